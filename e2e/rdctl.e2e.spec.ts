@@ -1152,7 +1152,8 @@ test.describe('Command server', () => {
 
             test('invalid setting is specified', async() => {
               const newSettings = { version: CURRENT_SETTINGS_VERSION, containerEngine: { name: 'beefalo' } };
-              const { stdout, stderr, error } = await rdctl(['api', 'settings', '-b', JSON.stringify(newSettings)]);
+              // rdctl would reject the value itself; skip that to check the server does too.
+              const { stdout, stderr, error } = await rdctl(['api', 'settings', '--no-validate', '-b', JSON.stringify(newSettings)]);
 
               expect({
                 stdout, stderr, error,
@@ -15,8 +15,8 @@ limitations under the License.
 */
 
 /**
- * This script generates the options module used for the rdctl `set` and `start` subcommands,
- * according to the preferences spec from pkg/rancher-desktop/assets/specs/command-api.yaml
+ * This script generates the options module used for the rdctl `set`, `start`, and `transient set` subcommands,
+ * according to the preferences and transientSettings specs from pkg/rancher-desktop/assets/specs/command-api.yaml
  * Doing this avoids manually keeping these `rdctl` commands in sync with the supported settings.
  */
 
@@ -27,7 +27,7 @@ import path from 'path';
 import ejs from 'ejs';
 import yaml from 'yaml';
 
-import { CURRENT_SETTINGS_VERSION } from '@pkg/config/settings';
+import { CURRENT_SETTINGS_VERSION, getDefaultSettings } from '@pkg/config/settings';
 
 interface commandFlagType {
   /**
@@ -119,14 +119,33 @@ function lastName(s: string): string {
   return s.split('.').pop() ?? '';
 }
 
+/**
+ * The GOOS that rdctl is built for on each platform the app runs on.
+ */
+const goosForPlatform = {
+  darwin: 'darwin',
+  linux:  'linux',
+  win32:  'windows',
+};
+
 class Generator {
   constructor() {
+    this.commandAPISpec = {};
     this.commandFlags = [];
     this.settingsTree = { version: { type: 'int' } };
+    this.preferencesSchema = {};
+    this.transientCommandFlags = [];
+    this.transientSettingsTree = {};
+    this.transientSettingsSchema = {};
   }
 
+  commandAPISpec: yamlObject;
   commandFlags: Array<commandFlagType>;
   settingsTree: settingsTreeType;
+  preferencesSchema: yamlObject;
+  transientCommandFlags: Array<commandFlagType>;
+  transientSettingsTree: settingsTreeType;
+  transientSettingsSchema: yamlObject;
 
   protected async loadInput(inputFile: string): Promise<yamlObject> {
     const contents = (await fs.promises.readFile(inputFile)).toString();
@@ -140,6 +159,7 @@ class Generator {
   }
 
   protected processInput(obj: yamlObject, inputFile: string): void {
+    this.commandAPISpec = obj;
     const preferences = obj?.components?.schemas?.preferences;
 
     if (!preferences) {
@@ -147,8 +167,20 @@ class Generator {
     }
     assert(preferences.type === 'object', `Expected preferences.type = 'object', got ${ preferences.type }`);
     assert(Object.keys(preferences.properties).length > 0, `Not a properties object: ${ preferences.properties }`);
+    this.preferencesSchema = preferences;
     for (const propertyName of Object.keys(preferences.properties)) {
-      this.walkProperty(propertyName, preferences.properties[propertyName], false, this.settingsTree);
+      this.walkProperty(propertyName, preferences.properties[propertyName], false, this.settingsTree, this.commandFlags);
+    }
+
+    const transientSettings = obj?.components?.schemas?.transientSettings;
+
+    if (!transientSettings) {
+      throw new Error(`Can't find components.schemas.transientSettings in ${ inputFile }`);
+    }
+    assert(transientSettings.type === 'object', `Expected transientSettings.type = 'object', got ${ transientSettings.type }`);
+    this.transientSettingsSchema = transientSettings;
+    for (const propertyName of Object.keys(transientSettings.properties)) {
+      this.walkProperty(propertyName, transientSettings.properties[propertyName], false, this.transientSettingsTree, this.transientCommandFlags);
     }
   }
 
@@ -158,11 +190,20 @@ class Generator {
 
     const linesForJSON = this.collectServerSettingsForJSON(this.settingsTree, true, '');
     const linesWithoutJSON = this.collectServerSettingsForJSON(this.settingsTree, false, '');
+    const transientLinesForJSON = this.collectServerSettingsForJSON(this.transientSettingsTree, true, '');
+    const transientLinesWithoutJSON = this.collectServerSettingsForJSON(this.transientSettingsTree, false, '');
     const data = {
-      commandFlags:     this.commandFlags,
-      linesForJSON:     linesForJSON.join('\n'),
-      linesWithoutJSON: linesWithoutJSON.join('\n'),
-      settingsVersion:  CURRENT_SETTINGS_VERSION,
+      commandFlags:              this.commandFlags,
+      linesForJSON:              linesForJSON.join('\n'),
+      linesWithoutJSON:          linesWithoutJSON.join('\n'),
+      settingsVersion:           CURRENT_SETTINGS_VERSION,
+      // A JSON string is also a valid golang string literal.
+      preferencesSchema:         JSON.stringify(JSON.stringify(this.preferencesSchema)),
+      commandAPISpec:            JSON.stringify(JSON.stringify(this.commandAPISpec)),
+      transientCommandFlags:     this.transientCommandFlags,
+      transientLinesForJSON:     transientLinesForJSON.join('\n'),
+      transientLinesWithoutJSON: transientLinesWithoutJSON.join('\n'),
+      transientSettingsSchema:   JSON.stringify(JSON.stringify(this.transientSettingsSchema)),
       kebabCase,
     };
     const renderedContent = await ejs.renderFile(templateFile, data, options);
@@ -179,6 +220,38 @@ class Generator {
     await fs.promises.writeFile(outputFile, renderedContent);
   }
 
+  /**
+   * Write the default settings of each platform to a file next to outputFile, named so that
+   * only the one for the target platform is built into rdctl, even when cross-compiling.
+   * Returns the names of the files written.
+   */
+  protected async emitDefaultSettings(outputFile: string): Promise<string[]> {
+    const options = { rmWhitespace: false };
+    const templateFile = 'scripts/assets/defaults.go.templ';
+    const outputFiles: string[] = [];
+
+    for (const [platform, goos] of Object.entries(goosForPlatform)) {
+      const data = {
+        platform,
+        goos,
+        // A JSON string is also a valid golang string literal.
+        defaultSettings: JSON.stringify(JSON.stringify(getDefaultSettings(platform as NodeJS.Platform))),
+      };
+      const renderedContent = await ejs.renderFile(templateFile, data, options);
+
+      if (outputFile === '-') {
+        console.log(renderedContent);
+        continue;
+      }
+      const defaultsFile = path.join(path.dirname(outputFile), `defaults_${ goos }.go`);
+
+      await fs.promises.writeFile(defaultsFile, renderedContent);
+      outputFiles.push(defaultsFile);
+    }
+
+    return outputFiles;
+  }
+
   protected collectServerSettingsForJSON(settingsTree: settingsTreeType, includeJSONTag: boolean, indent: string): string[] {
     return Object.keys(settingsTree).flatMap((propertyName) => {
       return this.collectServerSettingsForJSONProperty(propertyName, settingsTree[propertyName], includeJSONTag, indent);
@@ -254,7 +327,8 @@ class Generator {
     defaultValue: string,
     preference: yamlObject,
     notAvailable: boolean,
-    settingsTree: settingsTreeType) {
+    settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>) {
     const enums = this.convertStringsToGolang(preference.enum);
     const usageNote = preference['x-rd-usage'] ?? '';
     const newFlag: commandFlagType = {
@@ -270,9 +344,9 @@ class Generator {
 
     newFlag.usageNote = this.getFullUsageNote(usageNote, preference.enum);
     settingsTree[lastName(propertyName)] = { type: lcTypeName };
-    this.commandFlags.push(newFlag);
+    commandFlags.push(newFlag);
     for (const alias of preference['x-rd-aliases'] ?? []) {
-      this.commandFlags.push(Object.assign({}, newFlag, { propertyName: alias, aliasFor: propertyName }));
+      commandFlags.push(Object.assign({}, newFlag, { propertyName: alias, aliasFor: propertyName }));
     }
   }
 
@@ -280,23 +354,24 @@ class Generator {
     propertyName: string,
     preference: yamlObject,
     notAvailable: boolean,
-    settingsTree: settingsTreeType): void {
+    settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>): void {
     const platforms = preference['x-rd-platforms'] ?? [];
 
     notAvailable ||= preference['x-rd-hidden'];
     notAvailable ||= platforms.length > 0 && !platforms.includes(process.platform);
     switch (preference.type) {
     case 'object':
-      return this.walkPropertyObject(propertyName, preference, notAvailable, settingsTree);
+      return this.walkPropertyObject(propertyName, preference, notAvailable, settingsTree, commandFlags);
     case 'boolean':
-      return this.walkPropertyBoolean(propertyName, preference, notAvailable, settingsTree);
+      return this.walkPropertyBoolean(propertyName, preference, notAvailable, settingsTree, commandFlags);
     case 'string':
-      return this.walkPropertyString(propertyName, preference, notAvailable, settingsTree);
+      return this.walkPropertyString(propertyName, preference, notAvailable, settingsTree, commandFlags);
     case 'integer':
-      return this.walkPropertyInteger(propertyName, preference, notAvailable, settingsTree);
+      return this.walkPropertyInteger(propertyName, preference, notAvailable, settingsTree, commandFlags);
     case 'array':
       // not yet available
-      return this.walkPropertyArray(propertyName, preference, settingsTree);
+      return this.walkPropertyArray(propertyName, preference, settingsTree, commandFlags);
     default:
       throw new Error(`walkProperty: unexpected preference.type: '${ preference.type }'`);
     }
@@ -306,12 +381,14 @@ class Generator {
     propertyName: string,
     preference: yamlObject,
     settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>,
   ): void {
     this.updateLeaf(propertyName, capitalizeParts(propertyName),
       'array', 'Array', 'nil',
       preference,
       true,
-      settingsTree);
+      settingsTree,
+      commandFlags);
   }
 
   protected walkPropertyBoolean(
@@ -319,12 +396,14 @@ class Generator {
     preference: yamlObject,
     notAvailable: boolean,
     settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>,
   ): void {
     this.updateLeaf(propertyName, capitalizeParts(propertyName),
       'bool', 'Bool', 'false',
       preference,
       notAvailable,
-      settingsTree);
+      settingsTree,
+      commandFlags);
   }
 
   protected walkPropertyInteger(
@@ -332,19 +411,22 @@ class Generator {
     preference: yamlObject,
     notAvailable: boolean,
     settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>,
   ): void {
     this.updateLeaf(propertyName, capitalizeParts(propertyName),
       'int', 'Int', '0',
       preference,
       notAvailable,
-      settingsTree);
+      settingsTree,
+      commandFlags);
   }
 
   protected walkPropertyObject(
     propertyName: string,
     preference: yamlObject,
     notAvailable: boolean,
-    settingsTree: settingsTreeType): void {
+    settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>): void {
     if (preference.additionalProperties) {
       settingsTree[lastName(propertyName)] = { type: 'hash' };
 
@@ -356,7 +438,7 @@ class Generator {
     const innerSetting: settingsTreeType = {};
 
     for (const innerName in properties) {
-      this.walkProperty(`${ propertyName }.${ innerName }`, properties[innerName], notAvailable, innerSetting);
+      this.walkProperty(`${ propertyName }.${ innerName }`, properties[innerName], notAvailable, innerSetting, commandFlags);
     }
 
     settingsTree[lastName(propertyName)] = { type: innerSetting };
@@ -367,12 +449,14 @@ class Generator {
     preference: yamlObject,
     notAvailable: boolean,
     settingsTree: settingsTreeType,
+    commandFlags: Array<commandFlagType>,
   ): void {
     this.updateLeaf(propertyName, capitalizeParts(propertyName),
       'string', 'String', '""',
       preference,
       notAvailable,
-      settingsTree);
+      settingsTree,
+      commandFlags);
   }
 
   async run(argv: string[]): Promise<void> {
@@ -383,8 +467,10 @@ class Generator {
 
     this.processInput(obj, argv[0]);
     await this.emitOutput(argv[1] ?? '-');
+    const defaultsFiles = await this.emitDefaultSettings(argv[1] ?? '-');
+
     if (argv[1]) {
-      execFileSync('gofmt', ['-w', argv[1]]);
+      execFileSync('gofmt', ['-w', argv[1], ...defaultsFiles]);
     }
   }
 }
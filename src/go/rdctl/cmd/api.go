@@ -23,16 +23,22 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"strings"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/apispec"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	"github.com/spf13/cobra"
 )
 
 var apiSettings struct {
-	Method    string
-	InputFile string
-	Body      string
+	Method     string
+	InputFile  string
+	Body       string
+	List       bool
+	Pretty     bool
+	NoValidate bool
 }
 
 // apiCmd represents the api command
@@ -47,19 +53,31 @@ Two ways of specifying a body:
 
 2. --body|-b string: For the 'PUT /settings' endpoint, this must be a valid JSON string.
 
+Request bodies for the endpoints in the description of the API built into rdctl are
+checked against it before they are sent, so that invalid values are reported without
+reaching the server; settings it doesn't know are passed on. Use --no-validate to send
+the body as it is. Use --list to show the available endpoints. JSON responses are
+pretty-printed when writing to a terminal, or when --pretty is specified.
+
 The API is currently at version 1, but is still considered internal and experimental, and
 is subject to change without any advance notice.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return doAPICommand(cmd, args)
 	},
+	ValidArgsFunction: completeAPIPath,
 }
 
 func init() {
 	rootCmd.AddCommand(apiCmd)
+	supportOutputFormats(apiCmd)
 	apiCmd.Flags().StringVarP(&apiSettings.Method, "method", "X", "", "method to use")
 	apiCmd.Flags().StringVarP(&apiSettings.InputFile, "input", "", "", "file containing JSON payload to upload (- for standard input)")
 	apiCmd.Flags().StringVarP(&apiSettings.Body, "body", "b", "", "string containing JSON payload to upload")
+	apiCmd.Flags().BoolVar(&apiSettings.List, "list", false, "list the available endpoints")
+	apiCmd.Flags().BoolVar(&apiSettings.Pretty, "pretty", false, "pretty-print JSON responses even when not writing to a terminal")
+	apiCmd.Flags().BoolVar(&apiSettings.NoValidate, "no-validate", false, "send the request body without checking it against the API description")
+	_ = apiCmd.RegisterFlagCompletionFunc("method", completeAPIMethod)
 }
 
 func doAPICommand(cmd *cobra.Command, args []string) error {
@@ -68,6 +86,17 @@ func doAPICommand(cmd *cobra.Command, args []string) error {
 	var err error
 	var errorPacket *client.APIError
 
+	if apiSettings.List {
+		if len(args) > 0 {
+			return fmt.Errorf("api command: --list does not take an endpoint")
+		}
+		cmd.SilenceUsage = true
+		return listAPIEndpoints()
+	}
+	if outputPrinter != nil {
+		return fmt.Errorf("api command: --output can only be used with --list")
+	}
+
 	connectionInfo, err := config.GetConnectionInfo()
 	if err != nil {
 		return fmt.Errorf("failed to get connection info: %w", err)
@@ -89,35 +118,110 @@ func doAPICommand(cmd *cobra.Command, args []string) error {
 	}
 	// No longer emit usage info on errors
 	cmd.SilenceUsage = true
-	if apiSettings.InputFile != "" {
-		if apiSettings.Method == "" {
+	hasBody := apiSettings.InputFile != "" || apiSettings.Body != ""
+	if apiSettings.InputFile == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else if apiSettings.InputFile != "" {
+		contents, err = os.ReadFile(apiSettings.InputFile)
+	} else {
+		contents = []byte(apiSettings.Body)
+	}
+	if err != nil {
+		return err
+	}
+	if apiSettings.Method == "" {
+		if hasBody {
 			apiSettings.Method = "PUT"
-		}
-		if apiSettings.InputFile == "-" {
-			contents, err = io.ReadAll(os.Stdin)
 		} else {
-			contents, err = os.ReadFile(apiSettings.InputFile)
+			apiSettings.Method = "GET"
 		}
-		if err != nil {
+	}
+	if !apiSettings.NoValidate {
+		if err := validateAPIRequest(apiSettings.Method, endpoint, contents); err != nil {
 			return err
 		}
+	}
+	if hasBody {
 		response, err := rdClient.DoRequestWithPayload(apiSettings.Method, endpoint, bytes.NewBuffer(contents))
 		result, errorPacket, err = client.ProcessRequestForAPI(response, err)
-	} else if apiSettings.Body != "" {
-		if apiSettings.Method == "" {
-			apiSettings.Method = "PUT"
-		}
-		response, err := rdClient.DoRequestWithPayload(apiSettings.Method, endpoint, bytes.NewBufferString(apiSettings.Body))
-		result, errorPacket, err = client.ProcessRequestForAPI(response, err)
 	} else {
-		if apiSettings.Method == "" {
-			apiSettings.Method = "GET"
-		}
 		result, errorPacket, err = client.ProcessRequestForAPI(rdClient.DoRequest(apiSettings.Method, endpoint))
 	}
+	if err == nil && errorPacket == nil && json.Valid(result) && (apiSettings.Pretty || output.IsTerminal(os.Stdout)) {
+		return output.WritePrettyJSON(os.Stdout, result, output.UseColor(os.Stdout))
+	}
 	return displayAPICallResult(result, errorPacket, err)
 }
 
+func validateAPIRequest(method, endpoint string, body []byte) error {
+	spec, err := apispec.Load()
+	if err != nil {
+		return err
+	}
+	operation, err := spec.Lookup(strings.ToUpper(method), endpoint)
+	if err != nil {
+		// Leave endpoints the description doesn't cover for the server to answer.
+		return nil
+	}
+	return spec.ValidateBody(operation, body)
+}
+
+func listAPIEndpoints() error {
+	spec, err := apispec.Load()
+	if err != nil {
+		return err
+	}
+	endpoints := spec.Endpoints()
+	table := output.Table{Headers: []string{"METHOD", "PATH", "DESCRIPTION"}}
+	for _, endpoint := range endpoints {
+		table.Rows = append(table.Rows, []string{endpoint.Method, endpoint.Path, endpoint.Summary})
+	}
+	if outputPrinter != nil {
+		return printOutput(endpoints, table)
+	}
+	return output.WriteTable(os.Stdout, table)
+}
+
+func completeAPIPath(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || apiSettings.List {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	spec, err := apispec.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var completions []string
+	for _, path := range spec.CompletePath(toComplete, client.ApiVersion) {
+		fullPath := path
+		if !strings.HasPrefix(path, "/") {
+			fullPath = fmt.Sprintf("/%s/%s", client.ApiVersion, path)
+		}
+		completions = append(completions, fmt.Sprintf("%s\t%s", path, strings.Join(spec.PathMethods(fullPath), ", ")))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeAPIMethod(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return apispec.Methods, cobra.ShellCompDirectiveNoFileComp
+	}
+	spec, err := apispec.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	endpoint := args[0]
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = fmt.Sprintf("/%s/%s", client.ApiVersion, endpoint)
+	}
+	path, _, _ := strings.Cut(endpoint, "?")
+	var completions []string
+	for _, method := range spec.PathMethods(path) {
+		operation := spec.Paths[path][strings.ToLower(method)]
+		completions = append(completions, fmt.Sprintf("%s\t%s", method, strings.Join(strings.Fields(operation.Summary), " ")))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
 func displayAPICallResult(result []byte, errorPacket *client.APIError, err error) error {
 	if err != nil {
 		return err
@@ -0,0 +1,400 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/autostart"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/environment"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var applyFlags struct {
+	InputFile string
+	DryRun    bool
+	Export    bool
+	Prune     bool
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f FILE",
+	Short: "Make the Rancher Desktop setup match an environment file",
+	Long: `Make the Rancher Desktop setup match an environment file, such as rancher-desktop.yaml:
+
+  settings:          # all or some of the settings, as for 'rdctl settings apply'
+    kubernetes:
+      version: 1.27.3
+  extensions:        # extensions to install; without a tag, any version will do
+  - docker/logs-explorer-extension:0.2.2
+  autostart: true    # whether to start Rancher Desktop when logging in
+  dockerContext: rancher-desktop
+  snapshot:          # a baseline snapshot, created if there is none of that name
+    name: baseline
+    description: Clean setup
+
+All the parts are optional. The file is compared with the current setup, the differences are
+shown, and only what differs is changed. Use '-f -' to read the file from standard input.
+Use --export to write an environment file describing the current setup to standard output.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if applyFlags.Export {
+			if cmd.Flags().Changed("filename") || applyFlags.DryRun || applyFlags.Prune {
+				return fmt.Errorf("--export can't be used with --filename, --dry-run or --prune")
+			}
+			cmd.SilenceUsage = true
+			return exportEnvironment()
+		}
+		if applyFlags.InputFile == "" {
+			return fmt.Errorf("an environment file must be specified with --filename")
+		}
+		cmd.SilenceUsage = true
+		return applyEnvironment(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+	applyCmd.Flags().StringVarP(&applyFlags.InputFile, "filename", "f", "", "YAML or JSON environment file (- for standard input)")
+	applyCmd.Flags().BoolVar(&applyFlags.DryRun, "dry-run", false, "only show what would change")
+	applyCmd.Flags().BoolVar(&applyFlags.Export, "export", false, "write an environment file for the current setup")
+	applyCmd.Flags().BoolVar(&applyFlags.Prune, "prune", false, "uninstall extensions that aren't listed in the file")
+}
+
+func applyEnvironment(cmd *cobra.Command) error {
+	contents, err := readInputFile(applyFlags.InputFile)
+	if err != nil {
+		return err
+	}
+	env, err := environment.Parse(applyFlags.InputFile, contents)
+	if err != nil {
+		return err
+	}
+	needServer := env.Settings != nil || len(env.Extensions) > 0 || applyFlags.Prune
+	var changes []string
+	var currentSettings, settingsChanges map[string]interface{}
+
+	changeAutostart := false
+	if env.Autostart != nil {
+		enabled, err := autostart.IsAutostartEnabled()
+		if err != nil {
+			return err
+		}
+		if enabled != *env.Autostart {
+			changeAutostart = true
+			changes = append(changes, fmt.Sprintf("autostart: %t -> %t", enabled, *env.Autostart))
+		}
+		syncSetting := needServer
+		if !needServer {
+			if currentSettings, err = getSettingsIfRunning(); err != nil {
+				return err
+			}
+			syncSetting = currentSettings != nil
+		}
+		if syncSetting {
+			// Keep the setting in sync, so that the app doesn't undo the change.
+			if env.Settings == nil {
+				env.Settings = map[string]interface{}{}
+			}
+			if value, err := settings.Get(env.Settings, "application.autoStart"); err == nil && value != *env.Autostart {
+				return fmt.Errorf("%s: autostart and settings.application.autoStart disagree", applyFlags.InputFile)
+			}
+			if err := settings.Set(env.Settings, "application.autoStart", *env.Autostart); err != nil {
+				return err
+			}
+		}
+	}
+
+	changeDockerContext := false
+	if env.DockerContext != "" {
+		currentContext, err := environment.CurrentDockerContext()
+		if err != nil {
+			return err
+		}
+		if currentContext != env.DockerContext {
+			changeDockerContext = true
+			changes = append(changes, fmt.Sprintf("docker context: %s -> %s", currentContext, env.DockerContext))
+		}
+	}
+
+	if env.Settings != nil {
+		if currentSettings == nil {
+			if currentSettings, err = getCurrentSettings(); err != nil {
+				return err
+			}
+		}
+		if settingsChanges, err = settings.Diff(currentSettings, settings.Merge(currentSettings, env.Settings)); err != nil {
+			return err
+		}
+		for _, line := range settingsDiffLines(currentSettings, settingsChanges) {
+			changes = append(changes, "setting "+line)
+		}
+	}
+
+	var installExtensions, uninstallExtensions []string
+	if len(env.Extensions) > 0 || applyFlags.Prune {
+		installed, err := getInstalledExtensions()
+		if err != nil {
+			return err
+		}
+		installExtensions, uninstallExtensions = environment.ExtensionChanges(installed, env.Extensions, applyFlags.Prune)
+		for _, reference := range installExtensions {
+			changes = append(changes, fmt.Sprintf("extension: install %s", reference))
+		}
+		for _, id := range uninstallExtensions {
+			changes = append(changes, fmt.Sprintf("extension: uninstall %s", id))
+		}
+	}
+
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	manager := snapshot.NewManager(appPaths)
+	createSnapshot := false
+	if env.Snapshot != nil {
+		err := manager.ValidateName(env.Snapshot.Name)
+		if err == nil {
+			createSnapshot = true
+			changes = append(changes, fmt.Sprintf("snapshot: create %q", env.Snapshot.Name))
+		} else if !errors.Is(err, snapshot.ErrNameExists) {
+			return err
+		}
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("No changes are needed.")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if applyFlags.DryRun {
+		return nil
+	}
+
+	if changeAutostart {
+		if err := autostart.EnsureAutostart(*env.Autostart); err != nil {
+			return err
+		}
+	}
+	if changeDockerContext {
+		if err := environment.UseDockerContext(env.DockerContext); err != nil {
+			return err
+		}
+	}
+	if len(settingsChanges) > 0 {
+		needBackend := len(installExtensions) > 0 || len(uninstallExtensions) > 0 || createSnapshot
+		restartFrom := ""
+		if needBackend {
+			// Find out before the change whether it restarts the backend; afterwards the backend can
+			// still report its old state for a while, which must not be taken for the restart finishing.
+			if restartFrom, err = backendRestartState(settingsChanges, currentSettings); err != nil {
+				return err
+			}
+		}
+		if err := updateSettings(settingsChanges, currentSettings); err != nil {
+			return err
+		}
+		if needBackend {
+			if err := waitForBackend(restartFrom); err != nil {
+				return err
+			}
+		}
+	}
+	for _, reference := range installExtensions {
+		if err := postExtensionRequest("install", reference); err != nil {
+			return err
+		}
+	}
+	for _, id := range uninstallExtensions {
+		if err := postExtensionRequest("uninstall", id); err != nil {
+			return err
+		}
+	}
+	if createSnapshot {
+		err := wrapSnapshotOperation(cmd, appPaths, true, func() error {
+			if _, err := manager.Create(env.Snapshot.Name, env.Snapshot.Description); err != nil {
+				return fmt.Errorf("failed to create snapshot: %w", err)
+			}
+			return nil
+		})
+		// Errors creating the snapshot are collected with those from restarting the backend.
+		return exitWithJsonOrErrorCondition(err)
+	}
+	return nil
+}
+
+// getSettingsIfRunning returns the current settings, or nil when the app isn't running.
+func getSettingsIfRunning() (map[string]interface{}, error) {
+	connectionInfo, err := getConnectionInfo()
+	if err != nil || connectionInfo == nil {
+		return nil, err
+	}
+	currentSettings, err := getCurrentSettings()
+	if errors.Is(err, client.ErrConnectionRefused) {
+		return nil, nil
+	}
+	return currentSettings, err
+}
+
+func exportEnvironment() error {
+	var env environment.Environment
+	currentSettings, err := getCurrentSettings()
+	if err != nil {
+		return err
+	}
+	delete(currentSettings, "version")
+	env.Settings = currentSettings
+
+	installed, err := getInstalledExtensions()
+	if err != nil {
+		return err
+	}
+	for id, version := range installed {
+		reference := id
+		if version != "" {
+			reference = fmt.Sprintf("%s:%s", id, version)
+		}
+		env.Extensions = append(env.Extensions, reference)
+	}
+	sort.Strings(env.Extensions)
+
+	enabled, err := autostart.IsAutostartEnabled()
+	if err != nil {
+		return err
+	}
+	env.Autostart = &enabled
+
+	if currentContext, err := environment.CurrentDockerContext(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: not exporting the docker context: %s\n", err)
+	} else {
+		env.DockerContext = currentContext
+	}
+
+	contents, err := env.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to export the environment: %w", err)
+	}
+	_, err = os.Stdout.Write(contents)
+	return err
+}
+
+// getInstalledExtensions returns the installed extensions, as a map of their IDs to their versions.
+func getInstalledExtensions() (map[string]string, error) {
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	endpoint := fmt.Sprintf("/%s/extensions", client.ApiVersion)
+	result, err := client.ProcessRequestForUtility(rdClient.DoRequest("GET", endpoint))
+	if err != nil {
+		return nil, err
+	}
+	extensionList := map[string]struct {
+		Version string `json:"version"`
+	}{}
+	if err := json.Unmarshal(result, &extensionList); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal extension list API response: %w", err)
+	}
+	installed := make(map[string]string, len(extensionList))
+	for id, info := range extensionList {
+		installed[id] = info.Version
+	}
+	return installed, nil
+}
+
+// postExtensionRequest installs or uninstalls an extension.
+func postExtensionRequest(action, id string) error {
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	endpoint := fmt.Sprintf("/%s/extensions/%s?id=%s", client.ApiVersion, action, id)
+	if _, err := client.ProcessRequestForUtility(rdClient.DoRequest("POST", endpoint)); err != nil {
+		return fmt.Errorf("failed to %s extension %s: %w", action, id, err)
+	}
+	fmt.Printf("Extension %s: %sed.\n", id, action)
+	return nil
+}
+
+// backendRestartState returns the current backend state if applying the settings changes
+// restarts the backend, and an empty string if it doesn't.
+func backendRestartState(changes, currentSettings map[string]interface{}) (string, error) {
+	proposed := map[string]interface{}{"version": currentSettings["version"]}
+	for name, value := range changes {
+		proposed[name] = value
+	}
+	jsonBuffer, err := json.Marshal(proposed)
+	if err != nil {
+		return "", err
+	}
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return "", fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	reasons, err := proposeSettings(rdClient, jsonBuffer)
+	if err != nil {
+		return "", fmt.Errorf("failed to check whether the settings changes restart the backend: %w", err)
+	}
+	if len(reasons) == 0 {
+		return "", nil
+	}
+	state, err := rdClient.GetBackendState()
+	if err != nil {
+		return "", fmt.Errorf("failed to get backend state: %w", err)
+	}
+	switch state.VMState {
+	case "STARTED", "DISABLED":
+		return state.VMState, nil
+	}
+	// The backend isn't running, so there is no restart to wait for.
+	return "", nil
+}
+
+// waitForBackend waits for the backend to be running again after a settings change.
+// If restartFrom is set, the change restarts the backend, and it first waits for the
+// backend to leave that state, so the state from before the restart isn't mistaken for
+// the restart having finished.
+func waitForBackend(restartFrom string) error {
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	if restartFrom != "" {
+		if err := waitForVMStateChange(rdClient, restartFrom); err != nil {
+			return fmt.Errorf("error waiting for the backend to restart: %w", err)
+		}
+	}
+	if err := waitForVMState(rdClient, []string{"STARTED", "DISABLED"}); err != nil {
+		return fmt.Errorf("error waiting for the backend to restart: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/certs"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/spf13/cobra"
+)
+
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Manage extra CA certificates trusted in the VM",
+	Long: `Manage extra CA certificates, such as that of a TLS-intercepting proxy, that are
+installed into the trust store of the VM each time it starts.
+
+The certificates are kept in the certs directory under the config directory shown by
+'rdctl paths'. On macOS and Linux, they are installed by a provisioning entry in the
+Lima override.yaml; on Windows, by a script in the provisioning directory, which is
+run in the WSL distribution. Changes take effect when the VM is restarted.`,
+}
+
+func init() {
+	rootCmd.AddCommand(certsCmd)
+}
+
+// certsDir returns the directory the certificates are stored in.
+func certsDir() (p.Paths, string, error) {
+	paths, err := p.GetPaths()
+	if err != nil {
+		return paths, "", fmt.Errorf("failed to get paths: %w", err)
+	}
+	return paths, certs.Dir(paths.Config), nil
+}
+
+// installCerts regenerates the script that installs the stored certificates when the VM starts.
+func installCerts(paths p.Paths, dir string) error {
+	stored, err := certs.List(dir)
+	if err != nil {
+		return err
+	}
+	return installProvisionScript(paths, "rdctl-certs.start", certs.Marker, certs.Script(stored))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/certs"
+	"github.com/spf13/cobra"
+)
+
+var certsAddCmd = &cobra.Command{
+	Use:   "add FILE...",
+	Short: "Add CA certificates to be trusted in the VM",
+	Long: `Add the CA certificates in PEM files to be trusted in the VM. A file can hold several
+certificates, like a CA bundle; use - to read from standard input.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return addCerts(args)
+	},
+}
+
+func init() {
+	certsCmd.AddCommand(certsAddCmd)
+}
+
+func addCerts(files []string) error {
+	var parsed []certs.Certificate
+	for _, file := range files {
+		contents, err := readInputFile(file)
+		if err != nil {
+			return err
+		}
+		fileCerts, err := certs.ParsePEM(contents)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		parsed = append(parsed, fileCerts...)
+	}
+	paths, dir, err := certsDir()
+	if err != nil {
+		return err
+	}
+	added, err := certs.Add(dir, parsed)
+	for _, cert := range added {
+		fmt.Printf("Added %s (%s)\n", cert.Fingerprint[:16], cert.Subject)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add certificate: %w", err)
+	}
+	if len(added) == 0 {
+		fmt.Println("All the certificates had already been added.")
+		return nil
+	}
+	return installCerts(paths, dir)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/certs"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var certsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the CA certificates trusted in the VM",
+	Long: `List the CA certificates added with 'rdctl certs add', with the start of their
+SHA-256 fingerprints, their subjects and their expiry dates.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return listCerts()
+	},
+}
+
+func init() {
+	certsCmd.AddCommand(certsListCmd)
+	supportOutputFormats(certsListCmd)
+}
+
+func listCerts() error {
+	_, dir, err := certsDir()
+	if err != nil {
+		return err
+	}
+	stored, err := certs.List(dir)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		stored = []certs.Certificate{}
+	}
+	now := time.Now()
+	table := output.Table{Headers: []string{"FINGERPRINT", "SUBJECT", "EXPIRES"}}
+	for _, cert := range stored {
+		expires := cert.NotAfter.Local().Format("2006-01-02")
+		if cert.Expired(now) {
+			expires += " (expired)"
+		}
+		table.Rows = append(table.Rows, []string{cert.Fingerprint[:16], cert.Subject, expires})
+	}
+	if outputPrinter != nil {
+		return printOutput(stored, table)
+	}
+	if len(stored) == 0 {
+		fmt.Println("No certificates have been added.")
+		return nil
+	}
+	return output.WriteTable(os.Stdout, table)
+}
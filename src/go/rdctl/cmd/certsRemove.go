@@ -0,0 +1,103 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/certs"
+	"github.com/spf13/cobra"
+)
+
+var certsRemoveCmd = &cobra.Command{
+	Use:     "remove FINGERPRINT|FILE...",
+	Aliases: []string{"rm"},
+	Short:   "Stop trusting CA certificates in the VM",
+	Long: `Remove CA certificates added with 'rdctl certs add'. Each argument is either
+the start of a fingerprint, as shown by 'rdctl certs list', or a PEM file whose
+certificates are to be removed.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return removeCerts(args)
+	},
+	ValidArgsFunction: completeCertFingerprint,
+}
+
+func init() {
+	certsCmd.AddCommand(certsRemoveCmd)
+}
+
+func removeCerts(args []string) error {
+	paths, dir, err := certsDir()
+	if err != nil {
+		return err
+	}
+	stored, err := certs.List(dir)
+	if err != nil {
+		return err
+	}
+	var toRemove []certs.Certificate
+	for _, arg := range args {
+		if contents, err := os.ReadFile(arg); err == nil {
+			fileCerts, err := certs.ParsePEM(contents)
+			if err != nil {
+				return fmt.Errorf("%s: %w", arg, err)
+			}
+			for _, fileCert := range fileCerts {
+				cert, err := certs.Find(stored, fileCert.Fingerprint)
+				if err != nil {
+					return fmt.Errorf("%s: certificate %s (%s) has not been added", arg, fileCert.Fingerprint[:16], fileCert.Subject)
+				}
+				toRemove = append(toRemove, cert)
+			}
+			continue
+		}
+		cert, err := certs.Find(stored, arg)
+		if err != nil {
+			return err
+		}
+		toRemove = append(toRemove, cert)
+	}
+	for _, cert := range toRemove {
+		if err := certs.Remove(cert); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove certificate %s: %w", cert.Fingerprint[:16], err)
+		}
+		fmt.Printf("Removed %s (%s)\n", cert.Fingerprint[:16], cert.Subject)
+	}
+	return installCerts(paths, dir)
+}
+
+// completeCertFingerprint completes the fingerprints of the stored certificates.
+func completeCertFingerprint(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_, dir, err := certsDir()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	stored, err := certs.List(dir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	var completions []string
+	for _, cert := range stored {
+		completions = append(completions, cert.Fingerprint[:16]+"\t"+cert.Subject)
+	}
+	// Files can be given too.
+	return completions, cobra.ShellCompDirectiveDefault
+}
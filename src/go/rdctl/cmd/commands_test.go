@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/fakeserver"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runAsRdctlEnv makes the test binary behave as rdctl, so that each command runs in a fresh
+// process, with its own flags and exit code.
+const runAsRdctlEnv = "RDCTL_TEST_RUN_AS_RDCTL"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runAsRdctlEnv) != "" {
+		Execute()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type rdctlResult struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// newFakeServer starts a fake server, and points rdctl at it through the default config file
+// in a new home directory.
+func newFakeServer(t *testing.T) *fakeserver.Server {
+	server, err := fakeserver.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	home := t.TempDir()
+	for _, name := range []string{"HOME", "USERPROFILE", "LOCALAPPDATA", "APPDATA"} {
+		t.Setenv(name, home)
+	}
+	for _, name := range []string{"XDG_DATA_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(name, "")
+	}
+	appPaths, err := paths.GetPaths()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(appPaths.AppHome, 0o755))
+	require.NoError(t, server.WriteConfig(filepath.Join(appPaths.AppHome, "rd-engine.json")))
+	return server
+}
+
+func rdctl(t *testing.T, args ...string) rdctlResult {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	command := exec.Command(executable, args...)
+	command.Env = append(os.Environ(), runAsRdctlEnv+"=1", "NO_COLOR=1")
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	err = command.Run()
+	result := rdctlResult{stdout: stdout.String(), stderr: stderr.String()}
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		result.exitCode = exitError.ExitCode()
+	} else {
+		require.NoError(t, err)
+	}
+	return result
+}
+
+func TestListSettings(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "list-settings")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	var listed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.stdout), &listed))
+	assert.Equal(t, server.Settings(), listed)
+}
+
+func TestAuthenticationFailure(t *testing.T) {
+	newFakeServer(t)
+	result := rdctl(t, "--password", "wrong", "list-settings")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "401")
+
+	result = rdctl(t, "--password", "wrong", "settings", "get", "kubernetes.enabled", "-o", "json")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stdout, `"code":"unauthorized"`)
+}
+
+func TestSet(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "set", "--kubernetes.enabled=false")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "Status: "+fakeserver.ReconfiguringMessage+".\n", result.stdout)
+	assert.Equal(t, false, server.Settings()["kubernetes"].(map[string]interface{})["enabled"])
+
+	result = rdctl(t, "set")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "no settings to change were given")
+}
+
+func TestSetDryRun(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "set", "--dry-run", "--kubernetes.enabled=false", "--application.debug=true")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, `Settings to change:
+  application.debug: true
+  kubernetes.enabled: false
+Effects on the backend:
+  kubernetes.enabled: true -> false (restart)
+`, result.stdout)
+
+	result = rdctl(t, "set", "--dry-run", "--application.debug=true")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, `Settings to change:
+  application.debug: true
+No restart or reset of the backend would be needed.
+`, result.stdout)
+
+	var requests []string
+	for _, request := range server.Requests() {
+		requests = append(requests, request.Method+" "+request.Path)
+	}
+	assert.Contains(t, requests, "PUT /v1/propose_settings")
+	assert.NotContains(t, requests, "PUT /v1/settings")
+	assert.Equal(t, true, server.Settings()["kubernetes"].(map[string]interface{})["enabled"])
+	assert.Equal(t, false, server.Settings()["application"].(map[string]interface{})["debug"])
+}
+
+func TestSettingsCommands(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "settings", "get", "kubernetes.enabled")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "true\n", result.stdout)
+
+	result = rdctl(t, "settings", "set", "application.debug=true")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "Status: settings updated; no restart required.\n", result.stdout)
+	assert.Equal(t, true, server.Settings()["application"].(map[string]interface{})["debug"])
+
+	result = rdctl(t, "settings", "set", "kubernets.enabled=false")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, `unknown setting "kubernets.enabled"`)
+	assert.NotContains(t, server.Settings(), "kubernets")
+
+	server.SetLockedSettings(map[string]interface{}{"containerEngine": map[string]interface{}{"allowedImages": true}})
+	result = rdctl(t, "settings", "set", "containerEngine.allowedImages.enabled=true")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, `field "containerEngine.allowedImages.enabled" is locked`)
+}
+
+func TestTransientCommands(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "transient", "set", "--no-modal-dialogs=true")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, true, server.TransientSettings()["noModalDialogs"])
+
+	result = rdctl(t, "transient", "get", "noModalDialogs")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "SETTING           VALUE\nnoModalDialogs    true\n", result.stdout)
+}
+
+func TestExtensionCommands(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "extension", "list")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "No extensions are installed.\n", result.stdout)
+
+	result = rdctl(t, "extension", "install", "docker/logs-explorer-extension:0.2.2")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, map[string]string{"docker/logs-explorer-extension": "0.2.2"}, server.Extensions())
+
+	result = rdctl(t, "extension", "list", "-o", "json")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.JSONEq(t, `[{"id": "docker/logs-explorer-extension", "version": "0.2.2"}]`, result.stdout)
+
+	result = rdctl(t, "extension", "uninstall", "docker/logs-explorer-extension")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Empty(t, server.Extensions())
+}
+
+func TestAPICommand(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "api", "/v1/backend_state", "--method", "PUT", "--body", `{"vmState": "STOPPED"}`)
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Equal(t, "STOPPING", server.BackendState().VMState)
+
+	result = rdctl(t, "api", "/v1/backend_state")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	var state client.BackendState
+	require.NoError(t, json.Unmarshal([]byte(result.stdout), &state))
+	assert.Equal(t, "STOPPING", state.VMState)
+	assert.Equal(t, "STOPPED", server.BackendState().VMState)
+
+	result = rdctl(t, "api", "/v1/snapshots", "--method", "POST", "--body", `{"name": "first"}`)
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	require.Len(t, server.Snapshots(), 1)
+
+	// Endpoints the API description doesn't cover, and missing bodies, are left for the server.
+	result = rdctl(t, "api", "/v1/no_such_thing")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "Unknown command: GET /v1/no_such_thing")
+	assert.JSONEq(t, `{"message": "404 Not Found"}`, result.stdout)
+
+	result = rdctl(t, "api", "/v1/settings", "--method", "PUT")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "error processing JSON request block")
+
+	result = rdctl(t, "api", "/v1/settings", "--method", "PUT", "--body", `{"version": "x"}`)
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "invalid request body")
+	assert.Empty(t, result.stdout)
+
+	result = rdctl(t, "api", "/v1/settings", "--method", "PUT", "--body", `{"version": "x"}`, "--no-validate")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "errors in attempt to update settings")
+	assert.JSONEq(t, `{"message": "400 Bad Request"}`, result.stdout)
+}
+
+func TestApplyAutostart(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("autostart is kept in the registry of the user running the test")
+	}
+	server := newFakeServer(t)
+	current := server.Settings()
+	require.NoError(t, settings.Set(current, "application.autoStart", true))
+	server.SetSettings(current)
+	envFile := filepath.Join(t.TempDir(), "rancher-desktop.yaml")
+	require.NoError(t, os.WriteFile(envFile, []byte("autostart: false\n"), 0o644))
+
+	// Autostart is already disabled in the new home directory, but the app's setting still has to follow.
+	result := rdctl(t, "apply", "-f", envFile)
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Contains(t, result.stdout, "setting application.autoStart: true -> false")
+	autoStart, err := settings.Get(server.Settings(), "application.autoStart")
+	require.NoError(t, err)
+	assert.Equal(t, false, autoStart)
+}
+
+func TestApplyWaitsForRestart(t *testing.T) {
+	server := newFakeServer(t)
+	// The real backend keeps reporting STARTED for a moment after a settings change that restarts it.
+	statesAfterChange := []string{"STARTED", "STARTED", "STOPPING", "STARTED"}
+	server.Handle("GET", "/v1/backend_state", func(w http.ResponseWriter, _ *http.Request) {
+		polls := -1
+		for _, request := range server.Requests() {
+			if request.Method == "PUT" && request.Path == "/v1/settings" {
+				polls = 0
+			} else if request.Method == "GET" && request.Path == "/v1/backend_state" && polls >= 0 {
+				polls++
+			}
+		}
+		state := "STARTED"
+		if polls > 0 && polls <= len(statesAfterChange) {
+			state = statesAfterChange[polls-1]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"vmState": state, "locked": false})
+	})
+	envFile := filepath.Join(t.TempDir(), "rancher-desktop.yaml")
+	contents := "settings:\n  kubernetes:\n    enabled: false\nextensions:\n  - example/extension:1.0\n"
+	require.NoError(t, os.WriteFile(envFile, []byte(contents), 0o644))
+
+	result := rdctl(t, "apply", "-f", envFile)
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	pollsBeforeInstall := -1
+	for _, request := range server.Requests() {
+		if request.Method == "PUT" && request.Path == "/v1/settings" {
+			pollsBeforeInstall = 0
+		} else if request.Method == "GET" && request.Path == "/v1/backend_state" && pollsBeforeInstall >= 0 {
+			pollsBeforeInstall++
+		} else if request.Method == "POST" && request.Path == "/v1/extensions/install" {
+			break
+		}
+	}
+	assert.Equal(t, len(statesAfterChange), pollsBeforeInstall, "the extension must be installed once the restart has finished")
+}
+
+func TestSupportBundleFile(t *testing.T) {
+	newFakeServer(t)
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	result := rdctl(t, "support-bundle", bundlePath)
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.Contains(t, result.stdout, bundlePath)
+	assert.FileExists(t, bundlePath)
+
+	result = rdctl(t, "support-bundle", "--file", bundlePath, bundlePath)
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "either as an argument or with --file")
+
+	result = rdctl(t, "support-bundle", "-o", bundlePath)
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "give the bundle file as an argument, or with --file")
+}
+
+func TestSettingFlagCompletion(t *testing.T) {
+	for _, flag := range []string{"--container-engine.name", "--container-engine"} {
+		result := rdctl(t, "__complete", "set", flag, "")
+		require.Equal(t, 0, result.exitCode, result.stderr)
+		assert.Equal(t, []string{"containerd", "docker", "moby", ":4"}, strings.Fields(result.stdout), flag)
+	}
+}
+
+func TestOutputFlagSupport(t *testing.T) {
+	server := newFakeServer(t)
+	result := rdctl(t, "set", "--kubernetes.enabled=false", "-o", "json")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stderr, "'rdctl set' does not support --output")
+	assert.Equal(t, true, server.Settings()["kubernetes"].(map[string]interface{})["enabled"])
+
+	result = rdctl(t, "api", "/v1/settings", "-o", "yaml")
+	assert.Equal(t, 1, result.exitCode)
+	assert.Contains(t, result.stdout, "--output can only be used with --list")
+
+	result = rdctl(t, "api", "--list", "-o", "json")
+	require.Equal(t, 0, result.exitCode, result.stderr)
+	assert.True(t, json.Valid([]byte(result.stdout)))
+}
@@ -0,0 +1,175 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// completionTimeout limits requests to the server made while completing arguments, so that
+// completion fails quickly, without any output, when the app isn't running.
+const completionTimeout = time.Second
+
+// completeSnapshotNames completes the name of an existing snapshot.
+func completeSnapshotNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	snapshots, err := snapshot.NewManager(appPaths).List(false)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, aSnapshot := range snapshots {
+		if strings.HasPrefix(aSnapshot.Name, toComplete) {
+			names = append(names, aSnapshot.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeExtensionIDs completes the ID of an installed extension, as reported by the server.
+func completeExtensionIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	quickConnectionInfo := *connectionInfo
+	quickConnectionInfo.Timeout = completionTimeout
+	quickConnectionInfo.Retries = 0
+	rdClient := client.NewRDClient(&quickConnectionInfo)
+	endpoint := fmt.Sprintf("/%s/extensions", client.ApiVersion)
+	result, err := client.ProcessRequestForUtility(rdClient.DoRequest("GET", endpoint))
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	extensionList := map[string]interface{}{}
+	if err := json.Unmarshal(result, &extensionList); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var ids []string
+	for id := range extensionList {
+		if strings.HasPrefix(id, toComplete) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSettingName returns a function completing the dotted name of a setting in the schema.
+func completeSettingName(schemaFunc func() (*settings.Schema, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		schema, err := schemaFunc()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		for _, name := range schema.Names() {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeSettingAssignment returns a function completing `name=value` arguments: first the
+// name of a setting in the schema, and then, for settings with only a few possible values,
+// the value.
+func completeSettingAssignment(schemaFunc func() (*settings.Schema, error)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		schema, err := schemaFunc()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if name, value, found := strings.Cut(toComplete, "="); found {
+			settingSchema := schema.Lookup(name)
+			if settingSchema == nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			var completions []string
+			for _, possibleValue := range settingSchema.Values() {
+				if strings.HasPrefix(possibleValue, value) {
+					completions = append(completions, name+"="+possibleValue)
+				}
+			}
+			return completions, cobra.ShellCompDirectiveNoFileComp
+		}
+		var completions []string
+		for _, name := range schema.Names() {
+			if strings.HasPrefix(name, toComplete) && schema.Lookup(name).IsLeaf() {
+				completions = append(completions, name+"=")
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// registerSettingFlagCompletions completes the values of the string-valued setting flags
+// already defined on the command, including their aliases, for settings with only a few
+// possible values.
+// Other string flags keep the default file-name completion.
+func registerSettingFlagCompletions(cmd *cobra.Command, schemaFunc func() (*settings.Schema, error)) {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Value.Type() != "string" {
+			return
+		}
+		flagName := flag.Name
+		_ = cmd.RegisterFlagCompletionFunc(flagName, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			schema, err := schemaFunc()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			for _, name := range schema.Names() {
+				setting := schema.Lookup(name)
+				if !setting.IsLeaf() {
+					// The flag name of a group, such as containerEngine, can be the alias of a setting in it.
+					continue
+				}
+				if settings.FlagName(name) == flagName || slices.Contains(setting.Aliases, flagName) {
+					return setting.Values(), cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+			return nil, cobra.ShellCompDirectiveDefault
+		})
+	})
+}
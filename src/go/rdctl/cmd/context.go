@@ -0,0 +1,49 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// contextCmd represents the context command
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage named connection contexts",
+	Long: `Manage named connection contexts, each holding the host, port, user, password reference and
+config file to use when connecting to a Rancher Desktop install.
+
+While a context is current, its settings are used for any connection flags that aren't given.
+Use the global --context flag to pick a different context for a single command.`,
+}
+
+func init() {
+	rootCmd.AddCommand(contextCmd)
+}
+
+// updateContexts reads the contexts file, applies the change, and writes it back.
+func updateContexts(change func(contexts *config.Contexts) error) error {
+	contexts, err := config.ReadContexts(config.ContextsPath)
+	if err != nil {
+		return err
+	}
+	if err := change(contexts); err != nil {
+		return err
+	}
+	return contexts.Write(config.ContextsPath)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var contextAddPasswordRef string
+
+var contextAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a connection context",
+	Long: `Add a connection context holding the connection flags given with this command, for example:
+
+> rdctl context add windows --host 172.30.0.1 --user user --password-ref env:RD_PASSWORD
+
+Passwords aren't stored in the context; instead, --password-ref says where to read the password
+from when the context is used: either env:NAME for an environment variable, or file:PATH for the
+first line of a file. Settings that aren't given are read from the context's config file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("password") {
+			return fmt.Errorf("passwords aren't stored in contexts; use --password-ref instead")
+		}
+		cmd.SilenceUsage = true
+		return addContext(args[0], cmd.Flags())
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextAddCmd)
+	contextAddCmd.Flags().StringVar(&contextAddPasswordRef, "password-ref", "", "where to read the password from: env:NAME or file:PATH")
+}
+
+func addContext(name string, flags *pflag.FlagSet) error {
+	getFlag := func(flagName string) string {
+		value, _ := flags.GetString(flagName)
+		return value
+	}
+	context := config.Context{
+		Name:             name,
+		Host:             getFlag("host"),
+		Port:             getFlag("port"),
+		User:             getFlag("user"),
+		PasswordRef:      contextAddPasswordRef,
+		ConfigPath:       getFlag("config-path"),
+		CACert:           getFlag("cacert"),
+		ClientCert:       getFlag("client-cert"),
+		ClientKey:        getFlag("client-key"),
+		PinnedCertSHA256: getFlag("pinned-cert-sha256"),
+	}
+	if err := updateContexts(func(contexts *config.Contexts) error { return contexts.Add(context) }); err != nil {
+		return err
+	}
+	fmt.Printf("Added context %q.\n", name)
+	return nil
+}
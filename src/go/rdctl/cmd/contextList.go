@@ -0,0 +1,65 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var contextListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List connection contexts",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return listContexts()
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextListCmd)
+	supportOutputFormats(contextListCmd)
+}
+
+func listContexts() error {
+	contexts, err := config.ReadContexts(config.ContextsPath)
+	if err != nil {
+		return err
+	}
+	table := output.Table{Headers: []string{"CURRENT", "NAME", "HOST", "PORT", "USER", "CONFIG PATH"}}
+	for _, context := range contexts.Contexts {
+		current := ""
+		if context.Name == contexts.Current {
+			current = "*"
+		}
+		table.Rows = append(table.Rows, []string{current, context.Name, context.Host, context.Port, context.User, context.ConfigPath})
+	}
+	if outputPrinter != nil {
+		return printOutput(contexts, table)
+	}
+	if len(contexts.Contexts) == 0 {
+		fmt.Fprintln(os.Stderr, "No contexts present.")
+		return nil
+	}
+	return output.WriteTable(os.Stdout, table)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var contextRemoveCmd = &cobra.Command{
+	Use:     "remove NAME",
+	Aliases: []string{"rm"},
+	Short:   "Remove a connection context",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if err := updateContexts(func(contexts *config.Contexts) error { return contexts.Remove(args[0]) }); err != nil {
+			return err
+		}
+		fmt.Printf("Removed context %q.\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextRemoveCmd)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var contextUseUnset bool
+
+var contextUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Make a connection context current",
+	Long: `Make a connection context current, so that its settings are used by later commands.
+Use --unset to go back to connecting with the settings in the default config file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if contextUseUnset {
+			if err := cobra.NoArgs(cmd, args); err != nil {
+				return err
+			}
+		} else if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		cmd.SilenceUsage = true
+		name := ""
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if err := updateContexts(func(contexts *config.Contexts) error { return contexts.Use(name) }); err != nil {
+			return err
+		}
+		if name == "" {
+			fmt.Println("No context is current.")
+		} else {
+			fmt.Printf("Switched to context %q.\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	contextCmd.AddCommand(contextUseCmd)
+	contextUseCmd.Flags().BoolVar(&contextUseUnset, "unset", false, "don't use any context")
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shell"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/vmcopy"
+	"github.com/spf13/cobra"
+)
+
+// cpProgressMinSize is the size of the smallest file that progress is shown for.
+const cpProgressMinSize = 16 << 20
+
+// cpTypeScript prints the type of a path in the VM: "directory", "file", or nothing if it doesn't exist.
+const cpTypeScript = `if [ -d "$1" ]; then echo directory; elif [ -e "$1" ] || [ -L "$1" ]; then echo file; fi`
+
+// cpExtractScript extracts an archive from stdin into a directory in the VM; the umask
+// is cleared so that the modes in the archive are kept.
+const cpExtractScript = `umask 0 && exec tar -x -o -f - -C "$1"`
+
+var cpCmd = &cobra.Command{
+	Use:   "cp SRC DST",
+	Short: "Copy files and directories between the host and the Rancher Desktop VM",
+	Long: `Copy files and directories between the host and the Rancher Desktop VM.
+Paths in the VM start with 'vm:'; exactly one of SRC and DST must be in the VM.
+
+Directories are copied recursively, and file modes and modification times are kept.
+As with cp -r, when DST is an existing directory, SRC is copied into it; otherwise
+it is copied to DST. Symbolic links are copied as links.
+
+The VM must be running. Progress is shown for large files when stderr is a terminal.
+
+Examples:
+  rdctl cp ./config.toml vm:/tmp/
+  rdctl cp vm:/var/log/ ./vm-logs`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return copyFiles(args[0], args[1])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+}
+
+func copyFiles(srcArg, dstArg string) error {
+	src, err := vmcopy.ParseLocation(srcArg)
+	if err != nil {
+		return err
+	}
+	dst, err := vmcopy.ParseLocation(dstArg)
+	if err != nil {
+		return err
+	}
+	if src.VM == dst.VM {
+		return fmt.Errorf("exactly one of the source and the destination must be in the VM, starting with %q", vmcopy.VMPrefix)
+	}
+	if dst.VM {
+		err = copyToVM(src.Path, dst.Path)
+	} else {
+		err = copyFromVM(src.Path, dst.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %w", srcArg, dstArg, err)
+	}
+	return nil
+}
+
+func copyToVM(srcPath, dstPath string) error {
+	if _, err := os.Lstat(srcPath); err != nil {
+		return err
+	}
+	probe, err := vmCommand(shell.Options{}, []string{"/bin/sh", "-c", cpTypeScript, "rdctl-cp", dstPath}, false)
+	if err != nil {
+		return err
+	}
+	dstType, err := probe.Output()
+	if err != nil {
+		return vmCommandError(err, nil)
+	}
+	target, err := vmcopy.VMTarget(dstPath, filepath.Base(srcPath), strings.TrimSpace(string(dstType)))
+	if err != nil {
+		return err
+	}
+	extract, err := vmCommand(shell.Options{}, []string{"/bin/sh", "-c", cpExtractScript, "rdctl-cp", target.Dir}, false)
+	if err != nil {
+		return err
+	}
+	reader, writer := io.Pipe()
+	var stderr bytes.Buffer
+	extract.Stdin = reader
+	extract.Stderr = &stderr
+	go func() {
+		writer.CloseWithError(vmcopy.WriteArchive(writer, srcPath, target.Name, cpProgress()))
+	}()
+	err = extract.Run()
+	// Stop the archive being written if tar in the VM failed before reading all of it.
+	reader.Close()
+	if err != nil {
+		return vmCommandError(err, &stderr)
+	}
+	return nil
+}
+
+func copyFromVM(srcPath, dstPath string) error {
+	dir, name := vmcopy.SplitVMPath(srcPath)
+	target, err := vmcopy.HostTarget(dstPath, name)
+	if err != nil {
+		return err
+	}
+	archive, err := vmCommand(shell.Options{}, []string{"tar", "-c", "-f", "-", "-C", dir, name}, false)
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	archive.Stderr = &stderr
+	stdout, err := archive.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := archive.Start(); err != nil {
+		return err
+	}
+	extractErr := vmcopy.ExtractArchive(stdout, target, cpProgress())
+	if extractErr != nil {
+		_ = archive.Process.Kill()
+	}
+	// An error from tar in the VM explains a failure to extract the archive better.
+	if err := archive.Wait(); err != nil && stderr.Len() > 0 {
+		return vmCommandError(err, &stderr)
+	}
+	return extractErr
+}
+
+// vmCommandError describes the failure of a command in the VM by its error output, if there is any.
+func vmCommandError(err error, stderr *bytes.Buffer) error {
+	var message []byte
+	var exitError *exec.ExitError
+	if stderr != nil {
+		message = stderr.Bytes()
+	} else if errors.As(err, &exitError) {
+		message = exitError.Stderr
+	}
+	if len(bytes.TrimSpace(message)) > 0 {
+		return fmt.Errorf("%s", bytes.TrimSpace(message))
+	}
+	return err
+}
+
+// cpProgress returns a function that shows the progress of copying large files on stderr,
+// or nil when stderr isn't a terminal.
+func cpProgress() vmcopy.Progress {
+	if !output.IsTerminal(os.Stderr) {
+		return nil
+	}
+	return func(name string, copied, total int64) {
+		if total < cpProgressMinSize {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "\r%s: %s / %s (%d%%)\x1b[K", name, output.FormatSize(copied), output.FormatSize(total), copied*100/total)
+		if copied >= total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
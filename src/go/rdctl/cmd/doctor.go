@@ -0,0 +1,133 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	dockerconfig "github.com/docker/docker/cli/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/diskusage"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/doctor"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the host for problems that stop Rancher Desktop from working",
+	Long: `Check the host for common problems that stop Rancher Desktop from working.
+The app doesn't need to be running. The checks are:
+
+` + doctorChecksDescription() + `
+Each problem found is reported with a suggested fix. With --fix, the problems
+that can be fixed safely are fixed. The command fails if an error remains.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runDoctor()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	supportOutputFormats(doctorCmd)
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "fix the problems that can be fixed safely")
+}
+
+func doctorChecksDescription() string {
+	var builder strings.Builder
+	for _, check := range doctor.Checks {
+		fmt.Fprintf(&builder, "  %-16s %s\n", check.Name, check.Description)
+	}
+	return builder.String()
+}
+
+func doctorEnvironment() (doctor.Environment, error) {
+	paths, err := p.GetPaths()
+	if err != nil {
+		return doctor.Environment{}, fmt.Errorf("failed to get paths: %w", err)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return doctor.Environment{}, fmt.Errorf("failed to get the home directory: %w", err)
+	}
+	env := doctor.Environment{
+		Paths:           paths,
+		HomeDir:         homeDir,
+		DockerConfigDir: dockerconfig.Dir(),
+		BackendLockPath: filepath.Join(paths.AppHome, backendLockName),
+		ConfigPath:      config.DefaultConfigPath,
+		FreeSpace:       diskusage.FreeSpace,
+		Getenv:          os.Getenv,
+		Now:             time.Now(),
+	}
+	if runtime.GOOS != "windows" {
+		if env.LimactlPath, err = directories.GetLimactlPath(); err != nil {
+			return doctor.Environment{}, fmt.Errorf("failed to get the path to limactl: %w", err)
+		}
+	}
+	return env, nil
+}
+
+func runDoctor() error {
+	env, err := doctorEnvironment()
+	if err != nil {
+		return err
+	}
+	results := doctor.Run(env, doctorFix)
+	var remaining []string
+	for _, result := range results {
+		if result.Status == doctor.StatusError {
+			remaining = append(remaining, result.Check)
+		}
+	}
+	if outputPrinter != nil {
+		table := output.Table{Headers: []string{"CHECK", "STATUS", "MESSAGE"}}
+		for _, result := range results {
+			table.Rows = append(table.Rows, []string{result.Check, string(result.Status), result.Message})
+		}
+		if err := printOutput(results, table); err != nil {
+			return err
+		}
+	} else {
+		fixable := false
+		for _, result := range results {
+			fmt.Printf("[%s] %s: %s\n", result.Status, result.Check, result.Message)
+			if result.Suggestion != "" {
+				fmt.Printf("    suggestion: %s\n", result.Suggestion)
+			}
+			fixable = fixable || result.Fixable
+		}
+		if fixable && !doctorFix {
+			fmt.Println("Run 'rdctl doctor --fix' to fix the problems that can be fixed safely.")
+		}
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("checks failed: %s", strings.Join(remaining, ", "))
+	}
+	return nil
+}
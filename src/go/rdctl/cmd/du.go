@@ -0,0 +1,136 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/diskusage"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shell"
+	"github.com/spf13/cobra"
+)
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show the disk space used by Rancher Desktop",
+	Long: `Show the disk space used by Rancher Desktop, by category. SIZE is the apparent size
+of the files, and ALLOCATED the space they take on disk, which is smaller for sparse
+files such as the VM disks. The categories are:
+
+` + diskUsageCategoriesDescription() + `
+The images and volumes in the VM are only measured while the VM is running.
+Use 'rdctl prune' to clear the categories that can be cleared.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return showDiskUsage()
+	},
+}
+
+// diskUsageEntry is the space taken by a category of data. Apparent is nil for data in
+// the VM, whose allocated size is all that is measured.
+type diskUsageEntry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Paths       []string `json:"paths,omitempty"`
+	InVM        bool     `json:"inVM"`
+	Apparent    *int64   `json:"apparent,omitempty"`
+	Allocated   int64    `json:"allocated"`
+	Prunable    bool     `json:"prunable"`
+}
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+	supportOutputFormats(duCmd)
+}
+
+func diskUsageCategoriesDescription() string {
+	var builder strings.Builder
+	for _, category := range diskusage.Categories(p.Paths{}) {
+		fmt.Fprintf(&builder, "  %-12s %s\n", category.Name, category.Description)
+	}
+	return builder.String()
+}
+
+// measureVM returns the space taken by each category of data in the VM, or nil if the VM isn't running.
+func measureVM() (map[string]int64, error) {
+	running, err := vmIsRunning()
+	if err != nil || !running {
+		return nil, err
+	}
+	command, err := vmCommand(shell.Options{User: "root"}, []string{"/bin/sh", "-c", diskusage.VMMeasureScript}, false)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := command.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure the data in the VM: %w", vmCommandError(err, nil))
+	}
+	return diskusage.ParseVMSizes(string(contents))
+}
+
+func showDiskUsage() error {
+	paths, err := p.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	vmSizes, err := measureVM()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "The images and volumes in the VM are not included: %s\n", err)
+	} else if vmSizes == nil {
+		fmt.Fprintln(os.Stderr, "The VM is not running, so the images and volumes in it are not included.")
+	}
+	entries := []diskUsageEntry{}
+	table := output.Table{Headers: []string{"CATEGORY", "SIZE", "ALLOCATED", "DESCRIPTION"}}
+	var total int64
+	for _, category := range diskusage.Categories(paths) {
+		entry := diskUsageEntry{
+			Name:        category.Name,
+			Description: category.Description,
+			Paths:       category.Paths,
+			InVM:        category.InVM(),
+			Prunable:    category.Prunable,
+		}
+		apparent := "-"
+		if category.InVM() {
+			allocated, ok := vmSizes[category.Name]
+			if !ok {
+				continue
+			}
+			entry.Allocated = allocated
+		} else {
+			size, err := diskusage.MeasureCategory(category)
+			if err != nil {
+				return err
+			}
+			entry.Apparent, entry.Allocated = &size.Apparent, size.Allocated
+			apparent = output.FormatSize(size.Apparent)
+		}
+		total += entry.Allocated
+		entries = append(entries, entry)
+		table.Rows = append(table.Rows, []string{entry.Name, apparent, output.FormatSize(entry.Allocated), entry.Description})
+	}
+	if outputPrinter != nil {
+		return printOutput(entries, table)
+	}
+	table.Rows = append(table.Rows, []string{"total", "", output.FormatSize(total), ""})
+	return output.WriteTable(os.Stdout, table)
+}
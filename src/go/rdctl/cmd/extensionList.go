@@ -26,9 +26,15 @@ import (
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	"github.com/spf13/cobra"
 )
 
+type extensionInfo struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
 // listCmd represents the list command
 var listCmd = &cobra.Command{
 	Use:     "list",
@@ -44,6 +50,7 @@ var listCmd = &cobra.Command{
 
 func init() {
 	extensionCmd.AddCommand(listCmd)
+	supportOutputFormats(listCmd)
 }
 
 func listExtensions() error {
@@ -53,9 +60,19 @@ func listExtensions() error {
 	}
 	rdClient := client.NewRDClient(connectionInfo)
 	endpoint := fmt.Sprintf("/%s/extensions", client.ApiVersion)
-	result, errorPacket, err := client.ProcessRequestForAPI(rdClient.DoRequest("GET", endpoint))
-	if errorPacket != nil || err != nil {
-		return displayAPICallResult([]byte{}, errorPacket, err)
+	var result []byte
+	if outputPrinter != nil {
+		// Report API failures as errors, so they go into the error envelope.
+		result, err = client.ProcessRequestForUtility(rdClient.DoRequest("GET", endpoint))
+		if err != nil {
+			return err
+		}
+	} else {
+		var errorPacket *client.APIError
+		result, errorPacket, err = client.ProcessRequestForAPI(rdClient.DoRequest("GET", endpoint))
+		if errorPacket != nil || err != nil {
+			return displayAPICallResult([]byte{}, errorPacket, err)
+		}
 	}
 	extensionList := map[string]struct {
 		Version string `json:"version"`
@@ -64,6 +81,18 @@ func listExtensions() error {
 	if err != nil {
 		return fmt.Errorf("failed to unmarshal extension list API response: %w", err)
 	}
+	if outputPrinter != nil {
+		extensions := make([]extensionInfo, 0, len(extensionList))
+		for id, info := range extensionList {
+			extensions = append(extensions, extensionInfo{id, info.Version})
+		}
+		sort.Slice(extensions, func(i, j int) bool { return strings.ToLower(extensions[i].ID) < strings.ToLower(extensions[j].ID) })
+		table := output.Table{Headers: []string{"ID", "VERSION"}}
+		for _, extension := range extensions {
+			table.Rows = append(table.Rows, []string{extension.ID, extension.Version})
+		}
+		return printOutput(extensions, table)
+	}
 	if len(extensionList) == 0 {
 		fmt.Println("No extensions are installed.")
 		return nil
@@ -36,6 +36,7 @@ The <image-id> is an image reference, e.g. splatform/epinio-docker-desktop:lates
 		cmd.SilenceUsage = true
 		return uninstallExtension(args)
 	},
+	ValidArgsFunction: completeExtensionIDs,
 }
 
 func init() {
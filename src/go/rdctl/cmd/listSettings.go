@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
@@ -37,6 +38,13 @@ var listSettingsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if outputPrinter != nil {
+			var currentSettings map[string]interface{}
+			if err := json.Unmarshal(result, &currentSettings); err != nil {
+				return fmt.Errorf("failed to unmarshal settings API response: %w", err)
+			}
+			return printOutput(currentSettings, settingsTable("", currentSettings))
+		}
 		fmt.Println(string(result))
 		return nil
 	},
@@ -44,6 +52,7 @@ var listSettingsCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(listSettingsCmd)
+	supportOutputFormats(listSettingsCmd)
 }
 
 func getListSettings() ([]byte, error) {
@@ -0,0 +1,193 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/logs"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/spf13/cobra"
+)
+
+// logsPollInterval is how often the log files are checked for new lines with --follow.
+const logsPollInterval = 500 * time.Millisecond
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [component...]",
+	Short: "Show the Rancher Desktop logs",
+	Long: `Show the Rancher Desktop logs, which are spread across one file per component
+(background, lima, k3s, wsl and so on) in the logs directory shown by 'rdctl paths'.
+
+With no components, the logs of all components are shown. Lines from more than one
+component are merged in time order, and each is marked with the component it came from.
+Older log files left by log rotation are included.
+
+Examples:
+  rdctl logs --list
+  rdctl logs background --since 10m
+  rdctl logs k3s lima -f --grep 'error|fatal'`,
+	ValidArgsFunction: completeLogComponents,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return showLogs(args)
+	},
+}
+
+var logsFlags struct {
+	follow bool
+	since  string
+	grep   string
+	list   bool
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	supportOutputFormats(logsCmd)
+	logsCmd.Flags().BoolVarP(&logsFlags.follow, "follow", "f", false, "keep watching for new lines, following rotated files")
+	logsCmd.Flags().StringVar(&logsFlags.since, "since", "", "only show lines logged after this duration ago (e.g. 10m) or time (e.g. 2023-08-01T12:00:00Z)")
+	logsCmd.Flags().StringVar(&logsFlags.grep, "grep", "", "only show lines matching this regular expression")
+	logsCmd.Flags().BoolVar(&logsFlags.list, "list", false, "list the components that have logs, and their files")
+}
+
+func logsDirectory() (string, error) {
+	paths, err := p.GetPaths()
+	if err != nil {
+		return "", fmt.Errorf("failed to get paths: %w", err)
+	}
+	return paths.Logs, nil
+}
+
+func showLogs(names []string) error {
+	dir, err := logsDirectory()
+	if err != nil {
+		return err
+	}
+	allComponents, err := logs.Components(dir)
+	if err != nil {
+		return err
+	}
+	if logsFlags.list {
+		return listLogComponents(allComponents)
+	}
+	if outputPrinter != nil {
+		return fmt.Errorf("--output can only be used with --list")
+	}
+	components, err := logs.Select(allComponents, names)
+	if err != nil {
+		return err
+	}
+	var filter logs.Filter
+	if logsFlags.since != "" {
+		if filter.Since, err = logs.ParseSince(logsFlags.since, time.Now()); err != nil {
+			return err
+		}
+	}
+	if logsFlags.grep != "" {
+		if filter.Pattern, err = regexp.Compile(logsFlags.grep); err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
+	writer := bufio.NewWriter(os.Stdout)
+	defer writer.Flush()
+	// Lines are only marked with their component when there could be more than one.
+	markSource := len(components) > 1 || (len(names) == 0 && logsFlags.follow)
+	prefixWidth := 0
+	for _, component := range components {
+		prefixWidth = max(prefixWidth, len(component.Name))
+	}
+	emit := func(line logs.Line) error {
+		if markSource {
+			prefixWidth = max(prefixWidth, len(line.Component))
+			_, err := fmt.Fprintf(writer, "%-*s | %s\n", prefixWidth, line.Component, line.Text)
+			return err
+		}
+		_, err := fmt.Fprintln(writer, line.Text)
+		return err
+	}
+	if err := logs.Merge(components, filter, emit); err != nil {
+		return err
+	}
+	if !logsFlags.follow {
+		return nil
+	}
+
+	follower := logs.NewFollower(dir, components, len(names) == 0)
+	for {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		time.Sleep(logsPollInterval)
+		lines, err := follower.Poll()
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			if filter.Pattern != nil && !filter.Pattern.MatchString(line.Text) {
+				continue
+			}
+			if err := emit(line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func listLogComponents(components []logs.Component) error {
+	if outputPrinter == nil {
+		for _, component := range components {
+			fmt.Println(component.Name)
+		}
+		return nil
+	}
+	table := output.Table{Headers: []string{"COMPONENT", "FILES", "SIZE"}}
+	for _, component := range components {
+		var size int64
+		for _, file := range component.Files {
+			size += file.Size
+		}
+		table.Rows = append(table.Rows, []string{component.Name, strconv.Itoa(len(component.Files)), strconv.FormatInt(size, 10)})
+	}
+	return printOutput(components, table)
+}
+
+func completeLogComponents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dir, err := logsDirectory()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	components, err := logs.Components(dir)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, component := range components {
+		if strings.HasPrefix(component.Name, toComplete) && !slices.Contains(args, component.Name) {
+			names = append(names, component.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+// outputFormat is the value of the global `--output` flag; commands keep their
+// human-readable output when it is empty.
+var outputFormat string
+
+// outputPrinter is set up from outputFormat before any command runs, and is nil when
+// no output format was requested.
+var outputPrinter *output.Printer
+
+// outputFormatsAnnotation marks the commands that print their results in the format
+// given by --output; the others reject the flag rather than ignore it.
+const outputFormatsAnnotation = "rdctl.output-formats"
+
+// outputHintAnnotation is added to the error for a command that doesn't support --output,
+// for commands where the flag is easily mistaken for one of their own.
+const outputHintAnnotation = "rdctl.output-hint"
+
+// supportOutputFormats marks the commands as printing their results through printOutput.
+func supportOutputFormats(commands ...*cobra.Command) {
+	for _, command := range commands {
+		if command.Annotations == nil {
+			command.Annotations = map[string]string{}
+		}
+		command.Annotations[outputFormatsAnnotation] = "true"
+	}
+}
+
+func setupOutputPrinter(cmd *cobra.Command, args []string) error {
+	if outputFormat == "" {
+		return nil
+	}
+	if _, ok := cmd.Annotations[outputFormatsAnnotation]; !ok {
+		if hint, ok := cmd.Annotations[outputHintAnnotation]; ok {
+			return fmt.Errorf("'%s' does not support --output; %s", cmd.CommandPath(), hint)
+		}
+		return fmt.Errorf("'%s' does not support --output", cmd.CommandPath())
+	}
+	printer, err := output.NewPrinter(outputFormat)
+	if err != nil {
+		return err
+	}
+	outputPrinter = printer
+	if printer.IsStructured() {
+		// Errors are written to stdout as JSON by Execute() instead.
+		cmd.Root().SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+	return nil
+}
+
+// printOutput writes the value in the requested output format.
+func printOutput(value interface{}, table output.Table) error {
+	return outputPrinter.Print(os.Stdout, value, table)
+}
+
+// settingsTable lists the leaf values of a settings document, or of the part of it under prefix.
+func settingsTable(prefix string, value interface{}) output.Table {
+	flattened := map[string]interface{}{}
+	if valueMap, ok := value.(map[string]interface{}); ok {
+		flattened = settings.Flatten(prefix, valueMap)
+	} else {
+		flattened[prefix] = value
+	}
+	table := output.Table{Headers: []string{"SETTING", "VALUE"}}
+	for _, name := range sortedKeys(flattened) {
+		value, isString := flattened[name].(string)
+		if !isString {
+			value = formatSettingValue(flattened[name])
+		}
+		table.Rows = append(table.Rows, []string{name, value})
+	}
+	return table
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/spf13/cobra"
 	"os"
@@ -17,6 +18,9 @@ var pathsCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("failed to construct Paths: %w", err)
 		}
+		if outputPrinter != nil {
+			return printPaths(paths)
+		}
 		encoder := json.NewEncoder(os.Stdout)
 		err = encoder.Encode(paths)
 		if err != nil {
@@ -28,4 +32,22 @@ var pathsCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(pathsCmd)
+	supportOutputFormats(pathsCmd)
+}
+
+func printPaths(paths p.Paths) error {
+	// Go through JSON to get the same names in the table as in the other formats.
+	jsonBuffer, err := json.Marshal(paths)
+	if err != nil {
+		return fmt.Errorf("failed to output paths: %w", err)
+	}
+	var pathMap map[string]string
+	if err := json.Unmarshal(jsonBuffer, &pathMap); err != nil {
+		return fmt.Errorf("failed to output paths: %w", err)
+	}
+	table := output.Table{Headers: []string{"NAME", "PATH"}}
+	for _, name := range sortedKeys(pathMap) {
+		table.Rows = append(table.Rows, []string{name, pathMap[name]})
+	}
+	return printOutput(paths, table)
 }
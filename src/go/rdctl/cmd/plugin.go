@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/plugin"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Manage rdctl plugins",
+	Long: `Plugins are executables named 'rdctl-<name>', which are run for 'rdctl <name> [args...]'
+when there is no built-in command of that name. They are looked for in ~/.rd/libexec,
+and then in the directories in PATH.
+
+Plugins get the settings for connecting to Rancher Desktop, as resolved from the global flags,
+the current context and the config file, in these environment variables:
+
+  RD_CONNECTION_HOST, RD_CONNECTION_PORT, RD_CONNECTION_USER, RD_CONNECTION_PASSWORD,
+  RD_CONNECTION_SOCKET, RD_CONNECTION_SCHEME, RD_CONNECTION_CACERT, RD_CONNECTION_CLIENT_CERT,
+  RD_CONNECTION_CLIENT_KEY, RD_CONNECTION_PINNED_CERT_SHA256
+
+Only the settings that have values are set. RDCTL is set to the path of rdctl itself.`,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+}
+
+// isBuiltinCommand reports whether the name is that of a built-in command, including the
+// ones cobra only adds when it runs.
+func isBuiltinCommand(name string) bool {
+	if name == "help" || name == "completion" || strings.HasPrefix(name, "__") {
+		return true
+	}
+	cmd, _, err := rootCmd.Find([]string{name})
+	return err == nil && cmd != rootCmd
+}
+
+// findCommandName returns the index of the first argument that isn't a global flag or
+// its value, or -1 if there is none.
+func findCommandName(args []string) int {
+	flags := rootCmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		if strings.Contains(arg, "=") {
+			continue
+		}
+		var flag *pflag.Flag
+		if longName, ok := strings.CutPrefix(arg, "--"); ok {
+			flag = flags.Lookup(longName)
+		} else if len(arg) == 2 {
+			flag = flags.ShorthandLookup(arg[1:])
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			// Skip the flag's value
+			i++
+		}
+	}
+	return -1
+}
+
+// runPlugin runs the plugin named on the command line, if there is one and there is no
+// built-in command of that name. It returns false if no plugin was run.
+func runPlugin(args []string) (bool, int, error) {
+	index := findCommandName(args)
+	if index < 0 || isBuiltinCommand(args[index]) {
+		return false, 0, nil
+	}
+	path, err := plugin.Find(args[index], plugin.Dirs())
+	if err != nil {
+		// Let cobra report the unknown command.
+		return false, 0, nil
+	}
+	if err := rootCmd.PersistentFlags().Parse(args[:index]); err != nil {
+		return false, 0, nil
+	}
+	connectionInfo, err := config.GetConnectionInfo()
+	if errors.Is(err, os.ErrNotExist) {
+		// Rancher Desktop hasn't been run yet; the plugin may not need to connect to it.
+		connectionInfo = nil
+	} else if err != nil {
+		return true, 1, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	exitCode, err := plugin.Run(path, args[index+1:], connectionInfo)
+	return true, exitCode, err
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/plugin"
+	"github.com/spf13/cobra"
+)
+
+var pluginListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the plugins that can be run",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return listPlugins()
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginListCmd)
+	supportOutputFormats(pluginListCmd)
+}
+
+func listPlugins() error {
+	plugins, shadowed := plugin.List(plugin.Dirs())
+	runnable := make([]plugin.Plugin, 0, len(plugins))
+	for _, aPlugin := range plugins {
+		if isBuiltinCommand(aPlugin.Name) {
+			fmt.Fprintf(os.Stderr, "Warning: %s is overshadowed by the built-in %q command, and will never be run.\n", aPlugin.Path, aPlugin.Name)
+		} else {
+			runnable = append(runnable, aPlugin)
+		}
+	}
+	for _, aPlugin := range shadowed {
+		fmt.Fprintf(os.Stderr, "Warning: %s is shadowed by another plugin of the same name, and will never be run.\n", aPlugin.Path)
+	}
+
+	table := output.Table{Headers: []string{"NAME", "PATH"}}
+	for _, aPlugin := range runnable {
+		table.Rows = append(table.Rows, []string{aPlugin.Name, aPlugin.Path})
+	}
+	if outputPrinter != nil {
+		return printOutput(runnable, table)
+	}
+	if len(runnable) == 0 {
+		fmt.Fprintln(os.Stderr, "No plugins found.")
+		return nil
+	}
+	return output.WriteTable(os.Stdout, table)
+}
@@ -0,0 +1,217 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/diskusage"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shell"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var pruneFlags struct {
+	dryRun bool
+	yes    bool
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune CATEGORY...",
+	Short: "Clear data kept by Rancher Desktop to free disk space",
+	Long: `Clear categories of data shown by 'rdctl du' to free disk space. The categories
+that can be cleared are:
+
+` + pruneCategoriesDescription() + `
+Unused images and volumes are removed from the VM, which must be running; with
+containerd, this includes the k8s.io namespace used by Kubernetes. The others are
+removed from the host. What is to be removed is shown, and confirmed before
+anything is removed, unless --yes is given. With --dry-run, nothing is removed.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return prune(args)
+	},
+	ValidArgsFunction: completePruneCategory,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVar(&pruneFlags.dryRun, "dry-run", false, "show what would be removed, without removing it")
+	pruneCmd.Flags().BoolVarP(&pruneFlags.yes, "yes", "y", false, "don't ask for confirmation")
+	pruneCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+}
+
+func prunableCategories(paths p.Paths) []diskusage.Category {
+	var result []diskusage.Category
+	for _, category := range diskusage.Categories(paths) {
+		if category.Prunable {
+			result = append(result, category)
+		}
+	}
+	return result
+}
+
+func pruneCategoriesDescription() string {
+	var builder strings.Builder
+	for _, category := range prunableCategories(p.Paths{}) {
+		fmt.Fprintf(&builder, "  %-12s %s\n", category.Name, category.Description)
+	}
+	return builder.String()
+}
+
+func prune(args []string) error {
+	paths, err := p.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	var hostCategories, vmCategories []diskusage.Category
+	selected := map[string]bool{}
+	for _, arg := range args {
+		category, err := diskusage.Find(diskusage.Categories(paths), arg)
+		if err != nil {
+			return err
+		}
+		if !category.Prunable {
+			return fmt.Errorf("%s can't be cleared with 'rdctl prune'", category.Name)
+		}
+		if selected[category.Name] {
+			continue
+		}
+		selected[category.Name] = true
+		if category.InVM() {
+			vmCategories = append(vmCategories, category)
+		} else {
+			hostCategories = append(hostCategories, category)
+		}
+	}
+	if selected[diskusage.Snapshots] {
+		if _, err := os.Stat(filepath.Join(paths.AppHome, backendLockName)); err == nil {
+			return errors.New("a snapshot operation is in progress; if there is none, remove the lock with 'rdctl snapshot unlock'")
+		}
+	}
+	var vmSizes map[string]int64
+	if len(vmCategories) > 0 {
+		if vmSizes, err = measureVM(); err != nil {
+			return err
+		} else if vmSizes == nil {
+			return errors.New("the VM must be running to remove images and volumes from it")
+		}
+	}
+
+	fmt.Println("This will remove:")
+	for _, category := range hostCategories {
+		size, err := diskusage.MeasureCategory(category)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %-12s %-10s %s\n", category.Name, output.FormatSize(size.Allocated), strings.Join(category.Paths, ", "))
+	}
+	for _, category := range vmCategories {
+		fmt.Printf("  %-12s %-10s %s\n", category.Name, "up to "+output.FormatSize(vmSizes[category.Name]), "unused "+category.Name+" in the VM")
+	}
+	if pruneFlags.dryRun {
+		return nil
+	}
+	if !pruneFlags.yes {
+		if !output.IsTerminal(os.Stdin) {
+			return errors.New("confirmation is needed to remove data; use --yes to remove it without asking")
+		}
+		if !confirm("Remove it?", false) {
+			fmt.Println("Nothing was removed.")
+			return nil
+		}
+	}
+
+	var errs []error
+	for _, category := range hostCategories {
+		before, _ := diskusage.MeasureCategory(category)
+		if err := pruneHostCategory(paths, category); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", category.Name, err))
+			continue
+		}
+		after, _ := diskusage.MeasureCategory(category)
+		fmt.Printf("Removed %s, freeing %s\n", category.Name, output.FormatSize(before.Allocated-after.Allocated))
+	}
+	if len(vmCategories) > 0 {
+		if err := pruneVM(vmCategories); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func pruneHostCategory(paths p.Paths, category diskusage.Category) error {
+	if category.Name != diskusage.Snapshots {
+		var errs []error
+		for _, path := range category.Paths {
+			errs = append(errs, diskusage.RemoveContents(path))
+		}
+		return errors.Join(errs...)
+	}
+	// Snapshots are deleted through the manager, which knows their layout, while holding
+	// the lock, so that no snapshot operation can start in the meantime.
+	if err := createBackendLock(paths.AppHome); err != nil {
+		return err
+	}
+	defer removeBackendLock(paths.AppHome)
+	manager := snapshot.NewManager(paths)
+	snapshots, err := manager.List(true)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range snapshots {
+		if err := manager.Delete(s.ID); err != nil {
+			errs = append(errs, fmt.Errorf("snapshot %q: %w", s.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pruneVM removes the unused images or volumes from the VM, showing the output of the container engine.
+func pruneVM(categories []diskusage.Category) error {
+	args := []string{"/bin/sh", "-c", diskusage.VMPruneScript, "rdctl-prune"}
+	for _, category := range categories {
+		args = append(args, category.Name)
+	}
+	command, err := vmCommand(shell.Options{User: "root"}, args, false)
+	if err != nil {
+		return err
+	}
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("failed to remove data from the VM: %w", err)
+	}
+	return nil
+}
+
+// completePruneCategory completes the categories that can be cleared.
+func completePruneCategory(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var completions []string
+	for _, category := range prunableCategories(p.Paths{}) {
+		completions = append(completions, category.Name+"\t"+category.Description)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage registry mirrors and insecure registries in the VM",
+	Long: `Manage pull-through mirrors and insecure registries for the container runtimes in
+the VM. They are written into the configuration of k3s (/etc/rancher/k3s/registries.yaml)
+and dockerd (/etc/docker/daemon.json) each time the VM starts, so they are kept after
+a factory reset. dockerd only uses mirrors for Docker Hub (docker.io).
+
+The configuration is kept in registries.yaml in the config directory shown by
+'rdctl paths'. On macOS and Linux, it is written into the VM by a provisioning entry in
+the Lima override.yaml; on Windows, by a script in the provisioning directory, which is
+run in the WSL distribution. A registries.yaml or daemon.json in the VM that was not
+written by rdctl is left alone. Changes take effect when the VM is restarted.`,
+}
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+}
+
+// loadRegistryConfig reads the registry configuration, refusing one with problems.
+func loadRegistryConfig() (p.Paths, string, *registry.Config, error) {
+	paths, err := p.GetPaths()
+	if err != nil {
+		return paths, "", nil, fmt.Errorf("failed to get paths: %w", err)
+	}
+	path := registry.Path(paths.Config)
+	config, err := registry.Load(path)
+	if err != nil {
+		return paths, path, nil, err
+	}
+	if problems := config.Validate(); len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Fprintln(os.Stderr, problem.Error())
+		}
+		return paths, path, nil, fmt.Errorf("%s: found %d invalid setting(s)", path, len(problems))
+	}
+	return paths, path, config, nil
+}
+
+// saveRegistryConfig writes the registry configuration, and regenerates the script that
+// writes it into the VM when it starts.
+func saveRegistryConfig(paths p.Paths, path string, config *registry.Config) error {
+	if err := config.Save(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	script, err := registry.Script(config)
+	if err != nil {
+		return err
+	}
+	return installProvisionScript(paths, "rdctl-registry.start", registry.Marker, script)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var registryInsecureCmd = &cobra.Command{
+	Use:   "insecure",
+	Short: "Manage registries used without TLS verification",
+	Long: `Manage insecure registries, given as host:port. Their certificates are not
+verified, and they can be used over plain HTTP. To trust a registry with a certificate
+signed by a private CA instead, add the CA with 'rdctl certs add'.`,
+}
+
+func init() {
+	registryCmd.AddCommand(registryInsecureCmd)
+}
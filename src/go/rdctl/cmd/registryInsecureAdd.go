@@ -0,0 +1,60 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var registryInsecureAddCmd = &cobra.Command{
+	Use:   "add HOST[:PORT]...",
+	Short: "Use registries without TLS verification",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return addInsecureRegistries(args)
+	},
+}
+
+func init() {
+	registryInsecureCmd.AddCommand(registryInsecureAddCmd)
+}
+
+func addInsecureRegistries(hosts []string) error {
+	paths, path, config, err := loadRegistryConfig()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, host := range hosts {
+		added, err := config.AddInsecure(host)
+		if err != nil {
+			return err
+		}
+		if added {
+			changed = true
+		} else {
+			fmt.Printf("%s has already been added\n", host)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return saveRegistryConfig(paths, path, config)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var registryInsecureRemoveCmd = &cobra.Command{
+	Use:     "remove HOST[:PORT]...",
+	Aliases: []string{"rm"},
+	Short:   "Verify the TLS certificates of registries again",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return removeInsecureRegistries(args)
+	},
+	ValidArgsFunction: completeInsecureRegistry,
+}
+
+func init() {
+	registryInsecureCmd.AddCommand(registryInsecureRemoveCmd)
+}
+
+func removeInsecureRegistries(hosts []string) error {
+	paths, path, config, err := loadRegistryConfig()
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if err := config.RemoveInsecure(host); err != nil {
+			return err
+		}
+	}
+	return saveRegistryConfig(paths, path, config)
+}
+
+// completeInsecureRegistry completes the insecure registries.
+func completeInsecureRegistry(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_, _, config, err := loadRegistryConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.Insecure, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var registryListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the registry mirrors and insecure registries",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return listRegistries()
+	},
+}
+
+func init() {
+	registryCmd.AddCommand(registryListCmd)
+	supportOutputFormats(registryListCmd)
+}
+
+func listRegistries() error {
+	_, _, config, err := loadRegistryConfig()
+	if err != nil {
+		return err
+	}
+	insecure := map[string]bool{}
+	names := []string{}
+	for _, host := range config.Insecure {
+		insecure[host] = true
+		names = append(names, host)
+	}
+	for name := range config.Mirrors {
+		if !insecure[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	table := output.Table{Headers: []string{"REGISTRY", "INSECURE", "MIRRORS"}}
+	for _, name := range names {
+		table.Rows = append(table.Rows, []string{name, fmt.Sprint(insecure[name]), strings.Join(config.Mirrors[name], ", ")})
+	}
+	if outputPrinter != nil {
+		if config.Mirrors == nil {
+			config.Mirrors = map[string][]string{}
+		}
+		if config.Insecure == nil {
+			config.Insecure = []string{}
+		}
+		return printOutput(config, table)
+	}
+	if len(names) == 0 {
+		fmt.Println("No registry mirrors or insecure registries have been added.")
+		return nil
+	}
+	return output.WriteTable(os.Stdout, table)
+}
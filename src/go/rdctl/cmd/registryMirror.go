@@ -0,0 +1,33 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var registryMirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Manage pull-through mirrors of registries",
+	Long: `Manage pull-through mirrors of registries. Images of a registry are pulled from its
+mirrors first, in the order they were added, and from the registry itself if none of
+them has the image. Docker Hub is called docker.io.`,
+}
+
+func init() {
+	registryCmd.AddCommand(registryMirrorCmd)
+}
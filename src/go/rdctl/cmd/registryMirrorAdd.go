@@ -0,0 +1,75 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var registryMirrorAddCmd = &cobra.Command{
+	Use:   "add REGISTRY URL...",
+	Short: "Add pull-through mirrors of a registry",
+	Long: `Add pull-through mirrors of a registry, e.g.
+
+  rdctl registry mirror add docker.io https://mirror.local
+
+The URLs must start with http:// or https:// and have no path. dockerd only uses
+mirrors for Docker Hub; those of other registries are only used by Kubernetes.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return addRegistryMirrors(args[0], args[1:])
+	},
+}
+
+func init() {
+	registryMirrorCmd.AddCommand(registryMirrorAddCmd)
+}
+
+func addRegistryMirrors(name string, urls []string) error {
+	paths, path, config, err := loadRegistryConfig()
+	if err != nil {
+		return err
+	}
+	name, err = registry.NormalizeRegistry(name)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, url := range urls {
+		added, err := config.AddMirror(name, url)
+		if err != nil {
+			return err
+		}
+		if added {
+			changed = true
+		} else {
+			fmt.Printf("%s is already a mirror of %s\n", url, name)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if name != registry.DockerHub {
+		fmt.Fprintf(os.Stderr, "Note: dockerd only uses mirrors for %s; the mirrors of %s are only used by Kubernetes.\n", registry.DockerHub, name)
+	}
+	return saveRegistryConfig(paths, path, config)
+}
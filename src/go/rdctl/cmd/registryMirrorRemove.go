@@ -0,0 +1,85 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var registryMirrorRemoveCmd = &cobra.Command{
+	Use:     "remove REGISTRY [URL...]",
+	Aliases: []string{"rm"},
+	Short:   "Remove pull-through mirrors of a registry",
+	Long:    `Remove the given mirrors of a registry, or all its mirrors if no URLs are given.`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return removeRegistryMirrors(args[0], args[1:])
+	},
+	ValidArgsFunction: completeRegistryMirror,
+}
+
+func init() {
+	registryMirrorCmd.AddCommand(registryMirrorRemoveCmd)
+}
+
+func removeRegistryMirrors(name string, urls []string) error {
+	paths, path, config, err := loadRegistryConfig()
+	if err != nil {
+		return err
+	}
+	name, err = registry.NormalizeRegistry(name)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		urls = []string{""}
+	}
+	for _, url := range urls {
+		removed, err := config.RemoveMirror(name, url)
+		if err != nil {
+			return err
+		}
+		for _, endpoint := range removed {
+			fmt.Printf("Removed mirror %s of %s\n", endpoint, name)
+		}
+	}
+	return saveRegistryConfig(paths, path, config)
+}
+
+// completeRegistryMirror completes the registries with mirrors, then their mirrors.
+func completeRegistryMirror(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	_, _, config, err := loadRegistryConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(args) == 0 {
+		var names []string
+		for name := range config.Mirrors {
+			names = append(names, name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+	name, err := registry.NormalizeRegistry(args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.Mirrors[name], cobra.ShellCompDirectiveNoFileComp
+}
@@ -18,27 +18,40 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	"github.com/spf13/cobra"
 	"os"
 )
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "rdctl",
-	Short: "A CLI for Rancher Desktop",
-	Long:  `The eventual goal of this CLI is to enable any UI-based operation to be done from the command-line as well.`,
+	Use:               "rdctl",
+	Short:             "A CLI for Rancher Desktop",
+	Long:              `The eventual goal of this CLI is to enable any UI-based operation to be done from the command-line as well.`,
+	PersistentPreRunE: setupOutputPrinter,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	if ran, exitCode, err := runPlugin(os.Args[1:]); ran {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		}
+		os.Exit(exitCode)
+	}
 	if err := rootCmd.Execute(); err != nil {
+		if outputPrinter != nil && outputPrinter.IsStructured() {
+			_ = output.WriteError(os.Stdout, err)
+		}
 		os.Exit(1)
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", output.FlagUsage)
 	if len(os.Args) > 1 {
 		mainCommand := os.Args[1]
 		if mainCommand == "-h" || mainCommand == "help" || mainCommand == "--help" {
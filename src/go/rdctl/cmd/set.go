@@ -20,10 +20,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
 	"github.com/spf13/cobra"
 )
 
@@ -31,7 +34,9 @@ import (
 var setCmd = &cobra.Command{
 	Use:   "set",
 	Short: "Update selected fields in the Rancher Desktop UI and restart the backend.",
-	Long:  `Update selected fields in the Rancher Desktop UI and restart the backend.`,
+	Long: `Update selected fields in the Rancher Desktop UI and restart the backend.
+Use --dry-run to see which settings would change, and whether the backend would
+need to be restarted or reset, without changing anything.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := cobra.NoArgs(cmd, args); err != nil {
 			return err
@@ -40,9 +45,21 @@ var setCmd = &cobra.Command{
 	},
 }
 
+var setDryRun bool
+
+// restartReason is the server's description of how changing one setting affects the backend,
+// as returned by `PUT /v1/propose_settings`.
+type restartReason struct {
+	Current  interface{} `json:"current"`
+	Desired  interface{} `json:"desired"`
+	Severity string      `json:"severity"`
+}
+
 func init() {
 	rootCmd.AddCommand(setCmd)
 	options.UpdateCommonStartAndSetCommands(setCmd)
+	registerSettingFlagCompletions(setCmd, settings.PreferencesSchema)
+	setCmd.Flags().BoolVar(&setDryRun, "dry-run", false, "show what would change, and whether the backend would restart or reset, without changing anything")
 }
 
 func doSetCommand(cmd *cobra.Command) error {
@@ -65,6 +82,10 @@ func doSetCommand(cmd *cobra.Command) error {
 		return err
 	}
 
+	if setDryRun {
+		return doSetDryRun(rdClient, jsonBuffer)
+	}
+
 	response, err := rdClient.DoRequestWithPayload("PUT", client.VersionCommand("", "settings"), bytes.NewBuffer(jsonBuffer))
 	result, err := client.ProcessRequestForUtility(response, err)
 	if err != nil {
@@ -77,3 +98,67 @@ func doSetCommand(cmd *cobra.Command) error {
 	}
 	return nil
 }
+
+// proposeSettings sends the settings payload to the propose_settings endpoint and returns
+// the restart or reset each changed setting would cause, keyed by setting name.
+func proposeSettings(rdClient client.RDClient, jsonBuffer []byte) (map[string]restartReason, error) {
+	response, err := rdClient.DoRequestWithPayload("PUT", client.VersionCommand("", "propose_settings"), bytes.NewBuffer(jsonBuffer))
+	result, err := client.ProcessRequestForUtility(response, err)
+	if err != nil {
+		return nil, err
+	}
+	reasons := map[string]restartReason{}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &reasons); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal propose_settings API response: %w", err)
+		}
+	}
+	return reasons, nil
+}
+
+// doSetDryRun sends the settings payload to the propose_settings endpoint and reports
+// which settings would change, and the restart or reset each change would cause.
+func doSetDryRun(rdClient client.RDClient, jsonBuffer []byte) error {
+	reasons, err := proposeSettings(rdClient, jsonBuffer)
+	if err != nil {
+		return err
+	}
+	var proposed map[string]interface{}
+	if err := json.Unmarshal(jsonBuffer, &proposed); err != nil {
+		return err
+	}
+	changes := settings.Flatten("", proposed)
+	delete(changes, "version")
+
+	fmt.Println("Settings to change:")
+	for _, name := range sortedKeys(changes) {
+		fmt.Printf("  %s: %s\n", name, formatSettingValue(changes[name]))
+	}
+	if len(reasons) == 0 {
+		fmt.Println("No restart or reset of the backend would be needed.")
+		return nil
+	}
+	fmt.Println("Effects on the backend:")
+	for _, name := range sortedKeys(reasons) {
+		reason := reasons[name]
+		fmt.Printf("  %s: %s -> %s (%s)\n", name, formatSettingValue(reason.Current), formatSettingValue(reason.Desired), reason.Severity)
+	}
+	return nil
+}
+
+func formatSettingValue(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return strings.ToLower(keys[i]) < strings.ToLower(keys[j]) })
+	return keys
+}
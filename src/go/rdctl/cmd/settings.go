@@ -0,0 +1,92 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// settingsCmd represents the settings command
+var settingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Query and update individual settings",
+	Long: `Query and update individual settings by their dotted names, such as 'kubernetes.version'.
+Changes are computed against the current settings, and only changed values are sent to Rancher Desktop.`,
+}
+
+func init() {
+	rootCmd.AddCommand(settingsCmd)
+}
+
+// getCurrentSettings returns the current settings as a generic JSON document.
+func getCurrentSettings() (map[string]interface{}, error) {
+	result, err := getListSettings()
+	if err != nil {
+		return nil, err
+	}
+	var currentSettings map[string]interface{}
+	if err := json.Unmarshal(result, &currentSettings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings API response: %w", err)
+	}
+	return currentSettings, nil
+}
+
+// updateSettings sends the partial settings document to the server, along with
+// the current settings version, and reports the server's response.
+func updateSettings(changes map[string]interface{}, currentSettings map[string]interface{}) error {
+	if len(changes) == 0 {
+		fmt.Println("No settings need to be changed.")
+		return nil
+	}
+	changes["version"] = currentSettings["version"]
+	jsonBuffer, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	response, err := rdClient.DoRequestWithPayload("PUT", client.VersionCommand("", "settings"), bytes.NewBuffer(jsonBuffer))
+	result, err := client.ProcessRequestForUtility(response, err)
+	if err != nil {
+		return err
+	}
+	if len(result) > 0 {
+		fmt.Printf("Status: %s.\n", string(result))
+	} else {
+		fmt.Println("Operation successfully returned with no output.")
+	}
+	return nil
+}
+
+// readInputFile reads the named file, or standard input for "-".
+func readInputFile(inputFile string) ([]byte, error) {
+	if inputFile == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(inputFile)
+}
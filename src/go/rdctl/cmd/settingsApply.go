@@ -0,0 +1,110 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var settingsApplyFlags struct {
+	InputFile string
+	DryRun    bool
+}
+
+var settingsApplyCmd = &cobra.Command{
+	Use:   "apply -f FILE",
+	Short: "Apply the settings in a JSON or YAML file",
+	Long: `Apply the settings in a JSON or YAML file, such as a team-standard settings file.
+
+The file can contain all the settings, or only some of them. It is checked against the
+settings schema before anything is sent to Rancher Desktop, and the differences from the
+current settings are shown. Only the settings that differ are then changed.
+Use '-f -' to read the settings from standard input.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return applySettingsFile(settingsApplyFlags.InputFile)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(settingsApplyCmd)
+	settingsApplyCmd.Flags().StringVarP(&settingsApplyFlags.InputFile, "filename", "f", "", "JSON or YAML file containing settings (- for standard input)")
+	settingsApplyCmd.Flags().BoolVar(&settingsApplyFlags.DryRun, "dry-run", false, "only show the differences from the current settings")
+	_ = settingsApplyCmd.MarkFlagRequired("filename")
+}
+
+func applySettingsFile(inputFile string) error {
+	contents, err := readInputFile(inputFile)
+	if err != nil {
+		return err
+	}
+	fileSettings, err := settings.ParseDocument(inputFile, contents)
+	if err != nil {
+		return err
+	}
+	schema, err := settings.PreferencesSchema()
+	if err != nil {
+		return err
+	}
+	if validationErrors := schema.Validate(fileSettings); len(validationErrors) > 0 {
+		for _, validationError := range validationErrors {
+			fmt.Fprintln(os.Stderr, validationError.Error())
+		}
+		return fmt.Errorf("%s: found %d invalid setting(s)", inputFile, len(validationErrors))
+	}
+	delete(fileSettings, "version")
+
+	currentSettings, err := getCurrentSettings()
+	if err != nil {
+		return err
+	}
+	changes, err := settings.Diff(currentSettings, settings.Merge(currentSettings, fileSettings))
+	if err != nil {
+		return err
+	}
+	printSettingsDiff(currentSettings, changes)
+	if settingsApplyFlags.DryRun {
+		return nil
+	}
+	return updateSettings(changes, currentSettings)
+}
+
+// printSettingsDiff shows the current and new values of each changed setting.
+func printSettingsDiff(currentSettings, changes map[string]interface{}) {
+	for _, line := range settingsDiffLines(currentSettings, changes) {
+		fmt.Println(line)
+	}
+}
+
+func settingsDiffLines(currentSettings, changes map[string]interface{}) []string {
+	var lines []string
+	flatChanges := settings.Flatten("", changes)
+	for _, name := range sortedKeys(flatChanges) {
+		currentValue, err := settings.Get(currentSettings, name)
+		current := "(unset)"
+		if err == nil {
+			current = formatSettingValue(currentValue)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s", name, current, formatSettingValue(flatChanges[name])))
+	}
+	return lines
+}
@@ -0,0 +1,135 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/deploymentprofile"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var settingsExplainCmd = &cobra.Command{
+	Use:   "explain [name]",
+	Short: "Show where the value of each setting comes from",
+	Long: `Show the current value of each setting, and where that value comes from:
+
+  default          the built-in default value
+  profile default  the value from the defaults deployment profile
+  locked           the value is locked by the locked deployment profile
+  user             the value has been changed by the user
+
+With a dotted name, only that setting, or the settings under it, are shown.
+Deployment profiles in the Windows registry are only taken into account for locked settings.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return explainSettings(args)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(settingsExplainCmd)
+	supportOutputFormats(settingsExplainCmd)
+	settingsExplainCmd.ValidArgsFunction = completeSettingName(settings.PreferencesSchema)
+}
+
+func explainSettings(args []string) error {
+	currentSettings, err := getCurrentSettings()
+	if err != nil {
+		return err
+	}
+	lockedSettings, err := getLockedSettings()
+	if err != nil {
+		return err
+	}
+	defaultSettings, err := settings.DefaultSettings()
+	if err != nil {
+		return err
+	}
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	profile, err := deploymentprofile.Read(appPaths)
+	if err != nil {
+		return err
+	}
+
+	explanations := settings.Explain(currentSettings, defaultSettings, profile.Defaults, lockedSettings)
+	if len(args) > 0 {
+		if _, err := settings.SplitPath(args[0]); err != nil {
+			return err
+		}
+		var matching []settings.Explanation
+		for _, explanation := range explanations {
+			if explanation.Name == args[0] || strings.HasPrefix(explanation.Name, args[0]+".") {
+				matching = append(matching, explanation)
+			}
+		}
+		if len(matching) == 0 {
+			return fmt.Errorf("setting %q not found", args[0])
+		}
+		explanations = matching
+	}
+
+	table := output.Table{Headers: []string{"SETTING", "VALUE", "SOURCE"}}
+	for _, explanation := range explanations {
+		table.Rows = append(table.Rows, []string{explanation.Name, formatSettingValue(explanation.Value), string(explanation.Source)})
+	}
+	if outputPrinter != nil {
+		if err := printOutput(explanations, table); err != nil {
+			return err
+		}
+	} else if err := output.WriteTable(os.Stdout, table); err != nil {
+		return err
+	}
+
+	if profile.Defaults != nil {
+		fmt.Fprintf(os.Stderr, "Defaults deployment profile: %s\n", profile.DefaultsPath)
+	}
+	if profile.Locked != nil {
+		fmt.Fprintf(os.Stderr, "Locked deployment profile: %s\n", profile.LockedPath)
+	}
+	return nil
+}
+
+// getLockedSettings returns the tree of locked settings, where a `true` leaf marks a locked setting.
+func getLockedSettings() (map[string]interface{}, error) {
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	rdClient := client.NewRDClient(connectionInfo)
+	result, err := client.ProcessRequestForUtility(rdClient.DoRequest("GET", client.VersionCommand("", "settings/locked")))
+	if err != nil {
+		return nil, err
+	}
+	var lockedSettings map[string]interface{}
+	if err := json.Unmarshal(result, &lockedSettings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal locked settings API response: %w", err)
+	}
+	return lockedSettings, nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var settingsGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Show the value of a setting",
+	Long: `Show the value of a setting given by its dotted name, for example:
+
+> rdctl settings get kubernetes.version
+
+Strings, numbers and booleans are shown as plain text; objects and lists are shown as JSON.
+With no name, all the settings are shown.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return getSetting(args)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(settingsGetCmd)
+	supportOutputFormats(settingsGetCmd)
+	settingsGetCmd.ValidArgsFunction = completeSettingName(settings.PreferencesSchema)
+}
+
+func getSetting(args []string) error {
+	currentSettings, err := getCurrentSettings()
+	if err != nil {
+		return err
+	}
+	var value interface{} = currentSettings
+	prefix := ""
+	if len(args) > 0 {
+		prefix = args[0]
+		value, err = settings.Get(currentSettings, prefix)
+		if err != nil {
+			return err
+		}
+	}
+	if outputPrinter != nil {
+		return printOutput(value, settingsTable(prefix, value))
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		jsonBuffer, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(jsonBuffer))
+	case nil:
+		fmt.Println("null")
+	default:
+		fmt.Println(value)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var settingsPatchFlags struct {
+	MergePatchFile string
+	JSONPatchFile  string
+}
+
+var settingsPatchCmd = &cobra.Command{
+	Use:   "patch",
+	Short: "Change settings with a JSON merge patch or a JSON patch",
+	Long: `Change settings with either:
+
+--merge FILE: a JSON merge patch (RFC 7386), for example '{"kubernetes": {"enabled": false}}'
+--json-patch FILE: a JSON patch (RFC 6902), for example '[{"op": "replace", "path": "/kubernetes/enabled", "value": false}]'
+
+Specify '-' for standard input. The patch is applied to the current settings,
+and only the resulting changes are sent to Rancher Desktop.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (settingsPatchFlags.MergePatchFile == "") == (settingsPatchFlags.JSONPatchFile == "") {
+			return fmt.Errorf("exactly one of --merge or --json-patch must be specified")
+		}
+		cmd.SilenceUsage = true
+		return patchSettings()
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(settingsPatchCmd)
+	settingsPatchCmd.Flags().StringVar(&settingsPatchFlags.MergePatchFile, "merge", "", "file containing a JSON merge patch (- for standard input)")
+	settingsPatchCmd.Flags().StringVar(&settingsPatchFlags.JSONPatchFile, "json-patch", "", "file containing a JSON patch (- for standard input)")
+}
+
+func patchSettings() error {
+	currentSettings, err := getCurrentSettings()
+	if err != nil {
+		return err
+	}
+	var desiredSettings map[string]interface{}
+	if settingsPatchFlags.MergePatchFile != "" {
+		patch, err := readInputFile(settingsPatchFlags.MergePatchFile)
+		if err != nil {
+			return err
+		}
+		desiredSettings, err = settings.ApplyMergePatch(currentSettings, patch)
+		if err != nil {
+			return err
+		}
+	} else {
+		patch, err := readInputFile(settingsPatchFlags.JSONPatchFile)
+		if err != nil {
+			return err
+		}
+		desiredSettings, err = settings.ApplyJSONPatch(currentSettings, patch)
+		if err != nil {
+			return err
+		}
+	}
+	changes, err := settings.Diff(currentSettings, desiredSettings)
+	if err != nil {
+		return err
+	}
+	return updateSettings(changes, currentSettings)
+}
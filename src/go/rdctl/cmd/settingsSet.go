@@ -0,0 +1,82 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var settingsSetCmd = &cobra.Command{
+	Use:   "set name=value...",
+	Short: "Change settings by their dotted names",
+	Long: `Change one or more settings given by their dotted names, for example:
+
+> rdctl settings set kubernetes.version=1.27.3 virtualMachine.memoryInGB=6
+> rdctl settings set containerEngine.allowedImages.patterns=docker.io,quay.io
+> rdctl settings set 'containerEngine.allowedImages.patterns=["docker.io"]'
+
+Values are converted to the type of the current value of the setting.
+Lists can be given either as comma-separated values or as a JSON array.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return setSettings(args)
+	},
+}
+
+func init() {
+	settingsCmd.AddCommand(settingsSetCmd)
+	settingsSetCmd.ValidArgsFunction = completeSettingAssignment(settings.PreferencesSchema)
+}
+
+func setSettings(args []string) error {
+	schema, err := settings.PreferencesSchema()
+	if err != nil {
+		return err
+	}
+	currentSettings, err := getCurrentSettings()
+	if err != nil {
+		return err
+	}
+	desiredSettings := settings.DeepCopy(currentSettings)
+	for _, arg := range args {
+		name, rawValue, err := settings.ParseAssignment(arg)
+		if err != nil {
+			return err
+		}
+		// The server ignores settings it doesn't know, so a misspelt name would do nothing.
+		if schema.Lookup(name) == nil {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		currentValue, _ := settings.Get(currentSettings, name)
+		value, err := settings.ParseValue(name, rawValue, currentValue)
+		if err != nil {
+			return err
+		}
+		if err := settings.Set(desiredSettings, name, value); err != nil {
+			return err
+		}
+	}
+	changes, err := settings.Diff(currentSettings, desiredSettings)
+	if err != nil {
+		return err
+	}
+	return updateSettings(changes, currentSettings)
+}
@@ -18,7 +18,9 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"regexp"
@@ -26,7 +28,9 @@ import (
 	"strings"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shell"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/encoding/unicode"
@@ -34,7 +38,7 @@ import (
 
 // shellCmd represents the shell command
 var shellCmd = &cobra.Command{
-	Use:   "shell",
+	Use:   "shell [flags] [command [args...]]",
 	Short: "Run an interactive shell or a command in a Rancher Desktop-managed VM",
 	Long: `Run an interactive shell or a command in a Rancher Desktop-managed VM. For example:
 
@@ -44,63 +48,140 @@ var shellCmd = &cobra.Command{
 -- Runs 'ls -CF' from /tmp on the VM
 > rdctl shell bash -c "cd .. ; pwd"
 -- Usual way of running multiple statements on a single call
+> rdctl shell --vm-user root --env DEBUG=1 -- apk info
+-- Runs 'apk info' as root, with DEBUG set
+
+Flags must come before the command; everything after the command name is passed to it.
+When the current directory is shared with the VM, the command runs in the same directory
+in the VM, unless --workdir is given. The exit code of the command is kept; a command
+killed by a signal exits with 128 plus the signal number, as in a shell.
 `,
-	DisableFlagParsing: true,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Do manual flag parsing looking to see if we should give help instead.
-		if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
-			return cmd.Help()
-		}
 		return doShellCommand(cmd, args)
 	},
 }
 
+var shellFlags struct {
+	workdir string
+	env     []string
+	vmUser  string
+	tty     bool
+	noTTY   bool
+}
+
 func init() {
 	rootCmd.AddCommand(shellCmd)
+	// Stop at the command name, so its flags aren't taken as flags of rdctl.
+	shellCmd.Flags().SetInterspersed(false)
+	shellCmd.Flags().StringVarP(&shellFlags.workdir, "workdir", "w", "", "directory in the VM to run the command in (default: the current directory, if it is shared with the VM)")
+	shellCmd.Flags().StringArrayVarP(&shellFlags.env, "env", "e", nil, "set an environment variable as KEY=VALUE, or pass KEY through from the host; can be repeated")
+	shellCmd.Flags().StringVarP(&shellFlags.vmUser, "vm-user", "u", "", "user in the VM to run the command as")
+	shellCmd.Flags().BoolVarP(&shellFlags.tty, "tty", "t", false, "require a terminal, failing if stdin and stdout aren't one")
+	shellCmd.Flags().BoolVarP(&shellFlags.noTTY, "no-tty", "T", false, "don't allocate a terminal, even when stdin and stdout are one")
+	shellCmd.MarkFlagsMutuallyExclusive("tty", "no-tty")
 }
 
 func doShellCommand(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
-	var commandName string
-	if runtime.GOOS == "windows" {
-		commandName = "wsl"
-		distroName := "rancher-desktop"
-		if !checkWSLIsRunning(distroName) {
-			// No further output wanted, so just exit with the desired status.
-			os.Exit(1)
-		}
-		args = append([]string{
-			"--distribution", distroName,
-			"--exec", "/usr/local/bin/wsl-exec"},
-			args...)
-	} else {
-		paths, err := p.GetPaths()
-		if err != nil {
-			return err
-		}
-		if err = directories.SetupLimaHome(paths.AppHome); err != nil {
-			return err
-		}
-		commandName, err = directories.GetLimactlPath()
+	if shellFlags.tty && !(output.IsTerminal(os.Stdin) && output.IsTerminal(os.Stdout)) {
+		return fmt.Errorf("--tty needs stdin and stdout to be a terminal")
+	}
+	env, err := shell.ParseEnv(shellFlags.env, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	opts := shell.Options{Workdir: shellFlags.workdir, Env: env, User: shellFlags.vmUser}
+	shellCommand, err := vmCommand(opts, args, shellFlags.workdir == "")
+	if err != nil {
+		return err
+	}
+	if shellFlags.noTTY {
+		// lima and wsl only set up a terminal when stdin and stdout are one, so pipes prevent it.
+		stdin, err := shellCommand.StdinPipe()
 		if err != nil {
 			return err
 		}
-		if !checkLimaIsRunning(commandName) {
+		go func() {
+			_, _ = io.Copy(stdin, os.Stdin)
+			stdin.Close()
+		}()
+		shellCommand.Stdout = struct{ io.Writer }{os.Stdout}
+	} else {
+		shellCommand.Stdin = os.Stdin
+		shellCommand.Stdout = os.Stdout
+	}
+	shellCommand.Stderr = os.Stderr
+	err = shellCommand.Run()
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		os.Exit(shell.ExitCode(exitError))
+	}
+	return err
+}
+
+// vmCommand returns the command that runs args in the VM, exiting if the VM isn't running.
+// With defaultWorkdir, a command without a working directory runs in the VM's path of
+// the current directory, when that is shared with the VM.
+func vmCommand(opts shell.Options, args []string, defaultWorkdir bool) (*exec.Cmd, error) {
+	if runtime.GOOS == "windows" {
+		if !checkWSLIsRunning(shell.WSLDistro) {
 			// No further output wanted, so just exit with the desired status.
 			os.Exit(1)
 		}
-		args = append([]string{"shell", "0"}, args...)
+		if defaultWorkdir && opts.Workdir == "" {
+			if cwd, err := os.Getwd(); err == nil {
+				opts.Workdir, _ = shell.WSLPath(cwd)
+			}
+		}
+		return exec.Command("wsl", shell.WSLArgs(opts, args)...), nil
 	}
-	shellCommand := exec.Command(commandName, args...)
-	shellCommand.Stdin = os.Stdin
-	shellCommand.Stdout = os.Stdout
-	shellCommand.Stderr = os.Stderr
-	return shellCommand.Run()
+	paths, err := p.GetPaths()
+	if err != nil {
+		return nil, err
+	}
+	if err = directories.SetupLimaHome(paths.AppHome); err != nil {
+		return nil, err
+	}
+	commandName, err := directories.GetLimactlPath()
+	if err != nil {
+		return nil, err
+	}
+	if !checkLimaIsRunning(commandName) {
+		// No further output wanted, so just exit with the desired status.
+		os.Exit(1)
+	}
+	if defaultWorkdir && opts.Workdir == "" {
+		opts.Workdir = limaWorkdir(paths.Lima)
+	}
+	return exec.Command(commandName, shell.LimaArgs(opts, args)...), nil
+}
+
+// limaWorkdir returns the path in the VM of the current directory, or an empty string
+// if it isn't shared with the VM.
+func limaWorkdir(limaHome string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	mounts, err := shell.LimaMounts(limaHome, homeDir)
+	if err != nil {
+		logrus.Debugf("Failed to read the VM mounts: %s", err)
+		return ""
+	}
+	workdir, _ := shell.TranslatePath(cwd, mounts)
+	return workdir
 }
 
 const restartDirective = "Either run 'rdctl start' or start the Rancher Desktop application first"
 
-func checkLimaIsRunning(commandName string) bool {
+// limaState returns the status of the Lima VM, e.g. "Running". The status is empty if
+// limactl printed none, in which case its error output is returned instead.
+func limaState(commandName string) (state, errorOutput string, err error) {
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 
@@ -108,20 +189,26 @@ func checkLimaIsRunning(commandName string) bool {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		logrus.Errorf("Failed to run %q: %s\n", cmd, err)
+		return "", "", fmt.Errorf("failed to run %q: %w", cmd, err)
+	}
+	// We should only have received the status for VM 0
+	return strings.TrimRight(stdout.String(), "\n"), stderr.String(), nil
+}
+
+func checkLimaIsRunning(commandName string) bool {
+	state, errorMsg, err := limaState(commandName)
+	if err != nil {
+		logrus.Errorf("%s\n", err)
 		return false
 	}
-	limaState := strings.TrimRight(stdout.String(), "\n")
-	// We can do an equals check here because we should only have received the status for VM 0
-	if limaState == "Running" {
+	if state == "Running" {
 		return true
 	}
-	if limaState != "" {
+	if state != "" {
 		fmt.Fprintf(os.Stderr,
-			"The Rancher Desktop VM needs to be in state \"Running\" in order to execute 'rdctl shell', but it is currently in state %q.\n%s.\n", limaState, restartDirective)
+			"The Rancher Desktop VM needs to be in state \"Running\" in order to execute 'rdctl shell', but it is currently in state %q.\n%s.\n", state, restartDirective)
 		return false
 	}
-	errorMsg := stderr.String()
 	if strings.Contains(errorMsg, "No instance matching 0 found.") {
 		logrus.Errorf("The Rancher Desktop VM needs to be created.\n%s.\n", restartDirective)
 	} else if len(errorMsg) > 0 {
@@ -132,36 +219,41 @@ func checkLimaIsRunning(commandName string) bool {
 	return false
 }
 
-func checkWSLIsRunning(distroName string) bool {
+// wslState returns the state of the WSL distribution, e.g. "Running", or an empty string
+// if it isn't listed.
+func wslState(distroName string) (string, error) {
 	// Ignore error messages; none are expected here
 	rawOutput, err := exec.Command("wsl", "--list", "--verbose").CombinedOutput()
 	if err != nil {
-		logrus.Errorf("Failed to run 'wsl --list --verbose': %s\n", err)
-		return false
+		return "", fmt.Errorf("failed to run 'wsl --list --verbose': %w", err)
 	}
 	decoder := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
 	output, err := decoder.Bytes(rawOutput)
 	if err != nil {
-		logrus.Errorf("Failed to read WSL output ([% q]...); error: %s\n", rawOutput[:12], err)
-		return false
+		return "", fmt.Errorf("failed to read WSL output ([% q]...); error: %w", rawOutput[:12], err)
 	}
-	isListed := false
-	targetState := ""
 	for _, line := range regexp.MustCompile(`\r?\n`).Split(string(output), -1) {
 		fields := regexp.MustCompile(`\s+`).Split(strings.TrimLeft(line, " \t"), -1)
 		if fields[0] == "*" {
 			fields = fields[1:]
 		}
 		if len(fields) >= 2 && fields[0] == distroName {
-			isListed = true
-			targetState = fields[1]
-			break
+			return fields[1], nil
 		}
 	}
+	return "", nil
+}
+
+func checkWSLIsRunning(distroName string) bool {
+	targetState, err := wslState(distroName)
+	if err != nil {
+		logrus.Errorf("%s\n", err)
+		return false
+	}
 	if targetState == "Running" {
 		return true
 	}
-	if !isListed {
+	if targetState == "" {
 		fmt.Fprintf(os.Stderr,
 			"The Rancher Desktop WSL needs to be running in order to execute 'rdctl shell', but it currently is not.\n%s.\n", restartDirective)
 		return false
@@ -170,3 +262,24 @@ func checkWSLIsRunning(distroName string) bool {
 		"The Rancher Desktop WSL needs to be in state \"Running\" in order to execute 'rdctl shell', but it is currently in state \"%s\".\n%s.\n", targetState, restartDirective)
 	return false
 }
+
+// vmIsRunning reports whether the VM is running, for commands that can do without it.
+func vmIsRunning() (bool, error) {
+	if runtime.GOOS == "windows" {
+		state, err := wslState(shell.WSLDistro)
+		return state == "Running", err
+	}
+	paths, err := p.GetPaths()
+	if err != nil {
+		return false, err
+	}
+	if err = directories.SetupLimaHome(paths.AppHome); err != nil {
+		return false, err
+	}
+	commandName, err := directories.GetLimactlPath()
+	if err != nil {
+		return false, err
+	}
+	state, _, err := limaState(commandName)
+	return state == "Running", err
+}
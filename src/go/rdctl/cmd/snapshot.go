@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -10,14 +9,11 @@ import (
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/spf13/cobra"
 )
 
-type errorPayloadType struct {
-	Error string `json:"error,omitempty"`
-}
-
 var outputJsonFormat bool
 var snapshotErrors []error
 
@@ -33,22 +29,25 @@ func init() {
 	snapshotErrors = make([]error, 0)
 }
 
+// snapshotJSONErrors reports whether errors should be written to stdout as JSON, either
+// because of the legacy `--json` flag or because of a structured `--output` format.
+func snapshotJSONErrors() bool {
+	return outputJsonFormat || (outputPrinter != nil && outputPrinter.IsStructured())
+}
+
 func exitWithJsonOrErrorCondition(e error) error {
 	if e != nil {
 		snapshotErrors = append(snapshotErrors, e)
 	}
-	if outputJsonFormat {
+	if snapshotJSONErrors() {
 		exitStatus := 0
 		for _, snapshotError := range snapshotErrors {
 			if snapshotError != nil {
 				exitStatus = 1
-				errorPayload := errorPayloadType{snapshotError.Error()}
-				jsonBuffer, err := json.Marshal(errorPayload)
-				if err != nil {
-					snapshotErrors = append(snapshotErrors, fmt.Errorf("error json-converting error messages: %w", err))
+				if err := output.WriteError(os.Stdout, snapshotError); err != nil {
+					snapshotErrors = append(snapshotErrors, err)
 					return errors.Join(snapshotErrors...)
 				}
-				fmt.Fprintf(os.Stdout, string(jsonBuffer)+"\n")
 			}
 		}
 		os.Exit(exitStatus)
@@ -64,7 +63,7 @@ func wrapSnapshotOperation(cmd *cobra.Command, appPaths paths.Paths, restartOnFa
 		return err
 	}
 	defer removeBackendLock(appPaths.AppHome)
-	if err := ensureBackendStopped(cmd); err != nil {
+	if err := ensureBackendStopped(fmt.Sprintf("do a snapshot-%s action", cmd.Name())); err != nil {
 		return err
 	}
 	if err := wrappedFunction(); err != nil {
@@ -112,7 +111,9 @@ func ensureBackendStarted() error {
 	return nil
 }
 
-func ensureBackendStopped(cmd *cobra.Command) error {
+// ensureBackendStopped stops and locks the backend if the main process is running; the
+// action is what needs it stopped, for the error when it is in neither steady state.
+func ensureBackendStopped(action string) error {
 	connectionInfo, err := getConnectionInfo()
 	if err != nil || connectionInfo == nil {
 		return err
@@ -129,7 +130,7 @@ func ensureBackendStopped(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to get backend state: %w", err)
 	}
 	if state.VMState != "STARTED" && state.VMState != "DISABLED" {
-		return fmt.Errorf("Rancher Desktop must be fully running or fully shut down to do a snapshot-%s action, state is currently %v", cmd.Name(), state.VMState)
+		return fmt.Errorf("Rancher Desktop must be fully running or fully shut down to %s, state is currently %v", action, state.VMState)
 	}
 
 	// Stop and lock the backend
@@ -166,6 +167,23 @@ func waitForVMState(rdClient client.RDClient, desiredStates []string) error {
 	return fmt.Errorf("timed out waiting for backend state in %s", desiredStates)
 }
 
+// waitForVMStateChange waits for the backend to leave the given state.
+func waitForVMStateChange(rdClient client.RDClient, fromState string) error {
+	interval := 1 * time.Second
+	numIntervals := 120
+	for i := 0; i < numIntervals; i = i + 1 {
+		state, err := rdClient.GetBackendState()
+		if err != nil {
+			return fmt.Errorf("failed to poll backend state: %w", err)
+		}
+		if state.VMState != fromState {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+	return fmt.Errorf("timed out waiting for backend to leave state %s", fromState)
+}
+
 func createBackendLock(appHome string) error {
 	if err := os.MkdirAll(appHome, 0o755); err != nil {
 		return fmt.Errorf("failed to create backend lock parent directory: %w", err)
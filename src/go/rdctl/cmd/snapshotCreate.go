@@ -24,6 +24,7 @@ var snapshotCreateCmd = &cobra.Command{
 
 func init() {
 	snapshotCmd.AddCommand(snapshotCreateCmd)
+	supportOutputFormats(snapshotCreateCmd)
 	snapshotCreateCmd.Flags().BoolVar(&outputJsonFormat, "json", false, "output json format")
 	snapshotCreateCmd.Flags().StringVar(&snapshotDescription, "description", "", "snapshot description")
 }
@@ -55,7 +56,7 @@ func createSnapshot(cmd *cobra.Command, args []string) error {
 	output, err := execCmd.CombinedOutput()
 	if err != nil {
 		msg := fmt.Errorf("`tmutil addexclusion` failed to add exclusion to TimeMachine: %w: %s", err, output)
-		if outputJsonFormat {
+		if snapshotJSONErrors() {
 			snapshotErrors = append(snapshotErrors, msg)
 		} else {
 			logrus.Errorln(msg)
@@ -21,6 +21,8 @@ var snapshotDeleteCmd = &cobra.Command{
 
 func init() {
 	snapshotCmd.AddCommand(snapshotDeleteCmd)
+	supportOutputFormats(snapshotDeleteCmd)
+	snapshotDeleteCmd.ValidArgsFunction = completeSnapshotNames
 	snapshotDeleteCmd.Flags().BoolVarP(&outputJsonFormat, "json", "", false, "output json format")
 }
 
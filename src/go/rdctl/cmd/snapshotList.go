@@ -6,9 +6,9 @@ import (
 	"os"
 	"sort"
 	"strings"
-	"text/tabwriter"
 	"time"
 
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
 	"github.com/spf13/cobra"
@@ -44,6 +44,7 @@ var snapshotListCmd = &cobra.Command{
 
 func init() {
 	snapshotCmd.AddCommand(snapshotListCmd)
+	supportOutputFormats(snapshotListCmd)
 	snapshotListCmd.Flags().BoolVar(&outputJsonFormat, "json", false, "output json format")
 }
 
@@ -58,6 +59,12 @@ func listSnapshot() error {
 		return fmt.Errorf("failed to list snapshots: %w", err)
 	}
 	sort.Sort(SortableSnapshots(snapshots))
+	if outputPrinter != nil {
+		for i := range snapshots {
+			snapshots[i].ID = ""
+		}
+		return printOutput(snapshots, snapshotTable(snapshots))
+	}
 	if outputJsonFormat {
 		return jsonOutput(snapshots)
 	}
@@ -81,8 +88,11 @@ func tabularOutput(snapshots []snapshot.Snapshot) error {
 		fmt.Fprintln(os.Stderr, "No snapshots present.")
 		return nil
 	}
-	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 4, ' ', 0)
-	fmt.Fprintf(writer, "NAME\tCREATED\tDESCRIPTION\n")
+	return output.WriteTable(os.Stdout, snapshotTable(snapshots))
+}
+
+func snapshotTable(snapshots []snapshot.Snapshot) output.Table {
+	table := output.Table{Headers: []string{"NAME", "CREATED", "DESCRIPTION"}}
 	for _, aSnapshot := range snapshots {
 		prettyCreated := aSnapshot.Created.Format(time.RFC1123)
 		desc := aSnapshot.Description
@@ -100,8 +110,7 @@ func tabularOutput(snapshots []snapshot.Snapshot) error {
 			desc += "..."
 		}
 
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", aSnapshot.Name, prettyCreated, desc)
+		table.Rows = append(table.Rows, []string{aSnapshot.Name, prettyCreated, desc})
 	}
-	writer.Flush()
-	return nil
+	return table
 }
@@ -20,6 +20,8 @@ var snapshotRestoreCmd = &cobra.Command{
 
 func init() {
 	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	supportOutputFormats(snapshotRestoreCmd)
+	snapshotRestoreCmd.ValidArgsFunction = completeSnapshotNames
 	snapshotRestoreCmd.Flags().BoolVarP(&outputJsonFormat, "json", "", false, "output json format")
 }
 
@@ -23,6 +23,7 @@ normal circumstances.`,
 
 func init() {
 	snapshotCmd.AddCommand(snapshotUnlockCmd)
+	supportOutputFormats(snapshotUnlockCmd)
 	snapshotUnlockCmd.Flags().BoolVarP(&outputJsonFormat, "json", "", false, "output json format")
 
 }
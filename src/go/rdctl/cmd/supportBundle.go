@@ -0,0 +1,117 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/supportbundle"
+	"github.com/spf13/cobra"
+)
+
+// supportBundleTimeout limits each request to the app, so that a hung app doesn't stop the bundle being written.
+const supportBundleTimeout = 10 * time.Second
+
+var supportBundleFile string
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle [FILE]",
+	Short: "Collect logs and configuration for a bug report",
+	Long: `Collect the information needed for a bug report into a gzipped tar file:
+the logs, settings.json, the Lima override.yaml and lima.yaml, the output of
+'rdctl paths', the app's description of itself and its diagnostics (when it is
+running), and the metadata of each snapshot.
+
+Passwords, tokens, registry credentials and proxy credentials are redacted.
+VM disks and SSH keys are never included. Check the bundle before sharing it.
+
+The bundle is written to FILE, or the file given with --file, or else to
+rancher-desktop-support-<time>.tar.gz in the current directory. The global
+--output (-o) flag selects the output format of other commands, and can't be
+used to name the bundle file.`,
+	Args: cobra.MaximumNArgs(1),
+	Annotations: map[string]string{
+		outputHintAnnotation: "give the bundle file as an argument, or with --file",
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if len(args) > 0 {
+			if supportBundleFile != "" {
+				return fmt.Errorf("the bundle file can be given either as an argument or with --file, not both")
+			}
+			supportBundleFile = args[0]
+		}
+		return createSupportBundle()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(supportBundleCmd)
+	supportBundleCmd.Flags().StringVarP(&supportBundleFile, "file", "f", "", "file to write the bundle to (default rancher-desktop-support-<time>.tar.gz)")
+}
+
+func createSupportBundle() error {
+	appPaths, err := p.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	sources := supportbundle.Sources{Paths: appPaths}
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		sources.ClientError = err
+	} else {
+		limitedInfo := *connectionInfo
+		if limitedInfo.Timeout == 0 || limitedInfo.Timeout > supportBundleTimeout {
+			limitedInfo.Timeout = supportBundleTimeout
+		}
+		limitedInfo.Retries = 0
+		sources.Client = client.NewRDClient(&limitedInfo)
+	}
+
+	now := time.Now()
+	outputPath := supportBundleFile
+	if outputPath == "" {
+		outputPath = supportbundle.DefaultFileName(now)
+	}
+	// Write to a temporary file first, so that a failure doesn't leave a partial bundle behind.
+	file, err := os.CreateTemp(filepath.Dir(outputPath), ".rdctl-support-bundle-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the bundle: %w", err)
+	}
+	defer os.Remove(file.Name())
+	manifest, err := supportbundle.Create(file, sources, now)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write the bundle: %w", err)
+	}
+	if err := os.Rename(file.Name(), outputPath); err != nil {
+		return fmt.Errorf("failed to write the bundle: %w", err)
+	}
+	for _, skipped := range manifest.Skipped {
+		fmt.Fprintf(os.Stderr, "Skipped %s: %s\n", skipped.Name, skipped.Reason)
+	}
+	fmt.Printf("Wrote %d files to %s\n", len(manifest.Files), outputPath)
+	return nil
+}
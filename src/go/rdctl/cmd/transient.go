@@ -0,0 +1,70 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// transientCmd represents the transient command
+var transientCmd = &cobra.Command{
+	Use:   "transient",
+	Short: "Query and update transient settings",
+	Long: `Query and update the transient settings, such as the page the Preferences dialog opens on.
+Transient settings only last until Rancher Desktop exits.`,
+}
+
+var transientOutputJSON bool
+
+func init() {
+	rootCmd.AddCommand(transientCmd)
+}
+
+// getTransientSettings returns the current transient settings as a generic JSON document.
+func getTransientSettings(rdClient client.RDClient) (map[string]interface{}, error) {
+	result, err := client.ProcessRequestForUtility(rdClient.DoRequest("GET", client.VersionCommand("", "transient_settings")))
+	if err != nil {
+		return nil, err
+	}
+	var transientSettings map[string]interface{}
+	if err := json.Unmarshal(result, &transientSettings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal transient settings API response: %w", err)
+	}
+	return transientSettings, nil
+}
+
+func newTransientClient() (client.RDClient, error) {
+	connectionInfo, err := config.GetConnectionInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection info: %w", err)
+	}
+	return client.NewRDClient(connectionInfo), nil
+}
+
+func printJSON(value interface{}) error {
+	jsonBuffer, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBuffer))
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var transientGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Show the transient settings",
+	Long: `Show the transient settings, or only those under the given dotted name, for example:
+
+> rdctl transient get preferences.navItem
+
+Use --json to show the settings as a JSON document.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return getTransientSetting(args)
+	},
+}
+
+func init() {
+	transientCmd.AddCommand(transientGetCmd)
+	supportOutputFormats(transientGetCmd)
+	transientGetCmd.ValidArgsFunction = completeSettingName(settings.TransientSettingsSchema)
+	transientGetCmd.Flags().BoolVar(&transientOutputJSON, "json", false, "output json format")
+}
+
+func getTransientSetting(args []string) error {
+	rdClient, err := newTransientClient()
+	if err != nil {
+		return err
+	}
+	transientSettings, err := getTransientSettings(rdClient)
+	if err != nil {
+		return err
+	}
+	var value interface{} = transientSettings
+	prefix := ""
+	if len(args) > 0 {
+		prefix = args[0]
+		if value, err = settings.Get(transientSettings, prefix); err != nil {
+			return err
+		}
+	}
+	if outputPrinter != nil {
+		return printOutput(value, settingsTable(prefix, value))
+	}
+	if transientOutputJSON {
+		return printJSON(value)
+	}
+	return output.WriteTable(os.Stdout, settingsTable(prefix, value))
+}
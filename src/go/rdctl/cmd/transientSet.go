@@ -0,0 +1,137 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+var transientSetCmd = &cobra.Command{
+	Use:   "set [name=value...]",
+	Short: "Update transient settings",
+	Long: `Update transient settings, either with the flags below, or with dotted-name assignments
+for settings that have no flag, for example:
+
+> rdctl transient set --no-modal-dialogs preferences.navItem.currentTabs.Kubernetes=general
+
+The changes are checked against the transient settings schema before they are sent.
+Use --json to show the resulting transient settings as a JSON document.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doTransientSetCommand(cmd, args)
+	},
+}
+
+func init() {
+	transientCmd.AddCommand(transientSetCmd)
+	options.UpdateTransientSetCommand(transientSetCmd)
+	registerSettingFlagCompletions(transientSetCmd, settings.TransientSettingsSchema)
+	transientSetCmd.ValidArgsFunction = completeSettingAssignment(settings.TransientSettingsSchema)
+	transientSetCmd.Flags().BoolVar(&transientOutputJSON, "json", false, "output the resulting transient settings in json format")
+}
+
+func doTransientSetCommand(cmd *cobra.Command, args []string) error {
+	changedSettings, err := options.UpdateTransientFieldsForJSON(cmd.Flags())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	} else if changedSettings == nil && len(args) == 0 {
+		return fmt.Errorf("%s command: no settings to change were given", cmd.CommandPath())
+	}
+	cmd.SilenceUsage = true
+
+	changes := map[string]interface{}{}
+	if changedSettings != nil {
+		jsonBuffer, err := json.Marshal(changedSettings)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(jsonBuffer, &changes); err != nil {
+			return err
+		}
+	}
+	rdClient, err := newTransientClient()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		currentSettings, err := getTransientSettings(rdClient)
+		if err != nil {
+			return err
+		}
+		for _, arg := range args {
+			name, rawValue, err := settings.ParseAssignment(arg)
+			if err != nil {
+				return err
+			}
+			// The setting may not exist yet, as with a new key under currentTabs.
+			currentValue, _ := settings.Get(currentSettings, name)
+			value, err := settings.ParseValue(name, rawValue, currentValue)
+			if err != nil {
+				return err
+			}
+			if err := settings.Set(changes, name, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	schema, err := settings.TransientSettingsSchema()
+	if err != nil {
+		return err
+	}
+	// Round-trip through JSON so integers from ParseValue are validated as numbers.
+	jsonBuffer, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	var proposed map[string]interface{}
+	if err := json.Unmarshal(jsonBuffer, &proposed); err != nil {
+		return err
+	}
+	if validationErrors := schema.Validate(proposed); len(validationErrors) > 0 {
+		for _, validationError := range validationErrors {
+			fmt.Fprintf(os.Stderr, "%s\n", validationError)
+		}
+		return fmt.Errorf("found %d invalid transient setting(s)", len(validationErrors))
+	}
+
+	result, err := client.ProcessRequestForUtility(rdClient.DoRequestWithPayload("PUT", client.VersionCommand("", "transient_settings"), bytes.NewBuffer(jsonBuffer)))
+	if err != nil {
+		return err
+	}
+	if transientOutputJSON {
+		transientSettings, err := getTransientSettings(rdClient)
+		if err != nil {
+			return err
+		}
+		return printJSON(transientSettings)
+	}
+	if len(result) > 0 {
+		fmt.Printf("Status: %s.\n", string(result))
+	} else {
+		fmt.Println("Operation successfully returned with no output.")
+	}
+	return nil
+}
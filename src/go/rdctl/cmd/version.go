@@ -19,15 +19,27 @@ package cmd
 import (
 	"fmt"
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
 	"github.com/spf13/cobra"
 )
 
+type versionInfo struct {
+	ClientVersion string `json:"clientVersion"`
+	APIVersion    string `json:"apiVersion"`
+}
+
 // showVersionCmd represents the showVersion command
 var showVersionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Shows the CLI version.",
 	Long:  `Shows the CLI version.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if outputPrinter != nil {
+			return printOutput(versionInfo{client.Version, client.ApiVersion}, output.Table{
+				Headers: []string{"CLIENT VERSION", "API VERSION"},
+				Rows:    [][]string{{client.Version, client.ApiVersion}},
+			})
+		}
 		_, err := fmt.Printf("rdctl client version: %s, targeting server version: %s\n", client.Version, client.ApiVersion)
 		return err
 	},
@@ -35,4 +47,5 @@ var showVersionCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(showVersionCmd)
+	supportOutputFormats(showVersionCmd)
 }
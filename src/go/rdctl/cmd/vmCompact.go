@@ -0,0 +1,173 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/directories"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/diskusage"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/shell"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/vmdisk"
+	"github.com/spf13/cobra"
+)
+
+var vmCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Give the space freed in the VM back to the host",
+	Long: `Shrink the data disk of the VM (diffdisk), which otherwise only grows, by giving
+the space freed in the VM back to the host.
+
+If the VM is running, the unused blocks of its file systems are first discarded with
+fstrim. Then the VM is stopped, the disk is rewritten without the blocks that are all
+zeros, and the VM is started again if Rancher Desktop is running. Rewriting the disk
+needs as much free space as the disk takes. Snapshots share blocks with the disk where
+the file system supports it; rewriting the disk stops that sharing, so the space may
+only be reclaimed once older snapshots are deleted.
+
+This can't be done while a snapshot operation is in progress, and is only supported on
+macOS and Linux, where the VM is run by Lima.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return compactVM()
+	},
+}
+
+func init() {
+	vmCmd.AddCommand(vmCompactCmd)
+}
+
+// qemuImgPath returns the path of the qemu-img shipped with Lima, or else the one in PATH.
+func qemuImgPath() (string, error) {
+	limactl, err := directories.GetLimactlPath()
+	if err != nil {
+		return "", err
+	}
+	bundled := filepath.Join(filepath.Dir(limactl), "qemu-img")
+	if _, err := os.Stat(bundled); err == nil {
+		return bundled, nil
+	}
+	return exec.LookPath("qemu-img")
+}
+
+// trimVM discards the unused blocks of the file systems in the VM, if it is running.
+func trimVM() error {
+	running, err := vmIsRunning()
+	if err != nil || !running {
+		return err
+	}
+	fmt.Println("Discarding unused blocks in the VM...")
+	command, err := vmCommand(shell.Options{User: "root"}, []string{"/bin/sh", "-c", vmdisk.TrimScript}, false)
+	if err != nil {
+		return err
+	}
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	return command.Run()
+}
+
+// compactDisk rewrites the disk image, in whichever format it is in.
+func compactDisk(path string) error {
+	format, err := vmdisk.Format(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compacting %s (%s)...\n", path, format)
+	if format == vmdisk.FormatRaw {
+		return vmdisk.RewriteSparse(path)
+	}
+	qemuImg, err := qemuImgPath()
+	if err != nil {
+		return fmt.Errorf("qemu-img is needed to compact a %s image: %w", format, err)
+	}
+	return vmdisk.ConvertQcow2(qemuImg, path)
+}
+
+func compactVM() error {
+	if runtime.GOOS == "windows" {
+		return errors.New("compacting the VM disk is only supported on macOS and Linux, where the VM is run by Lima")
+	}
+	paths, err := p.GetPaths()
+	if err != nil {
+		return fmt.Errorf("failed to get paths: %w", err)
+	}
+	if err := directories.SetupLimaHome(paths.AppHome); err != nil {
+		return err
+	}
+	diskPath := filepath.Join(paths.Lima, "0", "diffdisk")
+	before, err := diskusage.Measure(diskPath)
+	if err != nil {
+		return err
+	}
+	if before.Apparent == 0 {
+		return fmt.Errorf("there is no VM disk at %s", diskPath)
+	}
+	free, err := diskusage.FreeSpace(filepath.Dir(diskPath))
+	if err != nil {
+		return fmt.Errorf("failed to get the free space for %s: %w", diskPath, err)
+	}
+	if free < uint64(before.Allocated) {
+		return fmt.Errorf("compacting %s needs %s of free space, but only %s is available",
+			diskPath, output.FormatSize(before.Allocated), output.FormatSize(int64(free)))
+	}
+	// Holding the lock keeps snapshot operations from starting while the disk is rewritten.
+	if err := createBackendLock(paths.AppHome); err != nil {
+		return err
+	}
+	defer removeBackendLock(paths.AppHome)
+	if snapshots, err := snapshot.NewManager(paths).List(false); err == nil && len(snapshots) > 0 {
+		fmt.Fprintf(os.Stderr, "Note: the space shared with the %d snapshot(s) is only reclaimed once they are deleted.\n", len(snapshots))
+	}
+
+	if err := trimVM(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to discard unused blocks in the VM, so less space may be reclaimed: %s\n", err)
+	}
+	if err := ensureBackendStopped("compact the VM disk"); err != nil {
+		return err
+	}
+	return errors.Join(compactStoppedVM(diskPath, before), ensureBackendStarted())
+}
+
+// compactStoppedVM compacts the disk once the backend has been stopped, reporting the space reclaimed.
+func compactStoppedVM(diskPath string, before diskusage.Size) error {
+	// Without the main process, the backend can't be stopped for us.
+	if running, err := vmIsRunning(); err != nil {
+		return err
+	} else if running {
+		return errors.New("the VM is running without Rancher Desktop; stop it with 'rdctl shutdown' first")
+	}
+	if err := compactDisk(diskPath); err != nil {
+		return err
+	}
+	after, err := diskusage.Measure(diskPath)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compacted the VM disk from %s to %s, reclaiming %s.\n",
+		output.FormatSize(before.Allocated), output.FormatSize(after.Allocated),
+		output.FormatSize(max(before.Allocated-after.Allocated, 0)))
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	p "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/spf13/cobra"
+)
+
+const vmRestartNotice = "Restart the VM for the changes to take effect, e.g. with 'rdctl shutdown' and 'rdctl start'."
+
+var vmConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the Lima configuration overrides of the VM",
+	Long: `Manage the Lima configuration overrides of the VM, in override.yaml in the
+_config directory of the Lima directory shown by 'rdctl paths'. Lima applies these
+on top of the configuration Rancher Desktop generates, so they can add provisioning
+scripts, mounts, port forwards, environment variables and so on. The file is kept in
+snapshots. Changes take effect when the VM is restarted.
+
+Settings are named by their path in the file, with list items given by their index
+and keys containing dots given in quotes: for example 'env.HTTP_PROXY',
+'mounts[0].writable' and 'hostResolver.hosts["host.example.com"]'.
+
+Overrides are only used on macOS and Linux, where the VM is run by Lima.`,
+}
+
+func init() {
+	vmCmd.AddCommand(vmConfigCmd)
+}
+
+// overridePath returns the path of the Lima override.yaml file.
+func overridePath() (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", fmt.Errorf("VM configuration overrides are only used on macOS and Linux, where the VM is run by Lima")
+	}
+	paths, err := p.GetPaths()
+	if err != nil {
+		return "", fmt.Errorf("failed to get paths: %w", err)
+	}
+	return limaconfig.OverridePath(paths.Lima), nil
+}
+
+// installProvisionScript arranges for the script to be run as root in the VM each time it
+// starts: on Windows, by writing it to the provisioning directory, whose *.start scripts
+// are run in the WSL distribution, with the given name; otherwise, by an entry in the
+// Lima override.yaml, which is found again by the marker in the script.
+func installProvisionScript(paths p.Paths, wslScriptName, marker, script string) error {
+	if runtime.GOOS == "windows" {
+		wslScriptPath := filepath.Join(paths.Config, "provisioning", wslScriptName)
+		if err := os.MkdirAll(filepath.Dir(wslScriptPath), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(wslScriptPath, []byte(script), 0o755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", wslScriptPath, err)
+		}
+	} else {
+		configPath := limaconfig.OverridePath(paths.Lima)
+		config, err := limaconfig.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if err := config.SetProvisionScript(marker, script); err != nil {
+			return fmt.Errorf("failed to update %s: %w", configPath, err)
+		}
+		if err := config.Save(configPath); err != nil {
+			return fmt.Errorf("failed to write %s: %w", configPath, err)
+		}
+	}
+	fmt.Fprintln(os.Stderr, vmRestartNotice)
+	return nil
+}
+
+// checkOverrides reports the problems in the configuration on stderr, and returns an error if there are any.
+func checkOverrides(config *limaconfig.Config, path string) error {
+	problems, err := config.Validate()
+	if err != nil {
+		return err
+	}
+	return reportValidationErrors(path, problems)
+}
+
+func reportValidationErrors(path string, problems []settings.ValidationError) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem.Error())
+	}
+	return fmt.Errorf("%s: found %d invalid setting(s)", path, len(problems))
+}
+
+// completeVMConfigName completes the names of the settings in the Lima configuration.
+var completeVMConfigName = completeSettingName(limaconfig.Schema)
@@ -0,0 +1,136 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var vmConfigEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the Lima configuration overrides",
+	Long: `Edit override.yaml with the editor given by $VISUAL or $EDITOR (vi by default).
+The file is only changed if the result is valid; when it isn't, you can edit it again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return editVMConfig()
+	},
+}
+
+func init() {
+	vmConfigCmd.AddCommand(vmConfigEditCmd)
+}
+
+// editorCommand returns the command line of the user's editor.
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.Fields(os.Getenv(name)); len(editor) > 0 {
+			return editor
+		}
+	}
+	return []string{"vi"}
+}
+
+func editVMConfig() error {
+	path, err := overridePath()
+	if err != nil {
+		return err
+	}
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	file, err := os.CreateTemp("", "override-*.yaml")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	_, err = file.Write(original)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	for {
+		editor := editorCommand()
+		editCommand := exec.Command(editor[0], append(editor[1:], tempPath)...)
+		editCommand.Stdin = os.Stdin
+		editCommand.Stdout = os.Stdout
+		editCommand.Stderr = os.Stderr
+		if err := editCommand.Run(); err != nil {
+			return fmt.Errorf("failed to run editor %q: %w; your changes are in %s", strings.Join(editor, " "), err, tempPath)
+		}
+		edited, err := os.ReadFile(tempPath)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, original) {
+			os.Remove(tempPath)
+			fmt.Println("No changes made.")
+			return nil
+		}
+		config, err := limaconfig.Parse(edited)
+		if err == nil {
+			err = checkOverrides(config, tempPath)
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to parse the file: %s\n", err)
+			err = fmt.Errorf("%s is not valid", tempPath)
+		}
+		if err == nil {
+			// Save the file as it was written, so that its layout is kept.
+			if err := limaconfig.WriteFile(path, edited); err != nil {
+				return fmt.Errorf("failed to write %s: %w; your changes are in %s", path, err, tempPath)
+			}
+			os.Remove(tempPath)
+			fmt.Fprintln(os.Stderr, vmRestartNotice)
+			return nil
+		}
+		if !output.IsTerminal(os.Stdin) || !confirm("Edit the file again?", true) {
+			return fmt.Errorf("%s was not changed; your changes are in %s", path, tempPath)
+		}
+	}
+}
+
+// confirm asks a yes/no question on the terminal, with the given default answer.
+func confirm(question string, defaultYes bool) bool {
+	choices := "[y/N]"
+	if defaultYes {
+		choices = "[Y/n]"
+	}
+	fmt.Fprintf(os.Stderr, "%s %s ", question, choices)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "" {
+		return defaultYes
+	}
+	return answer == "y" || answer == "yes"
+}
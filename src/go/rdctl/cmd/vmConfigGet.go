@@ -0,0 +1,96 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var vmConfigGetCmd = &cobra.Command{
+	Use:   "get [name]",
+	Short: "Show the Lima configuration overrides, or one of them",
+	Long: `Show the value of a setting in override.yaml, for example:
+
+> rdctl vm config get env.HTTP_PROXY
+
+Strings, numbers and booleans are shown as plain text; lists and mappings are shown as YAML.
+With no name, the whole file is shown.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeVMConfigName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return getVMConfig(args)
+	},
+}
+
+func init() {
+	vmConfigCmd.AddCommand(vmConfigGetCmd)
+	supportOutputFormats(vmConfigGetCmd)
+}
+
+func getVMConfig(args []string) error {
+	path, err := overridePath()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 && outputPrinter == nil {
+		// Show the file as it is, with its comments.
+		contents, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		_, err = os.Stdout.Write(contents)
+		return err
+	}
+	config, err := limaconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+		value, err = config.Get(name)
+	} else {
+		value, err = config.Value()
+	}
+	if err != nil {
+		return err
+	}
+	if outputPrinter != nil {
+		return printOutput(value, settingsTable(name, value))
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		yamlBuffer, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(yamlBuffer))
+	case nil:
+		fmt.Println("null")
+	default:
+		fmt.Println(value)
+	}
+	return nil
+}
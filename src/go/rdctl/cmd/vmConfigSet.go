@@ -0,0 +1,103 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	"github.com/spf13/cobra"
+)
+
+var vmConfigSetFlags struct {
+	append bool
+	delete bool
+}
+
+var vmConfigSetCmd = &cobra.Command{
+	Use:   "set name [value]",
+	Short: "Change one of the Lima configuration overrides",
+	Long: `Change the value of a setting in override.yaml. Values of settings that aren't strings
+are read as YAML, so lists and mappings can be given too. For example:
+
+> rdctl vm config set env.HTTP_PROXY http://proxy.example.com:3128
+> rdctl vm config set --append portForwards '{guestPort: 8080, hostPort: 18080}'
+> rdctl vm config set --append provision '{mode: system, script: "#!/bin/sh\napk add htop"}'
+> rdctl vm config set --delete env.HTTP_PROXY
+
+The file is only changed if the result is valid. Comments in the file are kept.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if vmConfigSetFlags.delete {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	ValidArgsFunction: completeVMConfigName,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return setVMConfig(args)
+	},
+}
+
+func init() {
+	vmConfigCmd.AddCommand(vmConfigSetCmd)
+	vmConfigSetCmd.Flags().BoolVar(&vmConfigSetFlags.append, "append", false, "add the value to the end of a list")
+	vmConfigSetCmd.Flags().BoolVar(&vmConfigSetFlags.delete, "delete", false, "remove the setting")
+	vmConfigSetCmd.MarkFlagsMutuallyExclusive("append", "delete")
+}
+
+func setVMConfig(args []string) error {
+	path, err := overridePath()
+	if err != nil {
+		return err
+	}
+	config, err := limaconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if vmConfigSetFlags.delete {
+		err = config.Delete(name)
+	} else {
+		var value interface{}
+		if vmConfigSetFlags.append {
+			value, err = limaconfig.ParseValue(name+"[0]", args[1])
+		} else {
+			value, err = limaconfig.ParseValue(name, args[1])
+		}
+		if err != nil {
+			return err
+		}
+		if vmConfigSetFlags.append {
+			err = config.Append(name, value)
+		} else {
+			err = config.Set(name, value)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkOverrides(config, path); err != nil {
+		return fmt.Errorf("%w, so it was not changed", err)
+	}
+	if err := config.Save(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	fmt.Fprintln(os.Stderr, vmRestartNotice)
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	"github.com/spf13/cobra"
+)
+
+var vmConfigValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check the Lima configuration overrides",
+	Long: `Check override.yaml, or another file of Lima configuration overrides, against the
+structure of the Lima configuration. Unknown settings, values of the wrong type,
+mounts without a location and provisioning entries without a script are reported.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return validateVMConfig(args)
+	},
+}
+
+func init() {
+	vmConfigCmd.AddCommand(vmConfigValidateCmd)
+}
+
+func validateVMConfig(args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+		// Unlike override.yaml, a file that was asked for has to exist.
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if path, err = overridePath(); err != nil {
+			return err
+		}
+	}
+	config, err := limaconfig.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := checkOverrides(config, path); err != nil {
+		return err
+	}
+	fmt.Printf("%s is valid.\n", path)
+	return nil
+}
@@ -13,11 +13,11 @@ require (
 	github.com/stretchr/testify v1.8.1
 	golang.org/x/sys v0.10.0
 	golang.org/x/text v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,197 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apispec gives access to the OpenAPI description of the command API (command-api.yaml),
+// as embedded in rdctl when the CLI code is generated.
+package apispec
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	options "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+)
+
+// Methods lists the HTTP methods that can appear in a path item, in display order.
+var Methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+
+// Spec is the subset of an OpenAPI document that rdctl uses.
+type Spec struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation describes one method on one path.
+type Operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	RequestBody *RequestBody `json:"requestBody"`
+}
+
+// RequestBody describes the body an operation accepts.
+type RequestBody struct {
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	Content     map[string]struct {
+		Schema json.RawMessage `json:"schema"`
+	} `json:"content"`
+}
+
+// Endpoint is one method on one path, for listing.
+type Endpoint struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Summary string `json:"summary"`
+}
+
+// Load parses the embedded command API description.
+func Load() (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal([]byte(options.CommandAPISpec), &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the command API description: %w", err)
+	}
+	return &spec, nil
+}
+
+// Endpoints lists every operation, ordered by path and then by method.
+func (spec *Spec) Endpoints() []Endpoint {
+	var endpoints []Endpoint
+	for _, path := range spec.sortedPaths() {
+		for _, method := range spec.PathMethods(path) {
+			summary := strings.Join(strings.Fields(spec.Paths[path][strings.ToLower(method)].Summary), " ")
+			endpoints = append(endpoints, Endpoint{Method: method, Path: path, Summary: summary})
+		}
+	}
+	return endpoints
+}
+
+// PathMethods returns the methods defined for a path, in upper case.
+func (spec *Spec) PathMethods(path string) []string {
+	var methods []string
+	for _, method := range Methods {
+		if _, ok := spec.Paths[path][strings.ToLower(method)]; ok {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// CompletePath returns the paths starting with the given prefix. Paths under the
+// current API version can also be completed without their `/v1/` prefix, as
+// `rdctl api` accepts them in that form.
+func (spec *Spec) CompletePath(prefix, apiVersion string) []string {
+	versionPrefix := "/" + apiVersion + "/"
+	var matches []string
+	for _, path := range spec.sortedPaths() {
+		if strings.HasPrefix(path, prefix) {
+			matches = append(matches, path)
+		} else if shortPath, ok := strings.CutPrefix(path, versionPrefix); ok && prefix != "" && strings.HasPrefix(shortPath, prefix) {
+			matches = append(matches, shortPath)
+		}
+	}
+	return matches
+}
+
+// Lookup finds the operation for a method on an endpoint, ignoring any query string.
+func (spec *Spec) Lookup(method, endpoint string) (*Operation, error) {
+	path, _, _ := strings.Cut(endpoint, "?")
+	operations, ok := spec.Paths[path]
+	if !ok {
+		return nil, fmt.Errorf("unknown endpoint %s; run 'rdctl api --list' to see the available endpoints", path)
+	}
+	operation, ok := operations[strings.ToLower(method)]
+	if !ok {
+		return nil, fmt.Errorf("method %s is not supported by %s; supported methods: %s", method, path, strings.Join(spec.PathMethods(path), ", "))
+	}
+	return operation, nil
+}
+
+// ValidateBody checks a request body against the JSON schema of the operation.
+// Bodies for operations without a JSON schema are only checked to be valid JSON, if non-empty.
+// Only what the server would reject is reported: a missing body is left for the server to
+// complain about, and settings it doesn't know, such as those of older versions, are ignored.
+func (spec *Spec) ValidateBody(operation *Operation, body []byte) error {
+	var doc interface{}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("request body is not valid JSON: %w", err)
+		}
+	}
+	if operation.RequestBody == nil {
+		return nil
+	}
+	if doc == nil {
+		return nil
+	}
+	content, ok := operation.RequestBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	schema, err := spec.resolveSchema(content.Schema)
+	if err != nil {
+		return err
+	}
+	if schema.Type != "object" {
+		return nil
+	}
+	docMap, ok := doc.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("request body must be a JSON object")
+	}
+	var messages []string
+	for _, validationError := range schema.Validate(docMap) {
+		if validationError.Message != settings.UnknownSetting {
+			messages = append(messages, validationError.Error())
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("invalid request body:\n  %s", strings.Join(messages, "\n  "))
+	}
+	return nil
+}
+
+// resolveSchema parses a schema, following a top-level reference to `#/components/schemas`.
+func (spec *Spec) resolveSchema(rawSchema json.RawMessage) (*settings.Schema, error) {
+	var reference struct {
+		Ref string `json:"$ref"`
+	}
+	if err := json.Unmarshal(rawSchema, &reference); err != nil {
+		return nil, fmt.Errorf("failed to parse request schema: %w", err)
+	}
+	if reference.Ref != "" {
+		name, ok := strings.CutPrefix(reference.Ref, "#/components/schemas/")
+		if !ok || spec.Components.Schemas[name] == nil {
+			return nil, fmt.Errorf("unsupported schema reference %q", reference.Ref)
+		}
+		rawSchema = spec.Components.Schemas[name]
+	}
+	return settings.ParseSchema(string(rawSchema))
+}
+
+func (spec *Spec) sortedPaths() []string {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
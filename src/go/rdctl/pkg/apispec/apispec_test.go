@@ -0,0 +1,94 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apispec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadSpec(t *testing.T) *Spec {
+	spec, err := Load()
+	require.NoError(t, err)
+	return spec
+}
+
+func TestEndpoints(t *testing.T) {
+	endpoints := loadSpec(t).Endpoints()
+	assert.Contains(t, endpoints, Endpoint{Method: "GET", Path: "/v1/settings", Summary: "List the current preference settings"})
+	assert.Contains(t, endpoints, Endpoint{Method: "PUT", Path: "/v1/settings", Summary: "Updates the specified preference settings"})
+	for i := 1; i < len(endpoints); i++ {
+		assert.LessOrEqual(t, endpoints[i-1].Path, endpoints[i].Path)
+	}
+}
+
+func TestCompletePath(t *testing.T) {
+	spec := loadSpec(t)
+	assert.Equal(t, []string{"/v1/settings", "/v1/settings/locked"}, spec.CompletePath("/v1/set", "v1"))
+	assert.Equal(t, []string{"settings", "settings/locked"}, spec.CompletePath("set", "v1"))
+	assert.Contains(t, spec.CompletePath("", "v1"), "/")
+	assert.NotContains(t, spec.CompletePath("", "v1"), "settings")
+}
+
+func TestLookup(t *testing.T) {
+	spec := loadSpec(t)
+	operation, err := spec.Lookup("delete", "/v1/snapshots?name=foo")
+	require.NoError(t, err)
+	assert.Equal(t, "deleteSnapshot", operation.OperationID)
+
+	_, err = spec.Lookup("GET", "/v1/no_such_thing")
+	assert.ErrorContains(t, err, "unknown endpoint /v1/no_such_thing")
+	_, err = spec.Lookup("POST", "/v1/settings")
+	assert.ErrorContains(t, err, "supported methods: GET, PUT")
+}
+
+func TestValidateBody(t *testing.T) {
+	spec := loadSpec(t)
+	putSettings, err := spec.Lookup("PUT", "/v1/settings")
+	require.NoError(t, err)
+	getSettings, err := spec.Lookup("GET", "/v1/settings")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		operation *Operation
+		body      string
+		errorText string
+	}{
+		{"valid settings", putSettings, `{"version": 10, "kubernetes": {"enabled": false}}`, ""},
+		{"invalid type", putSettings, `{"kubernetes": {"enabled": "no"}}`, "kubernetes.enabled: expected a boolean"},
+		{"unknown setting", putSettings, `{"kubernetes": {"enable": true, "enabled": "no"}}`, "kubernetes.enabled: expected a boolean"},
+		{"only unknown settings", putSettings, `{"kubernetes": {"memoryInGB": 4}}`, ""},
+		{"not an object", putSettings, `[]`, "must be a JSON object"},
+		{"not JSON", putSettings, `{"kubernetes":`, "not valid JSON"},
+		{"missing body", putSettings, "", ""},
+		{"no body needed", getSettings, "", ""},
+		{"unchecked body", getSettings, `{"anything": 1}`, ""},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := spec.ValidateBody(testCase.operation, []byte(testCase.body))
+			if testCase.errorText == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, testCase.errorText)
+			}
+		})
+	}
+}
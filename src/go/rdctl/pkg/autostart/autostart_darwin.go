@@ -38,13 +38,20 @@ type launchAgentFileData struct {
 	RancherDesktopPath string
 }
 
+func getLaunchAgentFilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(homeDir, "Library", "LaunchAgents", "io.rancherdesktop.autostart.plist"), nil
+}
+
 func EnsureAutostart(autostartDesired bool) error {
 	// get path to LaunchAgent file
-	homeDir, err := os.UserHomeDir()
+	launchAgentFilePath, err := getLaunchAgentFilePath()
 	if err != nil {
-		return fmt.Errorf("failed to find home directory: %w", err)
+		return err
 	}
-	launchAgentFilePath := filepath.Join(homeDir, "Library", "LaunchAgents", "io.rancherdesktop.autostart.plist")
 
 	if autostartDesired {
 		// ensure LaunchAgent directory is created
@@ -83,6 +90,21 @@ func EnsureAutostart(autostartDesired bool) error {
 	return nil
 }
 
+// IsAutostartEnabled reports whether Rancher Desktop is set to start when the user logs in.
+func IsAutostartEnabled() (bool, error) {
+	launchAgentFilePath, err := getLaunchAgentFilePath()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(launchAgentFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check for LaunchAgent file: %w", err)
+	}
+	return true, nil
+}
+
 func getDesiredLaunchAgentFileContents() ([]byte, error) {
 	rancherDesktopPath, err := utils.GetRDPath()
 	if err != nil {
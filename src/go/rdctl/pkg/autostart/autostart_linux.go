@@ -74,6 +74,17 @@ func EnsureAutostart(autostartDesired bool) error {
 	return nil
 }
 
+// IsAutostartEnabled reports whether Rancher Desktop is set to start when the user logs in.
+func IsAutostartEnabled() (bool, error) {
+	_, err := os.Stat(autostartFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to check for autostart .desktop file: %w", err)
+	}
+	return true, nil
+}
+
 func getDesiredAutostartFileContents() ([]byte, error) {
 	// Look for existing application .desktop files in expected locations.
 	// This part applies to rpm, deb and AppImageLauncher installs.
@@ -42,3 +42,20 @@ func EnsureAutostart(autostartDesired bool) error {
 
 	return nil
 }
+
+// IsAutostartEnabled reports whether Rancher Desktop is set to start when the user logs in.
+func IsAutostartEnabled() (bool, error) {
+	autostartKey, err := registry.OpenKey(registry.CURRENT_USER, relativeKey, registry.QUERY_VALUE)
+	if err != nil {
+		return false, fmt.Errorf("failed to open registry key: %w", err)
+	}
+	defer autostartKey.Close()
+
+	_, _, err = autostartKey.GetStringValue(nameValue)
+	if errors.Is(err, registry.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get name value %q of registry key %q: %w", nameValue, absoluteKey, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,197 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certs keeps the CA certificates added with `rdctl certs`, and generates the
+// provisioning script that installs them into the trust store of the VM when it starts.
+package certs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pemExtension is the extension of the certificate files in the certificates directory.
+const pemExtension = ".pem"
+
+// minIDLength is the shortest fingerprint prefix accepted to pick a certificate.
+const minIDLength = 4
+
+// Certificate is a CA certificate stored in the certificates directory.
+type Certificate struct {
+	// Fingerprint is the SHA-256 digest of the certificate, in lower-case hex.
+	Fingerprint string    `json:"fingerprint"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	Path        string    `json:"path"`
+	// PEM is the certificate in PEM format.
+	PEM []byte `json:"-"`
+}
+
+// Expired reports whether the certificate is no longer valid at the time.
+func (cert Certificate) Expired(now time.Time) bool {
+	return now.After(cert.NotAfter)
+}
+
+// Dir returns the directory the certificates are stored in.
+func Dir(configDir string) string {
+	return filepath.Join(configDir, "certs")
+}
+
+func newCertificate(der []byte, path string) (Certificate, error) {
+	parsed, err := x509.ParseCertificate(der)
+	if err != nil {
+		return Certificate{}, err
+	}
+	digest := sha256.Sum256(der)
+	return Certificate{
+		Fingerprint: hex.EncodeToString(digest[:]),
+		Subject:     parsed.Subject.String(),
+		Issuer:      parsed.Issuer.String(),
+		NotBefore:   parsed.NotBefore,
+		NotAfter:    parsed.NotAfter,
+		Path:        path,
+		PEM:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}, nil
+}
+
+// ParsePEM returns the certificates in PEM data, such as a CA bundle. Blocks other than
+// certificates, like private keys, are an error, so that they aren't stored by mistake.
+func ParsePEM(contents []byte) ([]Certificate, error) {
+	var result []Certificate
+	rest := contents
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("found a %q block; only certificates can be added", block.Type)
+		}
+		cert, err := newCertificate(block.Bytes, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %d: %w", len(result)+1, err)
+		}
+		result = append(result, cert)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, fmt.Errorf("unexpected data after certificate %d", len(result))
+	}
+	return result, nil
+}
+
+// List returns the stored certificates, sorted by subject.
+func List(dir string) ([]Certificate, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var result []Certificate
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != pemExtension {
+			continue
+		}
+		certPath := filepath.Join(dir, entry.Name())
+		contents, err := os.ReadFile(certPath)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParsePEM(contents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", certPath, err)
+		}
+		for _, cert := range parsed {
+			cert.Path = certPath
+			result = append(result, cert)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Subject != result[j].Subject {
+			return result[i].Subject < result[j].Subject
+		}
+		return result[i].Fingerprint < result[j].Fingerprint
+	})
+	return result, nil
+}
+
+// Add stores the certificates in the directory, each in a file named after its fingerprint.
+// It returns the certificates that were added; the others were already there.
+func Add(dir string, certs []Certificate) ([]Certificate, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var added []Certificate
+	for _, cert := range certs {
+		cert.Path = filepath.Join(dir, cert.Fingerprint+pemExtension)
+		if _, err := os.Stat(cert.Path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(cert.Path, cert.PEM, 0o644); err != nil {
+			return added, err
+		}
+		added = append(added, cert)
+	}
+	return added, nil
+}
+
+// normalizeID turns a fingerprint as it might be written, e.g. "AB:CD:...", into the form used in Certificate.
+func normalizeID(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, ":", ""))
+}
+
+// Find returns the stored certificate whose fingerprint starts with the id,
+// which must be long enough to pick only one.
+func Find(certs []Certificate, id string) (Certificate, error) {
+	prefix := normalizeID(id)
+	if len(prefix) < minIDLength {
+		return Certificate{}, fmt.Errorf("fingerprint %q is too short; give at least %d hex digits", id, minIDLength)
+	}
+	var matches []Certificate
+	for _, cert := range certs {
+		if strings.HasPrefix(cert.Fingerprint, prefix) {
+			matches = append(matches, cert)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return Certificate{}, fmt.Errorf("no certificate with fingerprint %q", id)
+	case 1:
+		return matches[0], nil
+	}
+	return Certificate{}, fmt.Errorf("fingerprint %q matches %d certificates; give more of it", id, len(matches))
+}
+
+// Remove deletes the stored certificate.
+func Remove(cert Certificate) error {
+	return os.Remove(cert.Path)
+}
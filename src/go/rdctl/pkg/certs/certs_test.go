@@ -0,0 +1,153 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCertPEM creates a self-signed CA certificate.
+func newCertPEM(t *testing.T, commonName string, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParsePEM(t *testing.T) {
+	expiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	bundle := append(newCertPEM(t, "First CA", expiry), newCertPEM(t, "Second CA", expiry)...)
+	parsed, err := ParsePEM(append([]byte("# comment before the certificates\n"), bundle...))
+	require.NoError(t, err)
+	require.Len(t, parsed, 2)
+	assert.Equal(t, "CN=First CA", parsed[0].Subject)
+	assert.Equal(t, "CN=Second CA", parsed[1].Subject)
+	assert.Equal(t, expiry, parsed[0].NotAfter.UTC())
+	assert.Len(t, parsed[0].Fingerprint, 64)
+	assert.NotEqual(t, parsed[0].Fingerprint, parsed[1].Fingerprint)
+	assert.True(t, parsed[0].Expired(expiry.Add(time.Second)))
+	assert.False(t, parsed[0].Expired(expiry.Add(-time.Second)))
+
+	_, err = ParsePEM([]byte("not a certificate"))
+	assert.ErrorContains(t, err, "no PEM certificates found")
+	_, err = ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("secret")}))
+	assert.ErrorContains(t, err, "only certificates can be added")
+	_, err = ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}))
+	assert.ErrorContains(t, err, "failed to parse certificate 1")
+	_, err = ParsePEM(append(bundle, []byte("trailing")...))
+	assert.ErrorContains(t, err, "unexpected data after certificate 2")
+}
+
+func TestAddListRemove(t *testing.T) {
+	dir := Dir(t.TempDir())
+	stored, err := List(dir)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+
+	expiry := time.Now().Add(time.Hour)
+	parsed, err := ParsePEM(append(newCertPEM(t, "B CA", expiry), newCertPEM(t, "A CA", expiry)...))
+	require.NoError(t, err)
+	added, err := Add(dir, parsed)
+	require.NoError(t, err)
+	assert.Len(t, added, 2)
+	added, err = Add(dir, parsed[:1])
+	require.NoError(t, err)
+	assert.Empty(t, added, "certificates must only be added once")
+
+	stored, err = List(dir)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "CN=A CA", stored[0].Subject, "certificates should be sorted by subject")
+	assert.FileExists(t, stored[0].Path)
+
+	found, err := Find(stored, strings.ToUpper(stored[1].Fingerprint[:8]))
+	require.NoError(t, err)
+	assert.Equal(t, stored[1].Fingerprint, found.Fingerprint)
+	withColons := stored[1].Fingerprint[:2] + ":" + stored[1].Fingerprint[2:4] + ":" + stored[1].Fingerprint[4:6]
+	found, err = Find(stored, withColons)
+	require.NoError(t, err)
+	assert.Equal(t, stored[1].Fingerprint, found.Fingerprint)
+	_, err = Find(stored, "abc")
+	assert.ErrorContains(t, err, "too short")
+	_, err = Find(stored, "ffffffffffffffffffff")
+	assert.ErrorContains(t, err, "no certificate")
+
+	require.NoError(t, Remove(found))
+	stored, err = List(dir)
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+
+	require.NoError(t, os.WriteFile(stored[0].Path, []byte("corrupt"), 0o644))
+	_, err = List(dir)
+	assert.Error(t, err)
+}
+
+func TestScript(t *testing.T) {
+	parsed, err := ParsePEM(newCertPEM(t, "Corp CA", time.Now().Add(time.Hour)))
+	require.NoError(t, err)
+	script := Script(parsed)
+	assert.True(t, strings.HasPrefix(script, "#!/bin/sh\n"+Marker+"\n"))
+	assert.Contains(t, script, "rm -f /usr/local/share/ca-certificates/rdctl-*.crt\n")
+	assert.Contains(t, script, "cat > /usr/local/share/ca-certificates/rdctl-"+parsed[0].Fingerprint[:16]+".crt <<'EOF'\n"+string(parsed[0].PEM)+"EOF\n")
+	assert.True(t, strings.HasSuffix(script, "update-ca-certificates\n"))
+
+	assert.NotContains(t, Script(nil), "cat >", "with no certificates, the old ones should only be removed")
+}
+
+func TestLimaProvisionScript(t *testing.T) {
+	config, err := limaconfig.Parse([]byte("provision:\n  - mode: user\n    script: echo mine\n"))
+	require.NoError(t, err)
+	require.NoError(t, config.SetProvisionScript(Marker, Script(nil)))
+	require.NoError(t, config.SetProvisionScript(Marker, "#!/bin/sh\n"+Marker+"\necho updated\n"))
+
+	provisioning, err := config.Get("provision")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"mode": "user", "script": "echo mine"},
+		map[string]interface{}{"mode": "system", "script": "#!/bin/sh\n" + Marker + "\necho updated\n"},
+	}, provisioning)
+
+	config, err = limaconfig.Parse(nil)
+	require.NoError(t, err)
+	require.NoError(t, config.SetProvisionScript(Marker, Script(nil)))
+	problems, err := config.Validate()
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
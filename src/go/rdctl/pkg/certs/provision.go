@@ -0,0 +1,47 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Marker is a line in the generated script, by which it is found again to be replaced.
+const Marker = "# Managed by 'rdctl certs'; changes will be overwritten."
+
+// vmCertsDir is where the certificates are installed in the VM. The app installs the
+// certificates of the host there too, named rd-*.crt, so these have a different prefix.
+const vmCertsDir = "/usr/local/share/ca-certificates"
+
+// Script returns the shell script that installs the certificates into the trust store of
+// the VM, replacing the ones installed before. With no certificates, it removes them.
+func Script(certs []Certificate) string {
+	var builder strings.Builder
+	builder.WriteString("#!/bin/sh\n")
+	builder.WriteString(Marker + "\n")
+	builder.WriteString("set -o errexit\n")
+	fmt.Fprintf(&builder, "mkdir -p %s\n", vmCertsDir)
+	fmt.Fprintf(&builder, "rm -f %s/rdctl-*.crt\n", vmCertsDir)
+	for _, cert := range certs {
+		fmt.Fprintf(&builder, "cat > %s/rdctl-%s.crt <<'EOF'\n", vmCertsDir, cert.Fingerprint[:16])
+		builder.Write(cert.PEM)
+		builder.WriteString("EOF\n")
+	}
+	builder.WriteString("update-ca-certificates\n")
+	return builder.String()
+}
@@ -2,13 +2,17 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
 )
 
 const (
@@ -48,19 +52,66 @@ func validateBackendState(state BackendState) error {
 
 type RDClientImpl struct {
 	connectionInfo *config.ConnectionInfo
+	httpClient     *http.Client
+	// setupErr is any error from setting up the http client, such as an unreadable certificate;
+	// it is returned from every request.
+	setupErr error
+	// retryDelay is the delay before the first retry of a failed request; it doubles after each retry.
+	retryDelay time.Duration
 }
 
 func NewRDClient(connectionInfo *config.ConnectionInfo) *RDClientImpl {
+	httpClient, err := newHTTPClient(connectionInfo)
 	return &RDClientImpl{
 		connectionInfo: connectionInfo,
+		httpClient:     httpClient,
+		setupErr:       err,
+		retryDelay:     initialRetryDelay,
+	}
+}
+
+// newHTTPClient returns a client that connects over the Unix domain socket in the connection info,
+// if there is one. If the socket can't be connected to (for example, because it was left behind
+// by a server that has since exited), it falls back to TCP when a port is known.
+// For https connections, the client uses the TLS options in the connection info.
+func newHTTPClient(connectionInfo *config.ConnectionInfo) (*http.Client, error) {
+	if connectionInfo.SocketPath == "" && connectionInfo.Scheme != "https" {
+		return &http.Client{Timeout: connectionInfo.Timeout}, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if connectionInfo.SocketPath != "" {
+		dialer := &net.Dialer{}
+		transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, "unix", connectionInfo.SocketPath)
+			if err != nil && connectionInfo.Port != "" {
+				return dialer.DialContext(ctx, network, address)
+			}
+			return conn, err
+		}
+	}
+	if connectionInfo.Scheme == "https" {
+		tlsConfig, err := newTLSConfig(connectionInfo)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
 	}
+	return &http.Client{Transport: transport, Timeout: connectionInfo.Timeout}, nil
 }
 
 func (client *RDClientImpl) makeURL(host string, port string, command string) string {
+	scheme := client.connectionInfo.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	address := host
+	if port != "" {
+		address = fmt.Sprintf("%s:%s", host, port)
+	}
 	if strings.HasPrefix(command, "/") {
-		return fmt.Sprintf("http://%s:%s%s", host, port, command)
+		return fmt.Sprintf("%s://%s%s", scheme, address, command)
 	}
-	return fmt.Sprintf("http://%s:%s/%s", host, port, command)
+	return fmt.Sprintf("%s://%s/%s", scheme, address, command)
 }
 
 func (client *RDClientImpl) DoRequest(method string, command string) (*http.Response, error) {
@@ -68,33 +119,48 @@ func (client *RDClientImpl) DoRequest(method string, command string) (*http.Resp
 	if err != nil {
 		return nil, err
 	}
-	return http.DefaultClient.Do(req)
+	return client.do(req)
 }
 
 func (client *RDClientImpl) DoRequestWithPayload(method string, command string, payload io.Reader) (*http.Response, error) {
+	if client.setupErr != nil {
+		return nil, client.setupErr
+	}
 	url := client.makeURL(client.connectionInfo.Host, client.connectionInfo.Port, command)
 	req, err := http.NewRequest(method, url, payload)
 	if err != nil {
 		return nil, err
 	}
-	req.SetBasicAuth(client.connectionInfo.User, client.connectionInfo.Password)
+	client.setAuth(req)
 	req.Header.Add("Content-Type", "application/json")
 	req.Close = true
-	return http.DefaultClient.Do(req)
+	return client.do(req)
 }
 
 func (client *RDClientImpl) getRequestObject(method string, command string) (*http.Request, error) {
+	if client.setupErr != nil {
+		return nil, client.setupErr
+	}
 	url := client.makeURL(client.connectionInfo.Host, client.connectionInfo.Port, command)
 	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.SetBasicAuth(client.connectionInfo.User, client.connectionInfo.Password)
+	client.setAuth(req)
 	req.Header.Add("Content-Type", "text/plain")
 	req.Close = true
 	return req, nil
 }
 
+// setAuth adds basic auth to the request. Connections over the socket don't need credentials,
+// so the header is left out when there are none.
+func (client *RDClientImpl) setAuth(req *http.Request) {
+	if client.connectionInfo.SocketPath != "" && client.connectionInfo.User == "" && client.connectionInfo.Password == "" {
+		return
+	}
+	req.SetBasicAuth(client.connectionInfo.User, client.connectionInfo.Password)
+}
+
 func (client *RDClientImpl) GetBackendState() (BackendState, error) {
 	body, err := ProcessRequestForUtility(client.DoRequest("GET", VersionCommand("", "backend_state")))
 	if err != nil {
@@ -0,0 +1,89 @@
+//go:build unix
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSocketServer starts a server on a Unix domain socket that replies with the given name
+// and the basic auth user of each request.
+func newSocketServer(t *testing.T, name string) string {
+	dir, err := os.MkdirTemp("", "rdctl-socket")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "rd-engine.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(replyHandler(name))
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+	return socketPath
+}
+
+func replyHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		fmt.Fprintf(w, "%s %s %s", name, r.URL.Path, user)
+	})
+}
+
+func newTCPServer(t *testing.T, name string) (string, string) {
+	server := httptest.NewServer(replyHandler(name))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return serverURL.Hostname(), serverURL.Port()
+}
+
+func doGet(t *testing.T, connectionInfo *config.ConnectionInfo) (string, error) {
+	response, err := NewRDClient(connectionInfo).DoRequest("GET", VersionCommand("", "settings"))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	return string(body), nil
+}
+
+func TestSocketTransport(t *testing.T) {
+	t.Run("uses the socket", func(t *testing.T) {
+		socketPath := newSocketServer(t, "socket")
+		host, port := newTCPServer(t, "tcp")
+		body, err := doGet(t, &config.ConnectionInfo{User: "user", Password: "password", Host: host, Port: port, SocketPath: socketPath})
+		require.NoError(t, err)
+		assert.Equal(t, "socket /v1/settings user", body)
+	})
+	t.Run("works without a port or credentials", func(t *testing.T) {
+		socketPath := newSocketServer(t, "socket")
+		body, err := doGet(t, &config.ConnectionInfo{Host: "127.0.0.1", SocketPath: socketPath})
+		require.NoError(t, err)
+		assert.Equal(t, "socket /v1/settings ", body)
+	})
+	t.Run("falls back to TCP when the socket is stale", func(t *testing.T) {
+		host, port := newTCPServer(t, "tcp")
+		socketPath := filepath.Join(t.TempDir(), "missing.sock")
+		body, err := doGet(t, &config.ConnectionInfo{User: "user", Password: "password", Host: host, Port: port, SocketPath: socketPath})
+		require.NoError(t, err)
+		assert.Equal(t, "tcp /v1/settings user", body)
+	})
+	t.Run("fails without a port to fall back to", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "missing.sock")
+		_, err := doGet(t, &config.ConnectionInfo{Host: "127.0.0.1", SocketPath: socketPath})
+		assert.Error(t, err)
+	})
+}
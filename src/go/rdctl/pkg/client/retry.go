@@ -0,0 +1,87 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	initialRetryDelay = 250 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+)
+
+// do sends the request, retrying it up to the configured number of times when the server
+// can't be reached. Requests that only read are also retried when the server drops the
+// connection, or reports that it is unavailable.
+// The delay between attempts doubles each time, up to maxRetryDelay.
+func (client *RDClientImpl) do(req *http.Request) (*http.Response, error) {
+	retries := 0
+	if isIdempotent(req.Method) {
+		retries = client.connectionInfo.Retries
+	}
+	if retries > 0 && req.Body != nil && req.GetBody == nil {
+		// The payload has to be sent again on each attempt, so keep a copy of it.
+		payload, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(payload)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+	delay := client.retryDelay
+	for attempt := 0; ; attempt++ {
+		response, err := client.httpClient.Do(req)
+		if attempt >= retries || !shouldRetry(req.Method, response, err) {
+			return response, err
+		}
+		if response != nil {
+			response.Body.Close()
+		}
+		time.Sleep(delay)
+		delay = min(delay*2, maxRetryDelay)
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// isIdempotent reports whether sending a request with the method more than once has the same
+// effect as sending it once (RFC 9110, section 9.2.2).
+func isIdempotent(method string) bool {
+	return isSafe(method) || method == http.MethodPut || method == http.MethodDelete
+}
+
+// isSafe reports whether a request with the method only reads (RFC 9110, section 9.2.1).
+func isSafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether a failed request should be sent again. On this API, PUT and
+// DELETE requests start actions, such as a factory reset or a restart of the backend, so
+// they are only sent again when the connection was refused, and they never reached the
+// server; after a timeout or an error response, they may have been carried out already.
+func shouldRetry(method string, response *http.Response, err error) bool {
+	if !isSafe(method) {
+		return err != nil && errors.Is(handleConnectionRefused(err), ErrConnectionRefused)
+	}
+	if err != nil {
+		return true
+	}
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer returns a server that drops the connection for the first `failures` requests,
+// and then echoes the request method and body. It also returns a count of the requests received.
+func newFlakyServer(t *testing.T, failures int32) (*config.ConnectionInfo, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s", r.Method, body)
+	}))
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return &config.ConnectionInfo{User: "user", Password: "password", Host: serverURL.Hostname(), Port: serverURL.Port()}, &requests
+}
+
+func newTestClient(connectionInfo *config.ConnectionInfo) *RDClientImpl {
+	client := NewRDClient(connectionInfo)
+	client.retryDelay = time.Millisecond
+	return client
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("retries GET requests", func(t *testing.T) {
+		connectionInfo, requests := newFlakyServer(t, 2)
+		connectionInfo.Retries = 3
+		body, err := ProcessRequestForUtility(newTestClient(connectionInfo).DoRequest("GET", VersionCommand("", "settings")))
+		require.NoError(t, err)
+		assert.Equal(t, "GET ", string(body))
+		assert.EqualValues(t, 3, requests.Load())
+	})
+	t.Run("sends the payload again", func(t *testing.T) {
+		connectionInfo, requests := newFlakyServer(t, 1)
+		connectionInfo.Retries = 1
+		// io.MultiReader hides the type of the payload, so the client has to buffer it itself.
+		payload := io.MultiReader(bytes.NewBufferString(`{"version":10}`))
+		body, err := ProcessRequestForUtility(newTestClient(connectionInfo).DoRequestWithPayload("GET", VersionCommand("", "settings"), payload))
+		require.NoError(t, err)
+		assert.Equal(t, `GET {"version":10}`, string(body))
+		assert.EqualValues(t, 2, requests.Load())
+	})
+	t.Run("gives up after the given number of retries", func(t *testing.T) {
+		connectionInfo, requests := newFlakyServer(t, 10)
+		connectionInfo.Retries = 2
+		_, err := newTestClient(connectionInfo).DoRequest("GET", VersionCommand("", "settings"))
+		assert.Error(t, err)
+		assert.EqualValues(t, 3, requests.Load())
+	})
+	t.Run("doesn't retry POST requests", func(t *testing.T) {
+		connectionInfo, requests := newFlakyServer(t, 1)
+		connectionInfo.Retries = 3
+		_, err := newTestClient(connectionInfo).DoRequestWithPayload("POST", VersionCommand("", "extensions/install"), bytes.NewBufferString("{}"))
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("doesn't retry PUT requests that reached the server", func(t *testing.T) {
+		connectionInfo, requests := newFlakyServer(t, 1)
+		connectionInfo.Retries = 3
+		_, err := newTestClient(connectionInfo).DoRequestWithPayload("PUT", VersionCommand("", "factory_reset"), bytes.NewBufferString("{}"))
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("retries PUT requests when the connection is refused", func(t *testing.T) {
+		// Find a free port, and only start listening on it after the first attempt.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().(*net.TCPAddr)
+		require.NoError(t, listener.Close())
+		var requests atomic.Int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s %s", r.Method, body)
+		}))
+		started := make(chan struct{})
+		go func() {
+			defer close(started)
+			time.Sleep(100 * time.Millisecond)
+			listener, err := net.Listen("tcp", address.String())
+			if assert.NoError(t, err) {
+				server.Listener.Close()
+				server.Listener = listener
+				server.Start()
+			}
+		}()
+		t.Cleanup(func() {
+			<-started
+			server.Close()
+		})
+		connectionInfo := &config.ConnectionInfo{Host: "127.0.0.1", Port: fmt.Sprint(address.Port), Retries: 20}
+		client := newTestClient(connectionInfo)
+		client.retryDelay = 50 * time.Millisecond
+		body, err := ProcessRequestForUtility(client.DoRequestWithPayload("PUT", VersionCommand("", "settings"), bytes.NewBufferString(`{"version":10}`)))
+		require.NoError(t, err)
+		assert.Equal(t, `PUT {"version":10}`, string(body))
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("doesn't retry by default", func(t *testing.T) {
+		connectionInfo, requests := newFlakyServer(t, 1)
+		_, err := newTestClient(connectionInfo).DoRequest("GET", VersionCommand("", "settings"))
+		assert.Error(t, err)
+		assert.EqualValues(t, 1, requests.Load())
+	})
+	t.Run("retries when the server is unavailable", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprint(w, "ready")
+		}))
+		defer server.Close()
+		serverURL, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		connectionInfo := &config.ConnectionInfo{Host: serverURL.Hostname(), Port: serverURL.Port(), Retries: 1}
+		body, err := ProcessRequestForUtility(newTestClient(connectionInfo).DoRequest("GET", VersionCommand("", "about")))
+		require.NoError(t, err)
+		assert.Equal(t, "ready", string(body))
+	})
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	connectionInfo := &config.ConnectionInfo{Host: serverURL.Hostname(), Port: serverURL.Port(), Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	_, err = newTestClient(connectionInfo).DoRequest("GET", VersionCommand("", "settings"))
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+)
+
+// newTLSConfig returns the TLS settings for an https connection.
+// When a certificate fingerprint is pinned, the server's certificate only needs to match it,
+// unless a CA certificate is also given, in which case the certificate must be signed by that CA as well.
+// That allows connecting to servers with self-signed certificates.
+func newTLSConfig(connectionInfo *config.ConnectionInfo) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if connectionInfo.CACert != "" {
+		pemData, err := os.ReadFile(connectionInfo.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("no certificates found in CA certificate file %q", connectionInfo.CACert)
+		}
+	}
+	if connectionInfo.ClientCert != "" {
+		clientCert, err := tls.LoadX509KeyPair(connectionInfo.ClientCert, connectionInfo.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	if connectionInfo.PinnedCertSHA256 != "" {
+		pinned, err := config.NormalizeFingerprint(connectionInfo.PinnedCertSHA256)
+		if err != nil {
+			return nil, err
+		}
+		// With InsecureSkipVerify, the standard verification (including the host name) is replaced by
+		// VerifyConnection, which checks the fingerprint, and the chain if there is a CA certificate.
+		tlsConfig.InsecureSkipVerify = true
+		rootCAs := tlsConfig.RootCAs
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return fmt.Errorf("server did not present a certificate")
+			}
+			leaf := state.PeerCertificates[0]
+			fingerprint := sha256.Sum256(leaf.Raw)
+			if hex.EncodeToString(fingerprint[:]) != pinned {
+				return fmt.Errorf("server certificate fingerprint %s does not match the pinned fingerprint %s", hex.EncodeToString(fingerprint[:]), pinned)
+			}
+			if rootCAs == nil {
+				return nil
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := leaf.Verify(x509.VerifyOptions{
+				DNSName:       state.ServerName,
+				Roots:         rootCAs,
+				Intermediates: intermediates,
+			})
+			return err
+		}
+	}
+	return tlsConfig, nil
+}
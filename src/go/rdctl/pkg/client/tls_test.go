@@ -0,0 +1,128 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHTTPSServer(t *testing.T, requireClientCert bool) (*httptest.Server, *config.ConnectionInfo) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			fmt.Fprintf(w, "hello %s", r.TLS.PeerCertificates[0].Subject.CommonName)
+		} else {
+			fmt.Fprint(w, "hello")
+		}
+	}))
+	if requireClientCert {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return server, &config.ConnectionInfo{
+		User:     "user",
+		Password: "password",
+		Scheme:   "https",
+		Host:     serverURL.Hostname(),
+		Port:     serverURL.Port(),
+	}
+}
+
+func writePEM(t *testing.T, name, blockType string, contents []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: contents}), 0o600))
+	return path
+}
+
+func writeClientCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rdctl-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return writePEM(t, "client.pem", "CERTIFICATE", certDER), writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+func getBody(connectionInfo *config.ConnectionInfo) (string, error) {
+	response, err := NewRDClient(connectionInfo).DoRequest("GET", VersionCommand("", "about"))
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	return string(body), err
+}
+
+func TestTLS(t *testing.T) {
+	t.Run("rejects untrusted certificates", func(t *testing.T) {
+		_, connectionInfo := newHTTPSServer(t, false)
+		_, err := getBody(connectionInfo)
+		assert.ErrorContains(t, err, "certificate")
+	})
+	t.Run("trusts the given CA", func(t *testing.T) {
+		server, connectionInfo := newHTTPSServer(t, false)
+		connectionInfo.CACert = writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+		body, err := getBody(connectionInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", body)
+	})
+	t.Run("reports unreadable CA files", func(t *testing.T) {
+		_, connectionInfo := newHTTPSServer(t, false)
+		connectionInfo.CACert = filepath.Join(t.TempDir(), "missing.pem")
+		_, err := getBody(connectionInfo)
+		assert.ErrorContains(t, err, "failed to read CA certificate")
+	})
+	t.Run("sends the client certificate", func(t *testing.T) {
+		server, connectionInfo := newHTTPSServer(t, true)
+		connectionInfo.CACert = writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+		connectionInfo.ClientCert, connectionInfo.ClientKey = writeClientCert(t)
+		body, err := getBody(connectionInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "hello rdctl-test", body)
+	})
+	t.Run("accepts a pinned certificate", func(t *testing.T) {
+		server, connectionInfo := newHTTPSServer(t, false)
+		fingerprint := sha256.Sum256(server.Certificate().Raw)
+		connectionInfo.PinnedCertSHA256 = hex.EncodeToString(fingerprint[:])
+		body, err := getBody(connectionInfo)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", body)
+	})
+	t.Run("rejects a certificate that doesn't match the pin", func(t *testing.T) {
+		_, connectionInfo := newHTTPSServer(t, false)
+		fingerprint := sha256.Sum256([]byte("some other certificate"))
+		connectionInfo.PinnedCertSHA256 = hex.EncodeToString(fingerprint[:])
+		_, err := getBody(connectionInfo)
+		assert.ErrorContains(t, err, "does not match the pinned fingerprint")
+	})
+}
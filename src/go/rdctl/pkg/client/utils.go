@@ -57,13 +57,13 @@ func ProcessRequestForUtility(response *http.Response, err error) ([]byte, error
 			// Prefer the error message in the body written by the command-server, not the one from the http server.
 			break
 		case 401:
-			return nil, fmt.Errorf("%s: user/password not accepted", response.Status)
+			return nil, &StatusError{response.StatusCode, fmt.Sprintf("%s: user/password not accepted", response.Status)}
 		case 413:
-			return nil, fmt.Errorf("%s", response.Status)
+			return nil, &StatusError{response.StatusCode, response.Status}
 		case 500:
-			return nil, fmt.Errorf("%s: server-side problem: please consult the server logs for more information", response.Status)
+			return nil, &StatusError{response.StatusCode, fmt.Sprintf("%s: server-side problem: please consult the server logs for more information", response.Status)}
 		default:
-			return nil, fmt.Errorf("%s (unexpected server error)", response.Status)
+			return nil, &StatusError{response.StatusCode, fmt.Sprintf("%s (unexpected server error)", response.Status)}
 		}
 	}
 
@@ -72,11 +72,21 @@ func ProcessRequestForUtility(response *http.Response, err error) ([]byte, error
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
 		if statusMessage != "" {
-			return nil, fmt.Errorf("server error return-code %d: %s", response.StatusCode, statusMessage)
+			return nil, &StatusError{response.StatusCode, fmt.Sprintf("server error return-code %d: %s", response.StatusCode, statusMessage)}
 		}
 		return nil, err
 	} else if statusMessage != "" {
-		return nil, fmt.Errorf("%s", string(body))
+		return nil, &StatusError{response.StatusCode, string(body)}
 	}
 	return body, nil
 }
+
+// StatusError is returned by ProcessRequestForUtility when the server responds with an error status.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
@@ -19,6 +19,8 @@ package config
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -28,6 +30,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
 	"github.com/spf13/cobra"
@@ -38,6 +42,15 @@ type CLIConfig struct {
 	User     string
 	Password string
 	Port     int
+	// Socket is the optional Unix domain socket the server listens on, as a path or `unix://` URL.
+	Socket string
+	// Host, and the TLS settings below, are only needed to connect to a remote server.
+	// The host can start with `https://` to connect over TLS.
+	Host             string
+	CACert           string `json:"caCert"`
+	ClientCert       string `json:"clientCert"`
+	ClientKey        string `json:"clientKey"`
+	PinnedCertSHA256 string `json:"pinnedCertSHA256"`
 }
 
 // ConnectionInfo stores the parameters needed to connect to an HTTP server
@@ -46,38 +59,74 @@ type ConnectionInfo struct {
 	Password string
 	Host     string
 	Port     string
+	// SocketPath is the Unix domain socket to connect to; when it is set, it is preferred over Host and Port.
+	SocketPath string
+	// Scheme is either "http" or "https"; an empty scheme means "http".
+	Scheme string
+	// CACert is the path of a PEM file with the certificate authorities to trust, instead of the system ones.
+	CACert string
+	// ClientCert and ClientKey are the paths of the PEM files for TLS client authentication.
+	ClientCert string
+	ClientKey  string
+	// PinnedCertSHA256 is the hex SHA-256 fingerprint the server's certificate must have.
+	PinnedCertSHA256 string
+	// Timeout limits how long each request may take; zero means no limit.
+	Timeout time.Duration
+	// Retries is how many more times an idempotent request is attempted when the server can't be reached.
+	Retries int
 }
 
+const socketURLPrefix = "unix://"
+
 var (
 	connectionSettings ConnectionInfo
 
 	configPath string
+	socketURL  string
 	// DefaultConfigPath - used to differentiate not being able to find a user-specified config file from the default
 	DefaultConfigPath string
+	// DefaultSocketPath is where the server's Unix domain socket is expected, next to the config file.
+	// It is empty inside WSL, as a socket created on the Windows side can't be reached from there.
+	// The app doesn't listen on a socket yet; this only prepares rdctl for when it does.
+	DefaultSocketPath string
+
+	finishOnce             sync.Once
+	finishIsImmediateError bool
+	finishErr              error
 )
 
 // DefineGlobalFlags sets up the global flags, available for all sub-commands
 func DefineGlobalFlags(rootCmd *cobra.Command) {
 	var configDir string
-	var err error
+	appPaths, err := paths.GetPaths()
+	if err != nil {
+		log.Fatalf("failed to get paths: %s", err)
+	}
 	if runtime.GOOS == "linux" && isWSLDistro() {
 		if configDir, err = wslifyConfigDir(); err != nil {
 			log.Fatalf("Can't get WSL config-dir: %v", err)
 		}
 		configDir = filepath.Join(configDir, "rancher-desktop")
 	} else {
-		appPaths, err := paths.GetPaths()
-		if err != nil {
-			log.Fatalf("failed to get paths: %s", err)
-		}
 		configDir = appPaths.AppHome
+		DefaultSocketPath = filepath.Join(configDir, "rd-engine.sock")
 	}
 	DefaultConfigPath = filepath.Join(configDir, "rd-engine.json")
+	// Contexts belong to this rdctl, so inside WSL they're kept in the distro rather than on the Windows host.
+	ContextsPath = filepath.Join(appPaths.Config, "rdctl", "contexts.json")
+	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "name of the connection context to use instead of the current one (see 'rdctl context')")
 	rootCmd.PersistentFlags().StringVar(&configPath, "config-path", "", fmt.Sprintf("config file (default %s)", DefaultConfigPath))
+	rootCmd.PersistentFlags().StringVar(&socketURL, "socket", "", "Unix domain socket of the server, as a path or unix:// URL; used in preference to --host and --port when it exists (the app doesn't create one yet)")
 	rootCmd.PersistentFlags().StringVar(&connectionSettings.User, "user", "", "overrides the user setting in the config file")
-	rootCmd.PersistentFlags().StringVar(&connectionSettings.Host, "host", "", "default is 127.0.0.1; most useful for WSL")
+	rootCmd.PersistentFlags().StringVar(&connectionSettings.Host, "host", "", "default is 127.0.0.1; most useful for WSL; prefix with https:// to connect over TLS")
 	rootCmd.PersistentFlags().StringVar(&connectionSettings.Port, "port", "", "overrides the port setting in the config file")
 	rootCmd.PersistentFlags().StringVar(&connectionSettings.Password, "password", "", "overrides the password setting in the config file")
+	rootCmd.PersistentFlags().StringVar(&connectionSettings.CACert, "cacert", "", "PEM file of the certificate authorities to trust for an https:// host")
+	rootCmd.PersistentFlags().StringVar(&connectionSettings.ClientCert, "client-cert", "", "PEM file of the client certificate for an https:// host")
+	rootCmd.PersistentFlags().StringVar(&connectionSettings.ClientKey, "client-key", "", "PEM file of the client key for an https:// host")
+	rootCmd.PersistentFlags().StringVar(&connectionSettings.PinnedCertSHA256, "pinned-cert-sha256", "", "SHA-256 fingerprint the certificate of an https:// host must have")
+	rootCmd.PersistentFlags().DurationVar(&connectionSettings.Timeout, "timeout", 0, "maximum time for each request to the server, such as 30s; 0 means no limit")
+	rootCmd.PersistentFlags().IntVar(&connectionSettings.Retries, "retry", 0, "number of times to retry idempotent requests when the server can't be reached, with exponential backoff")
 }
 
 // GetConnectionInfo returns the connection info if it has it, and an error message explaining why
@@ -85,49 +134,165 @@ func DefineGlobalFlags(rootCmd *cobra.Command) {
 // So if the user runs an `rdctl` command after a factory reset, there is no config file (in the default location),
 // but it might not be necessary. So only use the error message for the missing file if it is actually needed.
 func GetConnectionInfo() (*ConnectionInfo, error) {
-	isImmediateError, err := finishConnectionSettings()
-	if err != nil && (isImmediateError || insufficientConnectionInfo()) {
-		return nil, err
+	finishOnce.Do(func() {
+		finishIsImmediateError, finishErr = finishConnectionSettings()
+	})
+	if finishErr != nil && (finishIsImmediateError || insufficientConnectionInfo()) {
+		return nil, finishErr
 	}
 	return &connectionSettings, nil
 }
 
 func finishConnectionSettings() (bool, error) {
+	if connectionSettings.Timeout < 0 {
+		return true, fmt.Errorf("--timeout must not be negative")
+	}
+	if connectionSettings.Retries < 0 {
+		return true, fmt.Errorf("--retry must not be negative")
+	}
+	if err := applyContext(); err != nil {
+		return true, err
+	}
 	if configPath == "" {
 		configPath = DefaultConfigPath
 	}
+	// Asking for a specific host or port means the user wants TCP, even if there is a socket.
+	tcpRequested := connectionSettings.Host != "" || connectionSettings.Port != ""
+	settings, isImmediateError, configErr := readConfigFile()
+	if configErr == nil {
+		if connectionSettings.User == "" {
+			connectionSettings.User = settings.User
+		}
+		if connectionSettings.Password == "" {
+			connectionSettings.Password = settings.Password
+		}
+		if connectionSettings.Port == "" {
+			connectionSettings.Port = strconv.Itoa(settings.Port)
+		}
+		if connectionSettings.Host == "" && settings.Host != "" {
+			connectionSettings.Host = settings.Host
+			tcpRequested = true
+		}
+		setIfEmpty(&connectionSettings.CACert, settings.CACert)
+		setIfEmpty(&connectionSettings.ClientCert, settings.ClientCert)
+		setIfEmpty(&connectionSettings.ClientKey, settings.ClientKey)
+		setIfEmpty(&connectionSettings.PinnedCertSHA256, settings.PinnedCertSHA256)
+	}
 	if connectionSettings.Host == "" {
 		connectionSettings.Host = "127.0.0.1"
 	}
+	if err := finishTLSSettings(); err != nil {
+		return true, err
+	}
+	if socketURL != "" {
+		socketPath, err := ParseSocketURL(socketURL)
+		if err != nil {
+			return true, err
+		}
+		connectionSettings.SocketPath = socketPath
+	} else if !tcpRequested {
+		// The credentials are sent over the socket too, so it is only preferred over TCP
+		// when no other user can connect to it.
+		if settings.Socket != "" {
+			socketPath, err := ParseSocketURL(settings.Socket)
+			if err != nil {
+				return configPath != DefaultConfigPath, fmt.Errorf("error in config file %q: %w", configPath, err)
+			}
+			if isPrivateSocket(socketPath) {
+				connectionSettings.SocketPath = socketPath
+			}
+		}
+		if connectionSettings.SocketPath == "" && isPrivateSocket(DefaultSocketPath) {
+			connectionSettings.SocketPath = DefaultSocketPath
+		}
+	}
+	return isImmediateError, configErr
+}
+
+// readConfigFile reads the config file. The returned boolean is true if an error should be
+// reported even when the connection info can be completed without the config file.
+func readConfigFile() (CLIConfig, bool, error) {
+	var settings CLIConfig
 	content, err := os.ReadFile(configPath)
 	if err != nil {
 		// If the default config file isn't available, it might not have been created yet,
 		// so don't complain if we don't need it.
 		// But if the user specified their own --config-path and it's not readable, complain immediately.
-		return configPath != DefaultConfigPath, err
+		return settings, configPath != DefaultConfigPath, err
 	}
-
-	var settings CLIConfig
 	if err = json.Unmarshal(content, &settings); err != nil {
-		return configPath != DefaultConfigPath, fmt.Errorf("error in config file %q: %w", configPath, err)
+		return CLIConfig{}, configPath != DefaultConfigPath, fmt.Errorf("error in config file %q: %w", configPath, err)
 	}
+	return settings, false, nil
+}
 
-	if connectionSettings.User == "" {
-		connectionSettings.User = settings.User
+func setIfEmpty(target *string, value string) {
+	if *target == "" {
+		*target = value
 	}
-	if connectionSettings.Password == "" {
-		connectionSettings.Password = settings.Password
+}
+
+// finishTLSSettings takes the scheme from the host, if it has one, and checks that the TLS options are consistent.
+func finishTLSSettings() error {
+	connectionSettings.Scheme = "http"
+	for _, scheme := range []string{"http", "https"} {
+		if host, found := strings.CutPrefix(connectionSettings.Host, scheme+"://"); found {
+			connectionSettings.Scheme = scheme
+			connectionSettings.Host = strings.TrimSuffix(host, "/")
+		}
+	}
+	usesTLSOptions := connectionSettings.CACert != "" || connectionSettings.ClientCert != "" ||
+		connectionSettings.ClientKey != "" || connectionSettings.PinnedCertSHA256 != ""
+	if usesTLSOptions && connectionSettings.Scheme != "https" {
+		return fmt.Errorf("--cacert, --client-cert, --client-key and --pinned-cert-sha256 can only be used with an https:// host")
+	}
+	if (connectionSettings.ClientCert == "") != (connectionSettings.ClientKey == "") {
+		return fmt.Errorf("--client-cert and --client-key must be used together")
 	}
-	if connectionSettings.Port == "" {
-		connectionSettings.Port = strconv.Itoa(settings.Port)
+	if connectionSettings.PinnedCertSHA256 != "" {
+		fingerprint, err := NormalizeFingerprint(connectionSettings.PinnedCertSHA256)
+		if err != nil {
+			return err
+		}
+		connectionSettings.PinnedCertSHA256 = fingerprint
 	}
-	return false, nil
+	return nil
+}
+
+// NormalizeFingerprint converts a SHA-256 certificate fingerprint, given as hex digits optionally
+// separated by colons (as shown by `openssl x509 -fingerprint -sha256`), to lower-case hex without separators.
+func NormalizeFingerprint(fingerprint string) (string, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(fingerprint, "sha256:"), ":", ""))
+	if len(normalized) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q: expected %d hex digits", fingerprint, sha256.Size*2)
+	}
+	if _, err := hex.DecodeString(normalized); err != nil {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q: %w", fingerprint, err)
+	}
+	return normalized, nil
 }
 
 func insufficientConnectionInfo() bool {
+	if connectionSettings.SocketPath != "" {
+		// Access to the socket is controlled by its file permissions.
+		return false
+	}
 	return connectionSettings.Port == "" || connectionSettings.User == "" || connectionSettings.Password == ""
 }
 
+// ParseSocketURL returns the path of a Unix domain socket given either as a `unix://` URL or as a plain path.
+func ParseSocketURL(socket string) (string, error) {
+	if strings.HasPrefix(socket, socketURLPrefix) {
+		socket = strings.TrimPrefix(socket, socketURLPrefix)
+	} else if strings.Contains(socket, "://") {
+		return "", fmt.Errorf("invalid socket %q: only %s URLs are supported", socket, socketURLPrefix)
+	}
+	if socket == "" {
+		return "", fmt.Errorf("invalid socket %q: no path given", socketURLPrefix)
+	}
+	return socket, nil
+}
+
 // determines if we are running in a wsl linux distro
 // by checking for availability of wslpath and see if it's a symlink
 func isWSLDistro() bool {
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSocketURL(t *testing.T) {
+	testCases := []struct {
+		socket   string
+		expected string
+		err      string
+	}{
+		{socket: "unix:///run/user/1000/rd-engine.sock", expected: "/run/user/1000/rd-engine.sock"},
+		{socket: "/tmp/rd-engine.sock", expected: "/tmp/rd-engine.sock"},
+		{socket: "unix://", err: "no path given"},
+		{socket: "tcp://127.0.0.1:6107", err: "only unix:// URLs are supported"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.socket, func(t *testing.T) {
+			socketPath, err := ParseSocketURL(testCase.socket)
+			if testCase.err != "" {
+				assert.ErrorContains(t, err, testCase.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expected, socketPath)
+			}
+		})
+	}
+}
+
+func TestNormalizeFingerprint(t *testing.T) {
+	expected := "5e8c8a0bf06e0de5e8a9f3a3d1c2d9a3b7f3e0a8f5b2c7d4e1f6a9b0c3d2e1f4"
+	for _, fingerprint := range []string{
+		expected,
+		"sha256:" + expected,
+		"5E:8C:8A:0B:F0:6E:0D:E5:E8:A9:F3:A3:D1:C2:D9:A3:B7:F3:E0:A8:F5:B2:C7:D4:E1:F6:A9:B0:C3:D2:E1:F4",
+	} {
+		normalized, err := NormalizeFingerprint(fingerprint)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, normalized)
+	}
+	_, err := NormalizeFingerprint("5e8c")
+	assert.ErrorContains(t, err, "expected 64 hex digits")
+	_, err = NormalizeFingerprint(expected[:62] + "zz")
+	assert.ErrorContains(t, err, "invalid SHA-256 fingerprint")
+}
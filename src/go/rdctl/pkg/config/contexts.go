@@ -0,0 +1,213 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	passwordRefEnvPrefix  = "env:"
+	passwordRefFilePrefix = "file:"
+)
+
+var (
+	// ContextsPath is the file holding the named connection contexts.
+	ContextsPath string
+	contextName  string
+
+	contextNamePattern = regexp.MustCompile(`^[A-Za-z0-9][-A-Za-z0-9_.]*$`)
+)
+
+// Context is a named set of connection settings, used in place of the global flags.
+// Passwords aren't stored; PasswordRef says where to read the password from instead.
+type Context struct {
+	Name             string `json:"name"`
+	Host             string `json:"host,omitempty"`
+	Port             string `json:"port,omitempty"`
+	User             string `json:"user,omitempty"`
+	PasswordRef      string `json:"passwordRef,omitempty"`
+	ConfigPath       string `json:"configPath,omitempty"`
+	CACert           string `json:"caCert,omitempty"`
+	ClientCert       string `json:"clientCert,omitempty"`
+	ClientKey        string `json:"clientKey,omitempty"`
+	PinnedCertSHA256 string `json:"pinnedCertSHA256,omitempty"`
+}
+
+// Contexts is the contents of the contexts file.
+type Contexts struct {
+	Current  string    `json:"current,omitempty"`
+	Contexts []Context `json:"contexts"`
+}
+
+// ReadContexts reads the contexts file; a missing file holds no contexts.
+func ReadContexts(path string) (*Contexts, error) {
+	contexts := &Contexts{Contexts: []Context{}}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return contexts, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read contexts file: %w", err)
+	}
+	if err := json.Unmarshal(content, contexts); err != nil {
+		return nil, fmt.Errorf("error in contexts file %q: %w", path, err)
+	}
+	return contexts, nil
+}
+
+// Write saves the contexts file, which is only readable by the user.
+func (contexts *Contexts) Write(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create contexts directory: %w", err)
+	}
+	content, err := json.MarshalIndent(contexts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write contexts file: %w", err)
+	}
+	return nil
+}
+
+// Find returns the named context, or nil if there is no such context.
+func (contexts *Contexts) Find(name string) *Context {
+	for i := range contexts.Contexts {
+		if contexts.Contexts[i].Name == name {
+			return &contexts.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// Add adds a new context, after checking its name and password reference.
+func (contexts *Contexts) Add(context Context) error {
+	if !contextNamePattern.MatchString(context.Name) {
+		return fmt.Errorf("invalid context name %q: must start with a letter or digit, and contain only letters, digits, '-', '_' and '.'", context.Name)
+	}
+	if contexts.Find(context.Name) != nil {
+		return fmt.Errorf("context %q already exists", context.Name)
+	}
+	if context.PasswordRef != "" {
+		if err := ValidatePasswordRef(context.PasswordRef); err != nil {
+			return err
+		}
+	}
+	contexts.Contexts = append(contexts.Contexts, context)
+	return nil
+}
+
+// Remove deletes the named context; if it was the current context, no context is current afterwards.
+func (contexts *Contexts) Remove(name string) error {
+	for i, context := range contexts.Contexts {
+		if context.Name == name {
+			contexts.Contexts = append(contexts.Contexts[:i], contexts.Contexts[i+1:]...)
+			if contexts.Current == name {
+				contexts.Current = ""
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("context %q not found", name)
+}
+
+// Use makes the named context current; an empty name means no context is current.
+func (contexts *Contexts) Use(name string) error {
+	if name != "" && contexts.Find(name) == nil {
+		return fmt.Errorf("context %q not found", name)
+	}
+	contexts.Current = name
+	return nil
+}
+
+// ValidatePasswordRef checks that a password reference has the form `env:NAME` or `file:PATH`.
+func ValidatePasswordRef(ref string) error {
+	for _, prefix := range []string{passwordRefEnvPrefix, passwordRefFilePrefix} {
+		if value, found := strings.CutPrefix(ref, prefix); found {
+			if value == "" {
+				return fmt.Errorf("invalid password reference %q: nothing after %q", ref, prefix)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid password reference %q: must be %sNAME or %sPATH", ref, passwordRefEnvPrefix, passwordRefFilePrefix)
+}
+
+// ResolvePasswordRef returns the password a reference points to: the value of an environment
+// variable, or the first line of a file.
+func ResolvePasswordRef(ref string) (string, error) {
+	if err := ValidatePasswordRef(ref); err != nil {
+		return "", err
+	}
+	if name, found := strings.CutPrefix(ref, passwordRefEnvPrefix); found {
+		password, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("password reference %q: environment variable %s is not set", ref, name)
+		}
+		return password, nil
+	}
+	path := strings.TrimPrefix(ref, passwordRefFilePrefix)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("password reference %q: %w", ref, err)
+	}
+	password, _, _ := strings.Cut(string(content), "\n")
+	return strings.TrimSuffix(password, "\r"), nil
+}
+
+// applyContext fills in the connection settings that weren't given as flags from the context
+// named by `--context`, or from the current context if there is one.
+func applyContext() error {
+	if ContextsPath == "" {
+		return nil
+	}
+	contexts, err := ReadContexts(ContextsPath)
+	if err != nil {
+		return err
+	}
+	name := contextName
+	if name == "" {
+		name = contexts.Current
+	}
+	if name == "" {
+		return nil
+	}
+	context := contexts.Find(name)
+	if context == nil {
+		return fmt.Errorf("context %q not found", name)
+	}
+	setIfEmpty(&connectionSettings.Host, context.Host)
+	setIfEmpty(&connectionSettings.Port, context.Port)
+	setIfEmpty(&connectionSettings.User, context.User)
+	setIfEmpty(&connectionSettings.CACert, context.CACert)
+	setIfEmpty(&connectionSettings.ClientCert, context.ClientCert)
+	setIfEmpty(&connectionSettings.ClientKey, context.ClientKey)
+	setIfEmpty(&connectionSettings.PinnedCertSHA256, context.PinnedCertSHA256)
+	setIfEmpty(&configPath, context.ConfigPath)
+	if connectionSettings.Password == "" && context.PasswordRef != "" {
+		if connectionSettings.Password, err = ResolvePasswordRef(context.PasswordRef); err != nil {
+			return fmt.Errorf("context %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rdctl", "contexts.json")
+	contexts, err := ReadContexts(path)
+	require.NoError(t, err)
+	assert.Empty(t, contexts.Contexts)
+
+	require.NoError(t, contexts.Add(Context{Name: "wsl", Host: "172.30.0.1", PasswordRef: "env:RD_PASSWORD"}))
+	require.NoError(t, contexts.Add(Context{Name: "test", ConfigPath: "/tmp/rd-engine.json"}))
+	assert.ErrorContains(t, contexts.Add(Context{Name: "wsl"}), `context "wsl" already exists`)
+	assert.ErrorContains(t, contexts.Add(Context{Name: "-x"}), "invalid context name")
+	assert.ErrorContains(t, contexts.Add(Context{Name: "other", PasswordRef: "hunter2"}), "invalid password reference")
+	require.NoError(t, contexts.Use("wsl"))
+	assert.ErrorContains(t, contexts.Use("missing"), `context "missing" not found`)
+	require.NoError(t, contexts.Write(path))
+
+	contexts, err = ReadContexts(path)
+	require.NoError(t, err)
+	assert.Equal(t, "wsl", contexts.Current)
+	require.Len(t, contexts.Contexts, 2)
+	assert.Equal(t, "172.30.0.1", contexts.Find("wsl").Host)
+	assert.Nil(t, contexts.Find("missing"))
+
+	require.NoError(t, contexts.Remove("wsl"))
+	assert.Equal(t, "", contexts.Current)
+	assert.ErrorContains(t, contexts.Remove("wsl"), `context "wsl" not found`)
+	assert.Equal(t, []Context{{Name: "test", ConfigPath: "/tmp/rd-engine.json"}}, contexts.Contexts)
+}
+
+func TestResolvePasswordRef(t *testing.T) {
+	t.Setenv("RDCTL_TEST_PASSWORD", "from-env")
+	password, err := ResolvePasswordRef("env:RDCTL_TEST_PASSWORD")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", password)
+
+	_, err = ResolvePasswordRef("env:RDCTL_TEST_PASSWORD_UNSET")
+	assert.ErrorContains(t, err, "environment variable RDCTL_TEST_PASSWORD_UNSET is not set")
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("from-file\r\nignored\n"), 0o600))
+	password, err = ResolvePasswordRef("file:" + passwordFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", password)
+
+	_, err = ResolvePasswordRef("file:")
+	assert.ErrorContains(t, err, "nothing after")
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// isPrivateSocket reports whether there is a Unix domain socket at the path that only the
+// current user can connect to, so that the credentials can be sent over it. The path itself
+// must be the socket, not a symbolic link to one.
+func isPrivateSocket(path string) bool {
+	if path == "" {
+		return false
+	}
+	fi, err := os.Lstat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return false
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || int(stat.Uid) != os.Getuid() {
+		return false
+	}
+	return fi.Mode().Perm()&0o022 == 0
+}
@@ -0,0 +1,60 @@
+//go:build unix
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPrivateSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "rdctl-socket")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "rd-engine.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	require.NoError(t, os.Chmod(socketPath, 0o600))
+	assert.True(t, isPrivateSocket(socketPath))
+
+	require.NoError(t, os.Chmod(socketPath, 0o750))
+	assert.True(t, isPrivateSocket(socketPath), "read and execute bits don't allow connecting")
+	require.NoError(t, os.Chmod(socketPath, 0o620))
+	assert.False(t, isPrivateSocket(socketPath), "group members could connect")
+	require.NoError(t, os.Chmod(socketPath, 0o602))
+	assert.False(t, isPrivateSocket(socketPath), "other users could connect")
+	require.NoError(t, os.Chmod(socketPath, 0o600))
+
+	linkPath := filepath.Join(dir, "link.sock")
+	require.NoError(t, os.Symlink(socketPath, linkPath))
+	assert.False(t, isPrivateSocket(linkPath))
+
+	filePath := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(filePath, nil, 0o600))
+	assert.False(t, isPrivateSocket(filePath))
+	assert.False(t, isPrivateSocket(filepath.Join(dir, "missing")))
+	assert.False(t, isPrivateSocket(""))
+}
@@ -0,0 +1,30 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import "os"
+
+// isPrivateSocket reports whether there is a Unix domain socket at the path. Unlike on
+// other platforms, its owner and mode aren't checked: the sockets are kept in the user's
+// profile, whose access control list already keeps other users out.
+func isPrivateSocket(path string) bool {
+	if path == "" {
+		return false
+	}
+	fi, err := os.Lstat(path)
+	return err == nil && fi.Mode()&os.ModeSocket != 0
+}
@@ -0,0 +1,85 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deploymentprofile reads the deployment profiles that administrators use to
+// supply default settings, and to lock settings so users can't change them.
+// It follows the same lookup rules as `readDeploymentProfiles` in the main process.
+package deploymentprofile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// Profile holds the contents of the defaults and locked deployment profiles.
+// Either document is nil if its profile doesn't exist.
+type Profile struct {
+	Defaults     map[string]interface{}
+	Locked       map[string]interface{}
+	DefaultsPath string
+	LockedPath   string
+}
+
+type profileLocation struct {
+	dir          string
+	defaultsName string
+	lockedName   string
+}
+
+// Read returns the deployment profile in effect. System-wide profiles take priority
+// over per-user profiles: if the system directory contains either a defaults or a
+// locked profile, the user directory isn't read.
+func Read(appPaths paths.Paths) (Profile, error) {
+	for _, location := range profileLocations(appPaths) {
+		if location.dir == "" {
+			continue
+		}
+		profile := Profile{
+			DefaultsPath: filepath.Join(location.dir, location.defaultsName),
+			LockedPath:   filepath.Join(location.dir, location.lockedName),
+		}
+		var err error
+		if profile.Defaults, err = readProfileFile(profile.DefaultsPath); err != nil {
+			return Profile{}, err
+		}
+		if profile.Locked, err = readProfileFile(profile.LockedPath); err != nil {
+			return Profile{}, err
+		}
+		if profile.Defaults != nil || profile.Locked != nil {
+			return profile, nil
+		}
+	}
+	return Profile{}, nil
+}
+
+// parseJSONProfile decodes a profile file's JSON contents; a missing file is not an error.
+func parseJSONProfile(path string, contents []byte, err error) (map[string]interface{}, error) {
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read deployment profile %q: %w", path, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing deployment profile %q: %w", path, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,33 @@
+package deploymentprofile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+func profileLocations(appPaths paths.Paths) []profileLocation {
+	return []profileLocation{
+		{appPaths.DeploymentProfileSystem, "io.rancherdesktop.profile.defaults.plist", "io.rancherdesktop.profile.locked.plist"},
+		{appPaths.DeploymentProfileUser, "io.rancherdesktop.profile.defaults.plist", "io.rancherdesktop.profile.locked.plist"},
+	}
+}
+
+// readProfileFile converts a plist profile to JSON with `plutil`, as the main process does.
+func readProfileFile(path string) (map[string]interface{}, error) {
+	if _, err := os.Stat(path); err != nil {
+		return parseJSONProfile(path, nil, err)
+	}
+	output, err := exec.Command("plutil", "-convert", "json", "-r", "-o", "-", path).Output()
+	if err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			return nil, fmt.Errorf("error loading plist file %q: %s", path, exitError.Stderr)
+		}
+		return nil, fmt.Errorf("error loading plist file %q: %w", path, err)
+	}
+	return parseJSONProfile(path, output, nil)
+}
@@ -0,0 +1,19 @@
+package deploymentprofile
+
+import (
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+func profileLocations(appPaths paths.Paths) []profileLocation {
+	return []profileLocation{
+		{appPaths.DeploymentProfileSystem, "defaults.json", "locked.json"},
+		{appPaths.DeploymentProfileUser, "rancher-desktop.defaults.json", "rancher-desktop.locked.json"},
+	}
+}
+
+func readProfileFile(path string) (map[string]interface{}, error) {
+	contents, err := os.ReadFile(path)
+	return parseJSONProfile(path, contents, err)
+}
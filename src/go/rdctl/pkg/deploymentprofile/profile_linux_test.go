@@ -0,0 +1,54 @@
+package deploymentprofile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	newPaths := func(t *testing.T) paths.Paths {
+		return paths.Paths{
+			DeploymentProfileSystem: t.TempDir(),
+			DeploymentProfileUser:   t.TempDir(),
+		}
+	}
+	writeFile := func(t *testing.T, dir, name, contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644))
+	}
+
+	t.Run("returns an empty profile when there are no files", func(t *testing.T) {
+		profile, err := Read(newPaths(t))
+		assert.NoError(t, err)
+		assert.Nil(t, profile.Defaults)
+		assert.Nil(t, profile.Locked)
+	})
+	t.Run("reads user profiles", func(t *testing.T) {
+		appPaths := newPaths(t)
+		writeFile(t, appPaths.DeploymentProfileUser, "rancher-desktop.defaults.json", `{"kubernetes": {"enabled": false}}`)
+		profile, err := Read(appPaths)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"kubernetes": map[string]interface{}{"enabled": false}}, profile.Defaults)
+		assert.Nil(t, profile.Locked)
+		assert.Equal(t, filepath.Join(appPaths.DeploymentProfileUser, "rancher-desktop.defaults.json"), profile.DefaultsPath)
+	})
+	t.Run("prefers system profiles", func(t *testing.T) {
+		appPaths := newPaths(t)
+		writeFile(t, appPaths.DeploymentProfileUser, "rancher-desktop.defaults.json", `{"kubernetes": {"enabled": false}}`)
+		writeFile(t, appPaths.DeploymentProfileSystem, "locked.json", `{"containerEngine": {"name": "moby"}}`)
+		profile, err := Read(appPaths)
+		assert.NoError(t, err)
+		assert.Nil(t, profile.Defaults)
+		assert.Equal(t, map[string]interface{}{"containerEngine": map[string]interface{}{"name": "moby"}}, profile.Locked)
+	})
+	t.Run("reports unparseable profiles", func(t *testing.T) {
+		appPaths := newPaths(t)
+		writeFile(t, appPaths.DeploymentProfileSystem, "defaults.json", `{"kubernetes":`)
+		_, err := Read(appPaths)
+		assert.ErrorContains(t, err, "error parsing deployment profile")
+	})
+}
@@ -0,0 +1,15 @@
+package deploymentprofile
+
+import (
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// On Windows, deployment profiles are stored in the registry rather than in files,
+// so there are no profile files to read.
+func profileLocations(appPaths paths.Paths) []profileLocation {
+	return nil
+}
+
+func readProfileFile(path string) (map[string]interface{}, error) {
+	return nil, nil
+}
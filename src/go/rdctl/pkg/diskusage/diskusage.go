@@ -0,0 +1,221 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package diskusage measures the disk space used by Rancher Desktop, by category, for
+// `rdctl du`, and clears the categories that can be cleared for `rdctl prune`. It also
+// reports the free disk space, for `rdctl doctor` and `rdctl vm compact`.
+package diskusage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// Categories of data, as named on the command line.
+const (
+	Snapshots  = "snapshots"
+	Cache      = "cache"
+	Logs       = "logs"
+	Extensions = "extensions"
+	Images     = "images"
+	Volumes    = "volumes"
+)
+
+// Category is a kind of data kept by Rancher Desktop.
+type Category struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Paths holds the files and directories on the host; it is empty for data in the VM.
+	Paths []string `json:"paths,omitempty"`
+	// Prunable is true for the categories `rdctl prune` can clear.
+	Prunable bool `json:"prunable"`
+}
+
+// InVM reports whether the data is in the VM, where it can only be measured while the VM is running.
+func (category Category) InVM() bool {
+	return len(category.Paths) == 0
+}
+
+// Categories returns the categories of data, those on the host first.
+func Categories(appPaths paths.Paths) []Category {
+	categories := diskCategories(appPaths)
+	return append(categories,
+		Category{Name: Snapshots, Description: "Snapshots of the VM", Paths: []string{appPaths.Snapshots}, Prunable: true},
+		Category{Name: Cache, Description: "Downloads, such as Kubernetes images", Paths: []string{appPaths.Cache}, Prunable: true},
+		Category{Name: Logs, Description: "Log files", Paths: []string{appPaths.Logs}, Prunable: true},
+		Category{Name: Extensions, Description: "Installed extensions", Paths: []string{appPaths.ExtensionRoot}},
+		Category{Name: Images, Description: "Container images and layers in the VM", Prunable: true},
+		Category{Name: Volumes, Description: "Container volumes in the VM", Prunable: true},
+	)
+}
+
+// Find returns the category with the name.
+func Find(categories []Category, name string) (Category, error) {
+	var names []string
+	for _, category := range categories {
+		if category.Name == name {
+			return category, nil
+		}
+		names = append(names, category.Name)
+	}
+	return Category{}, fmt.Errorf("unknown category %q; expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Size is the space taken by files.
+type Size struct {
+	// Apparent is the sum of the file sizes.
+	Apparent int64 `json:"apparent"`
+	// Allocated is the disk space allocated to the files, which is smaller than their
+	// apparent size for sparse files, such as VM disks.
+	Allocated int64 `json:"allocated"`
+}
+
+// Add returns the sum of the sizes.
+func (size Size) Add(other Size) Size {
+	return Size{Apparent: size.Apparent + other.Apparent, Allocated: size.Allocated + other.Allocated}
+}
+
+// Measure returns the space taken by a file, or by a directory and everything in it.
+// Symbolic links are not followed, and a missing path takes no space.
+func Measure(path string) (Size, error) {
+	var total Size
+	err := filepath.WalkDir(path, func(entryPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			total.Apparent += info.Size()
+		}
+		total.Allocated += allocatedSize(info)
+		return nil
+	})
+	return total, err
+}
+
+// MeasureCategory returns the space taken by the files of a category on the host.
+func MeasureCategory(category Category) (Size, error) {
+	var total Size
+	for _, path := range category.Paths {
+		size, err := Measure(path)
+		if err != nil {
+			return total, fmt.Errorf("failed to measure %s: %w", path, err)
+		}
+		total = total.Add(size)
+	}
+	return total, nil
+}
+
+// RemoveContents removes everything in a directory, but not the directory itself. It
+// carries on past files that can't be removed, e.g. logs still open on Windows, and
+// returns the errors.
+func RemoveContents(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var errs []error
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// VMMeasureScript is a shell script, run as root in the VM, that prints the space taken by
+// each category of data in the VM, as the name of the category and a number of KiB
+// separated by a tab. The directories are those of both dockerd and containerd.
+const VMMeasureScript = `measure() {
+  name="$1"
+  shift
+  total=0
+  for dir in "$@"; do
+    [ -d "$dir" ] || continue
+    size="$(du -sk "$dir" | cut -f1)"
+    total=$((total + size))
+  done
+  printf '%s\t%s\n' "$name" "$total"
+}
+measure ` + Images + ` /var/lib/docker/overlay2 /var/lib/docker/image /var/lib/containerd /var/lib/buildkit
+measure ` + Volumes + ` /var/lib/docker/volumes /var/lib/nerdctl
+`
+
+// ParseVMSizes reads the output of VMMeasureScript, returning the bytes allocated to each category.
+func ParseVMSizes(output string) (map[string]int64, error) {
+	result := map[string]int64{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		name, kib, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			return nil, fmt.Errorf("unexpected output %q", line)
+		}
+		size, err := strconv.ParseInt(kib, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected size in %q: %w", line, err)
+		}
+		result[name] = size * 1024
+	}
+	return result, nil
+}
+
+// VMPruneScript is a shell script, run as root in the VM, that removes the unused images
+// or volumes, as given by its arguments, with the CLI of the container engine in use.
+// With containerd, both the default namespace and the k8s.io namespace used by Kubernetes
+// are pruned.
+const VMPruneScript = `if command -v docker >/dev/null 2>&1 && docker info >/dev/null 2>&1; then
+  namespaces=""
+elif command -v nerdctl >/dev/null 2>&1; then
+  namespaces=default
+  if nerdctl namespace ls --quiet 2>/dev/null | grep -qx k8s.io; then
+    namespaces="$namespaces k8s.io"
+  fi
+else
+  echo "No container engine is running in the VM." >&2
+  exit 1
+fi
+cli() {
+  if [ -z "$namespaces" ]; then
+    docker "$@"
+  else
+    nerdctl --namespace "$namespace" "$@"
+  fi
+}
+for category in "$@"; do
+  for namespace in ${namespaces:-moby}; do
+    case "$category" in
+    ` + Images + `) cli image prune --all --force ;;
+    ` + Volumes + `) cli volume prune --all --force 2>/dev/null || cli volume prune --force ;;
+    esac
+  done
+done
+`
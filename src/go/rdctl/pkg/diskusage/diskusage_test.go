@@ -0,0 +1,132 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskusage
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), make([]byte, 1000), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b"), make([]byte, 24), 0o644))
+
+	size, err := Measure(dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), size.Apparent)
+	assert.Positive(t, size.Allocated)
+
+	size, err = Measure(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, Size{}, size)
+
+	if runtime.GOOS != "windows" {
+		// A sparse file takes less space than its size.
+		sparse, err := os.Create(filepath.Join(dir, "sparse"))
+		require.NoError(t, err)
+		require.NoError(t, sparse.Truncate(64<<20))
+		require.NoError(t, sparse.Close())
+		size, err = Measure(sparse.Name())
+		require.NoError(t, err)
+		assert.Equal(t, int64(64<<20), size.Apparent)
+		assert.Less(t, size.Allocated, size.Apparent)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	appPaths := paths.Paths{Snapshots: "/snapshots", Cache: "/cache", Logs: "/logs", ExtensionRoot: "/extensions", Lima: "/lima"}
+	categories := Categories(appPaths)
+	cache, err := Find(categories, Cache)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/cache"}, cache.Paths)
+	assert.True(t, cache.Prunable)
+	assert.False(t, cache.InVM())
+	images, err := Find(categories, Images)
+	require.NoError(t, err)
+	assert.True(t, images.InVM())
+	extensions, err := Find(categories, Extensions)
+	require.NoError(t, err)
+	assert.False(t, extensions.Prunable)
+	_, err = Find(categories, "bogus")
+	assert.ErrorContains(t, err, "expected one of")
+}
+
+func TestRemoveContents(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", "deeper"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0o644))
+	require.NoError(t, RemoveContents(dir))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.DirExists(t, dir)
+	assert.NoError(t, RemoveContents(filepath.Join(dir, "missing")))
+}
+
+func TestParseVMSizes(t *testing.T) {
+	sizes, err := ParseVMSizes("images\t2048\nvolumes\t0\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{Images: 2 << 20, Volumes: 0}, sizes)
+	_, err = ParseVMSizes("images 2048\n")
+	assert.Error(t, err)
+	_, err = ParseVMSizes("images\tlots\n")
+	assert.Error(t, err)
+}
+
+func TestVMScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scripts need a POSIX shell")
+	}
+	for _, script := range []string{VMMeasureScript, VMPruneScript} {
+		output, err := exec.Command("/bin/sh", "-n", "-c", script).CombinedOutput()
+		assert.NoError(t, err, string(output))
+	}
+}
+
+func TestVMPruneScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the scripts need a POSIX shell")
+	}
+	binDir := t.TempDir()
+	logPath := filepath.Join(binDir, "log")
+	// docker isn't running, so nerdctl is used; it logs its arguments.
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "docker"), []byte("#!/bin/sh\nexit 1\n"), 0o755))
+	nerdctl := `#!/bin/sh
+if [ "$1 $2" = "namespace ls" ]; then
+  printf 'buildkit\ndefault\nk8s.io\n'
+  exit 0
+fi
+echo "$*" >> "` + logPath + `"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "nerdctl"), []byte(nerdctl), 0o755))
+	cmd := exec.Command("/bin/sh", "-c", VMPruneScript, "rdctl-prune", Images)
+	cmd.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, "--namespace default image prune --all --force\n--namespace k8s.io image prune --all --force\n", string(log))
+}
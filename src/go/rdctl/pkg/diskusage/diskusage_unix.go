@@ -0,0 +1,42 @@
+//go:build !windows
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskusage
+
+import (
+	"io/fs"
+	"path/filepath"
+	"syscall"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+func diskCategories(appPaths paths.Paths) []Category {
+	return []Category{
+		{Name: "basedisk", Description: "Base disk image of the VM", Paths: []string{filepath.Join(appPaths.Lima, "0", "basedisk")}},
+		{Name: "diffdisk", Description: "Data disk of the VM", Paths: []string{filepath.Join(appPaths.Lima, "0", "diffdisk")}},
+	}
+}
+
+// allocatedSize returns the disk space allocated to a file, which st_blocks counts in 512-byte units.
+func allocatedSize(info fs.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return int64(stat.Blocks) * 512
+	}
+	return info.Size()
+}
@@ -0,0 +1,39 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskusage
+
+import (
+	"io/fs"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+func diskCategories(appPaths paths.Paths) []Category {
+	return []Category{
+		{Name: "distro", Description: "Disk of the WSL distribution", Paths: []string{appPaths.WslDistro}},
+		{Name: "distro-data", Description: "Disk of the WSL data distribution", Paths: []string{appPaths.WslDistroData}},
+	}
+}
+
+// allocatedSize returns the size of a file; the WSL disks are not sparse, so their
+// allocated size is the same.
+func allocatedSize(info fs.FileInfo) int64 {
+	if !info.Mode().IsRegular() {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,473 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package doctor checks the host for problems that stop Rancher Desktop from working,
+// without needing the app to be running, and fixes the ones that are safe to fix.
+package doctor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/factoryreset"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/output"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusWarning Status = "warning"
+	StatusError   Status = "error"
+	// StatusFixed is a problem that was found and then fixed.
+	StatusFixed Status = "fixed"
+)
+
+const (
+	// StaleLockAge is how old the backend lock has to be before it is probably left behind
+	// by an operation that was interrupted. Long operations can take longer, so it is never
+	// removed on that basis alone.
+	StaleLockAge = time.Hour
+	// DockerContextName is the docker context Rancher Desktop creates for the moby engine.
+	DockerContextName = "rancher-desktop"
+
+	gib = uint64(1) << 30
+	// MinFreeSpace and LowFreeSpace are the free disk space below which the VM
+	// (or a snapshot of it) can't be expected to work, or might soon stop working.
+	MinFreeSpace = 2 * gib
+	LowFreeSpace = 10 * gib
+)
+
+const pathManagementStart = "### MANAGED BY RANCHER DESKTOP START (DO NOT EDIT)"
+
+// Environment is what the checks look at.
+type Environment struct {
+	Paths paths.Paths
+	// HomeDir holds the shell profiles that PATH management is added to.
+	HomeDir string
+	// DockerConfigDir is the docker CLI configuration directory, usually ~/.docker.
+	DockerConfigDir string
+	// BackendLockPath is the file whose presence means a snapshot operation is in progress.
+	BackendLockPath string
+	// ConfigPath is the rd-engine.json file written by the app for rdctl.
+	ConfigPath string
+	// LimactlPath is the limactl bundled with the app. It is empty on Windows, where the
+	// docker context and shell profiles aren't managed by the app either, and aren't checked.
+	LimactlPath string
+	// FreeSpace returns the disk space available to the user on the file system holding the path.
+	FreeSpace func(path string) (uint64, error)
+	// Getenv returns the value of an environment variable.
+	Getenv func(string) string
+	Now    time.Time
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check      string `json:"check"`
+	Status     Status `json:"status"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+	// Fixable is set when --fix can fix the problem.
+	Fixable bool `json:"fixable"`
+	fix     func() error
+}
+
+// Check is one of the checks run by `rdctl doctor`.
+type Check struct {
+	Name        string
+	Description string
+	Run         func(env Environment) Result
+}
+
+// Checks are all the checks, in the order they are run.
+var Checks = []Check{
+	{"limactl", "the bundled limactl can be run", checkLimactl},
+	{"backend-lock", "no stale backend lock is left by an interrupted snapshot operation", checkBackendLock},
+	{"config-file", "the rd-engine.json file rdctl uses to connect to the app is valid", checkConfigFile},
+	{"disk-space", "there is enough free disk space for the VM and snapshots", checkDiskSpace},
+	{"docker-context", "the docker CLI uses the context of Rancher Desktop", checkDockerContext},
+	{"cli-plugins", "there are no broken docker CLI plugin links", checkCLIPlugins},
+	{"path-management", "shell profiles have no leftover PATH changes", checkPathManagement},
+}
+
+// Run runs all the checks. When fix is set, problems that can be fixed safely are fixed,
+// and reported with StatusFixed; if a fix fails, the problem is reported with the reason.
+func Run(env Environment, fix bool) []Result {
+	results := make([]Result, 0, len(Checks))
+	for _, check := range Checks {
+		result := check.Run(env)
+		result.Check = check.Name
+		if result.Status == "" {
+			result.Status = StatusOK
+		}
+		if fix && result.Fixable && result.fix != nil && result.Status != StatusOK {
+			if err := result.fix(); err != nil {
+				result.Message = fmt.Sprintf("%s (fix failed: %s)", result.Message, err)
+			} else {
+				result.Status = StatusFixed
+				result.Fixable = false
+				result.Suggestion = ""
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func ok(format string, args ...interface{}) Result {
+	return Result{Status: StatusOK, Message: fmt.Sprintf(format, args...)}
+}
+
+func checkLimactl(env Environment) Result {
+	if env.LimactlPath == "" {
+		return ok("limactl isn't used on this platform")
+	}
+	if _, err := os.Stat(env.LimactlPath); err != nil {
+		return Result{
+			Status:     StatusError,
+			Message:    fmt.Sprintf("limactl can't be found: %s", err),
+			Suggestion: "reinstall Rancher Desktop, and run the rdctl that comes with it",
+		}
+	}
+	output, err := exec.Command(env.LimactlPath, "--version").CombinedOutput()
+	if err != nil {
+		return Result{
+			Status:     StatusError,
+			Message:    fmt.Sprintf("%s --version failed: %s: %s", env.LimactlPath, err, strings.TrimSpace(string(output))),
+			Suggestion: "reinstall Rancher Desktop",
+		}
+	}
+	return ok("%s (%s)", env.LimactlPath, strings.TrimSpace(string(output)))
+}
+
+func checkBackendLock(env Environment) Result {
+	info, err := os.Stat(env.BackendLockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ok("the backend isn't locked")
+	} else if err != nil {
+		return Result{Status: StatusError, Message: fmt.Sprintf("failed to check the backend lock: %s", err)}
+	}
+	age := env.Now.Sub(info.ModTime()).Round(time.Second)
+	if age < StaleLockAge {
+		return Result{
+			Status:     StatusWarning,
+			Message:    fmt.Sprintf("the backend has been locked for %s, by a snapshot operation that may still be running", age),
+			Suggestion: "if no snapshot operation is running, remove the lock with 'rdctl snapshot unlock'",
+		}
+	}
+	return Result{
+		Status:     StatusError,
+		Message:    fmt.Sprintf("the backend has been locked for %s, probably by an interrupted snapshot operation; the app can't start the VM until it is removed", age),
+		Suggestion: "if no snapshot operation is running, remove the lock with 'rdctl snapshot unlock'",
+	}
+}
+
+func checkConfigFile(env Environment) Result {
+	contents, err := os.ReadFile(env.ConfigPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return ok("%s doesn't exist; it is written when Rancher Desktop starts", env.ConfigPath)
+	} else if err != nil {
+		return Result{Status: StatusError, Message: fmt.Sprintf("failed to read %s: %s", env.ConfigPath, err)}
+	}
+	restart := "quit and restart Rancher Desktop, which rewrites it"
+	var cliConfig config.CLIConfig
+	if err := json.Unmarshal(contents, &cliConfig); err != nil {
+		return Result{Status: StatusError, Message: fmt.Sprintf("%s isn't valid JSON: %s", env.ConfigPath, err), Suggestion: restart}
+	}
+	var problems []string
+	if cliConfig.User == "" || cliConfig.Password == "" {
+		problems = append(problems, "no user or password")
+	}
+	if (cliConfig.Port <= 0 || cliConfig.Port > 65535) && cliConfig.Socket == "" {
+		problems = append(problems, fmt.Sprintf("invalid port %d", cliConfig.Port))
+	}
+	if len(problems) > 0 {
+		return Result{Status: StatusError, Message: fmt.Sprintf("%s has %s", env.ConfigPath, strings.Join(problems, " and ")), Suggestion: restart}
+	}
+	if info, err := os.Stat(env.ConfigPath); err == nil && info.Mode().Perm()&0o077 != 0 && runtime.GOOS != "windows" {
+		mode := info.Mode().Perm()
+		return Result{
+			Status:     StatusWarning,
+			Message:    fmt.Sprintf("%s holds the API password, but can be read by other users (mode %04o)", env.ConfigPath, mode),
+			Suggestion: fmt.Sprintf("chmod 600 %s", env.ConfigPath),
+			Fixable:    true,
+			fix:        func() error { return os.Chmod(env.ConfigPath, 0o600) },
+		}
+	}
+	return ok("%s is valid", env.ConfigPath)
+}
+
+// existingParent returns the path, or its closest ancestor that exists.
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+func checkDiskSpace(env Environment) Result {
+	dataDir := env.Paths.Lima
+	if dataDir == "" {
+		dataDir = env.Paths.WslDistroData
+	}
+	if dataDir == "" {
+		dataDir = env.Paths.AppHome
+	}
+	var lowest uint64
+	lowestPath := ""
+	for _, dir := range []string{dataDir, env.Paths.Snapshots} {
+		if dir == "" {
+			continue
+		}
+		dir = existingParent(dir)
+		free, err := env.FreeSpace(dir)
+		if err != nil {
+			return Result{Status: StatusWarning, Message: fmt.Sprintf("failed to get the free disk space of %s: %s", dir, err)}
+		}
+		if lowestPath == "" || free < lowest {
+			lowest, lowestPath = free, dir
+		}
+	}
+	suggestion := "free up disk space, or delete snapshots you no longer need with 'rdctl snapshot delete'"
+	switch {
+	case lowestPath == "":
+		return ok("no data directory to check")
+	case lowest < MinFreeSpace:
+		return Result{Status: StatusError, Message: fmt.Sprintf("only %s is free for %s; the VM may fail to start or write data", output.FormatSize(int64(lowest)), lowestPath), Suggestion: suggestion}
+	case lowest < LowFreeSpace:
+		return Result{Status: StatusWarning, Message: fmt.Sprintf("only %s is free for %s, which may not be enough for snapshots", output.FormatSize(int64(lowest)), lowestPath), Suggestion: suggestion}
+	}
+	return ok("%s is free for %s", output.FormatSize(int64(lowest)), lowestPath)
+}
+
+// readDockerConfig returns the docker CLI configuration, or nil if there is none.
+func readDockerConfig(env Environment) (map[string]interface{}, error) {
+	contents, err := os.ReadFile(filepath.Join(env.DockerConfigDir, "config.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var dockerConfig map[string]interface{}
+	if err := json.Unmarshal(contents, &dockerConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(env.DockerConfigDir, "config.json"), err)
+	}
+	return dockerConfig, nil
+}
+
+// dockerContextExists reports whether the docker CLI has a context with the name;
+// contexts are stored in directories named after the SHA-256 digest of their names.
+func dockerContextExists(env Environment, name string) bool {
+	digest := sha256.Sum256([]byte(name))
+	_, err := os.Stat(filepath.Join(env.DockerConfigDir, "contexts", "meta", hex.EncodeToString(digest[:]), "meta.json"))
+	return err == nil
+}
+
+// readAppSettings returns the app's settings file, or nil if there is none.
+func readAppSettings(env Environment) map[string]interface{} {
+	contents, err := os.ReadFile(filepath.Join(env.Paths.Config, "settings.json"))
+	if err != nil {
+		return nil
+	}
+	var appSettings map[string]interface{}
+	if err := json.Unmarshal(contents, &appSettings); err != nil {
+		return nil
+	}
+	return appSettings
+}
+
+func checkDockerContext(env Environment) Result {
+	if env.LimactlPath == "" {
+		return ok("the docker context isn't managed on this platform")
+	}
+	dockerConfig, err := readDockerConfig(env)
+	if err != nil {
+		return Result{Status: StatusWarning, Message: err.Error()}
+	}
+	current, _ := dockerConfig["currentContext"].(string)
+	if envContext := env.Getenv("DOCKER_CONTEXT"); envContext != "" {
+		current = envContext
+	}
+	if current == "" {
+		current = "default"
+	}
+	if current == DockerContextName && !dockerContextExists(env, DockerContextName) {
+		configPath := filepath.Join(env.DockerConfigDir, "config.json")
+		return Result{
+			Status:     StatusError,
+			Message:    fmt.Sprintf("the current docker context is %q, but it doesn't exist, so docker commands fail", DockerContextName),
+			Suggestion: "run 'docker context use default', or start Rancher Desktop with the moby engine",
+			Fixable:    env.Getenv("DOCKER_CONTEXT") == "",
+			fix: func() error {
+				delete(dockerConfig, "currentContext")
+				contents, err := json.MarshalIndent(dockerConfig, "", "  ")
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(configPath, contents, 0o600)
+			},
+		}
+	}
+	if engine, err := settings.Get(readAppSettings(env), "containerEngine.name"); err != nil || engine != "moby" {
+		return ok("the current docker context is %q", current)
+	}
+	if dockerHost := env.Getenv("DOCKER_HOST"); dockerHost != "" {
+		return Result{
+			Status:     StatusWarning,
+			Message:    fmt.Sprintf("DOCKER_HOST is set to %s, so the docker CLI doesn't use Rancher Desktop", dockerHost),
+			Suggestion: "unset DOCKER_HOST",
+		}
+	}
+	if current != DockerContextName && dockerContextExists(env, DockerContextName) {
+		return Result{
+			Status:     StatusWarning,
+			Message:    fmt.Sprintf("the current docker context is %q, so the docker CLI doesn't use Rancher Desktop", current),
+			Suggestion: fmt.Sprintf("run 'docker context use %s'", DockerContextName),
+		}
+	}
+	return ok("the current docker context is %q", current)
+}
+
+// checkCLIPlugins looks for broken docker CLI plugin links. Only the links into the
+// Rancher Desktop installation or ~/.rd are removed by --fix; the others were made by
+// something else, and are only reported.
+func checkCLIPlugins(env Environment) Result {
+	pluginsDir := filepath.Join(env.DockerConfigDir, "cli-plugins")
+	entries, err := os.ReadDir(pluginsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return ok("there are no docker CLI plugins")
+	} else if err != nil {
+		return Result{Status: StatusWarning, Message: fmt.Sprintf("failed to read %s: %s", pluginsDir, err)}
+	}
+	var owned, others []string
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		pluginPath := filepath.Join(pluginsDir, entry.Name())
+		if _, err := os.Stat(pluginPath); err == nil {
+			continue
+		}
+		target, err := os.Readlink(pluginPath)
+		if err == nil && !filepath.IsAbs(target) {
+			target = filepath.Join(pluginsDir, target)
+		}
+		if err == nil && (isWithin(env.Paths.Resources, target) || isWithin(env.Paths.AltAppHome, target)) {
+			owned = append(owned, pluginPath)
+		} else {
+			others = append(others, pluginPath)
+		}
+	}
+	if len(owned) == 0 && len(others) == 0 {
+		return ok("all docker CLI plugin links in %s are valid", pluginsDir)
+	}
+	if len(owned) == 0 {
+		return Result{
+			Status:     StatusWarning,
+			Message:    fmt.Sprintf("broken docker CLI plugin links not created by Rancher Desktop: %s", strings.Join(others, ", ")),
+			Suggestion: "reinstall the plugins, or remove the links",
+		}
+	}
+	message := fmt.Sprintf("broken docker CLI plugin links: %s", strings.Join(owned, ", "))
+	if len(others) > 0 {
+		message += fmt.Sprintf("; broken links not created by Rancher Desktop, which are left alone: %s", strings.Join(others, ", "))
+	}
+	return Result{
+		Status:     StatusError,
+		Message:    message,
+		Suggestion: "remove the broken links; Rancher Desktop recreates the ones it needs when it starts",
+		Fixable:    true,
+		fix: func() error {
+			var errs []error
+			for _, pluginPath := range owned {
+				errs = append(errs, os.Remove(pluginPath))
+			}
+			return errors.Join(errs...)
+		},
+	}
+}
+
+// isWithin reports whether the path is the directory or inside it.
+func isWithin(dir, path string) bool {
+	if dir == "" {
+		return false
+	}
+	relPath, err := filepath.Rel(dir, path)
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+var pathManagementStartPattern = regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(pathManagementStart))
+
+func checkPathManagement(env Environment) Result {
+	if env.LimactlPath == "" {
+		return ok("PATH management isn't used on this platform")
+	}
+	strategy, _ := settings.Get(readAppSettings(env), "application.pathManagementStrategy")
+	var managed, duplicated []string
+	for _, profile := range factoryreset.ShellProfiles(env.HomeDir) {
+		contents, err := os.ReadFile(profile)
+		if err != nil {
+			continue
+		}
+		switch count := len(pathManagementStartPattern.FindAllIndex(contents, -1)); {
+		case count > 1:
+			duplicated = append(duplicated, profile)
+		case count == 1:
+			managed = append(managed, profile)
+		}
+	}
+	if len(duplicated) > 0 {
+		return Result{
+			Status:     StatusWarning,
+			Message:    fmt.Sprintf("more than one block of PATH changes by Rancher Desktop in %s", strings.Join(duplicated, ", ")),
+			Suggestion: "remove all but one of the blocks",
+		}
+	}
+	if len(managed) == 0 || strategy == "rcfiles" {
+		return ok("shell profiles have no leftover PATH changes")
+	}
+	reason := "PATH management is set to manual"
+	if strategy == nil {
+		reason = "Rancher Desktop has no settings (it may have been reset or uninstalled)"
+	}
+	return Result{
+		Status:     StatusWarning,
+		Message:    fmt.Sprintf("%s, but PATH changes were left in %s", reason, strings.Join(managed, ", ")),
+		Suggestion: "remove the blocks marked as managed by Rancher Desktop",
+		Fixable:    true,
+		fix:        func() error { return factoryreset.RemovePathManagement(managed) },
+	}
+}
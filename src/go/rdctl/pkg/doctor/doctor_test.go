@@ -0,0 +1,300 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEnvironment(t *testing.T) Environment {
+	root := t.TempDir()
+	appHome := filepath.Join(root, "appHome")
+	require.NoError(t, os.MkdirAll(appHome, 0o755))
+	return Environment{
+		Paths: paths.Paths{
+			AppHome:    appHome,
+			AltAppHome: filepath.Join(root, "home", ".rd"),
+			Config:     filepath.Join(root, "config"),
+			Lima:       filepath.Join(appHome, "lima"),
+			Snapshots:  filepath.Join(appHome, "snapshots"),
+		},
+		HomeDir:         filepath.Join(root, "home"),
+		DockerConfigDir: filepath.Join(root, "docker"),
+		BackendLockPath: filepath.Join(appHome, "backend.lock"),
+		ConfigPath:      filepath.Join(appHome, "rd-engine.json"),
+		LimactlPath:     filepath.Join(root, "limactl"),
+		FreeSpace:       func(string) (uint64, error) { return 100 * gib, nil },
+		Getenv:          func(string) string { return "" },
+		Now:             time.Now(),
+	}
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func TestRunFixesOnlyWhenAsked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the config file permissions aren't checked on Windows")
+	}
+	env := newEnvironment(t)
+	writeFile(t, env.ConfigPath, `{"user": "user", "password": "password", "port": 6107}`)
+	require.NoError(t, os.Chmod(env.ConfigPath, 0o644))
+
+	result := resultFor(t, Run(env, false), "config-file")
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.True(t, result.Fixable)
+	info, err := os.Stat(env.ConfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	result = resultFor(t, Run(env, true), "config-file")
+	assert.Equal(t, StatusFixed, result.Status)
+	assert.Equal(t, StatusOK, resultFor(t, Run(env, false), "config-file").Status)
+}
+
+func resultFor(t *testing.T, results []Result, check string) Result {
+	for _, result := range results {
+		if result.Check == check {
+			return result
+		}
+	}
+	require.Failf(t, "missing result", "no result for check %s", check)
+	return Result{}
+}
+
+func TestCheckBackendLock(t *testing.T) {
+	env := newEnvironment(t)
+	assert.Equal(t, StatusOK, checkBackendLock(env).Status)
+
+	writeFile(t, env.BackendLockPath, "")
+	result := checkBackendLock(env)
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.False(t, result.Fixable, "a lock that may be in use must not be removed")
+	assert.Contains(t, result.Suggestion, "rdctl snapshot unlock")
+
+	// Even an old lock may be held by a long snapshot operation.
+	old := env.Now.Add(-2 * StaleLockAge)
+	require.NoError(t, os.Chtimes(env.BackendLockPath, old, old))
+	result = resultFor(t, Run(env, true), "backend-lock")
+	assert.Equal(t, StatusError, result.Status)
+	assert.False(t, result.Fixable)
+	assert.Contains(t, result.Suggestion, "rdctl snapshot unlock")
+	assert.FileExists(t, env.BackendLockPath)
+}
+
+func TestCheckLimactl(t *testing.T) {
+	env := newEnvironment(t)
+	assert.Equal(t, StatusError, checkLimactl(env).Status)
+
+	env.LimactlPath = ""
+	assert.Equal(t, StatusOK, checkLimactl(env).Status)
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	env.LimactlPath = filepath.Join(t.TempDir(), "limactl")
+	writeFile(t, env.LimactlPath, "#!/bin/sh\necho limactl version 0.17.2\n")
+	require.NoError(t, os.Chmod(env.LimactlPath, 0o755))
+	result := checkLimactl(env)
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Contains(t, result.Message, "limactl version 0.17.2")
+
+	writeFile(t, env.LimactlPath, "#!/bin/sh\nexit 1\n")
+	assert.Equal(t, StatusError, checkLimactl(env).Status)
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		status   Status
+	}{
+		{"missing", "", StatusOK},
+		{"valid", `{"user": "user", "password": "password", "port": 6107}`, StatusOK},
+		{"invalid JSON", `{"user": `, StatusError},
+		{"no password", `{"user": "user", "port": 6107}`, StatusError},
+		{"bad port", `{"user": "user", "password": "password", "port": 0}`, StatusError},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			env := newEnvironment(t)
+			if testCase.contents != "" {
+				writeFile(t, env.ConfigPath, testCase.contents)
+			}
+			result := checkConfigFile(env)
+			assert.Equal(t, testCase.status, result.Status, result.Message)
+			assert.False(t, result.Fixable)
+		})
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	t.Run("readable by others", func(t *testing.T) {
+		env := newEnvironment(t)
+		writeFile(t, env.ConfigPath, `{"user": "user", "password": "password", "port": 6107}`)
+		require.NoError(t, os.Chmod(env.ConfigPath, 0o644))
+		result := resultFor(t, Run(env, true), "config-file")
+		assert.Equal(t, StatusFixed, result.Status)
+		info, err := os.Stat(env.ConfigPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	env := newEnvironment(t)
+	var checked []string
+	free := map[string]uint64{}
+	env.FreeSpace = func(path string) (uint64, error) {
+		checked = append(checked, path)
+		return free[path], nil
+	}
+	free[env.Paths.AppHome] = 100 * gib
+	assert.Equal(t, StatusOK, checkDiskSpace(env).Status)
+	// Neither the lima nor the snapshots directories exist yet, so their parent is checked.
+	assert.Equal(t, []string{env.Paths.AppHome, env.Paths.AppHome}, checked)
+
+	require.NoError(t, os.MkdirAll(env.Paths.Snapshots, 0o755))
+	free[env.Paths.Snapshots] = 5 * gib
+	result := checkDiskSpace(env)
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.Contains(t, result.Message, env.Paths.Snapshots)
+
+	free[env.Paths.Snapshots] = gib
+	assert.Equal(t, StatusError, checkDiskSpace(env).Status)
+}
+
+const rancherDesktopContextDigest = "b547d66a5de60e5f0843aba28283a8875c2ad72e99ba076060ef9ec7c09917c8"
+
+func TestCheckDockerContext(t *testing.T) {
+	setup := func(t *testing.T, currentContext string, contextExists bool) Environment {
+		env := newEnvironment(t)
+		writeFile(t, filepath.Join(env.Paths.Config, "settings.json"), `{"containerEngine": {"name": "moby"}}`)
+		writeFile(t, filepath.Join(env.DockerConfigDir, "config.json"), `{"currentContext": "`+currentContext+`", "auths": {}}`)
+		if contextExists {
+			writeFile(t, filepath.Join(env.DockerConfigDir, "contexts", "meta", rancherDesktopContextDigest, "meta.json"), "{}")
+		}
+		return env
+	}
+
+	t.Run("in use", func(t *testing.T) {
+		assert.Equal(t, StatusOK, checkDockerContext(setup(t, DockerContextName, true)).Status)
+	})
+	t.Run("other context", func(t *testing.T) {
+		result := checkDockerContext(setup(t, "colima", true))
+		assert.Equal(t, StatusWarning, result.Status)
+		assert.Contains(t, result.Suggestion, "docker context use rancher-desktop")
+	})
+	t.Run("DOCKER_HOST", func(t *testing.T) {
+		env := setup(t, DockerContextName, true)
+		env.Getenv = func(name string) string {
+			if name == "DOCKER_HOST" {
+				return "tcp://localhost:2375"
+			}
+			return ""
+		}
+		assert.Equal(t, StatusWarning, checkDockerContext(env).Status)
+	})
+	t.Run("missing context", func(t *testing.T) {
+		env := setup(t, DockerContextName, false)
+		result := resultFor(t, Run(env, true), "docker-context")
+		assert.Equal(t, StatusFixed, result.Status)
+		contents, err := os.ReadFile(filepath.Join(env.DockerConfigDir, "config.json"))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"auths": {}}`, string(contents))
+	})
+}
+
+func TestCheckCLIPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	env := newEnvironment(t)
+	assert.Equal(t, StatusOK, checkCLIPlugins(env).Status)
+
+	pluginsDir := filepath.Join(env.DockerConfigDir, "cli-plugins")
+	target := filepath.Join(t.TempDir(), "docker-buildx")
+	writeFile(t, target, "")
+	require.NoError(t, os.MkdirAll(pluginsDir, 0o755))
+	require.NoError(t, os.Symlink(target, filepath.Join(pluginsDir, "docker-buildx")))
+	require.NoError(t, os.Symlink(filepath.Join(env.Paths.AltAppHome, "bin", "docker-compose"), filepath.Join(pluginsDir, "docker-compose")))
+	require.NoError(t, os.Symlink(filepath.Join(t.TempDir(), "missing"), filepath.Join(pluginsDir, "docker-scan")))
+	result := checkCLIPlugins(env)
+	assert.Equal(t, StatusError, result.Status)
+	assert.True(t, result.Fixable)
+	assert.Contains(t, result.Message, "docker-compose")
+	assert.Contains(t, result.Message, "docker-scan")
+	assert.NotContains(t, result.Message, "docker-buildx")
+
+	result = resultFor(t, Run(env, true), "cli-plugins")
+	assert.Equal(t, StatusFixed, result.Status)
+	assert.FileExists(t, filepath.Join(pluginsDir, "docker-buildx"))
+	_, err := os.Lstat(filepath.Join(pluginsDir, "docker-compose"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Lstat(filepath.Join(pluginsDir, "docker-scan"))
+	assert.NoError(t, err, "links not created by Rancher Desktop must be left alone")
+
+	result = resultFor(t, Run(env, true), "cli-plugins")
+	assert.Equal(t, StatusWarning, result.Status)
+	assert.False(t, result.Fixable)
+	assert.Contains(t, result.Message, "docker-scan")
+	_, err = os.Lstat(filepath.Join(pluginsDir, "docker-scan"))
+	assert.NoError(t, err)
+}
+
+func TestCheckPathManagement(t *testing.T) {
+	block := pathManagementStart + "\nexport PATH=\"$HOME/.rd/bin:$PATH\"\n### MANAGED BY RANCHER DESKTOP END (DO NOT EDIT)\n"
+
+	t.Run("rcfiles", func(t *testing.T) {
+		env := newEnvironment(t)
+		writeFile(t, filepath.Join(env.Paths.Config, "settings.json"), `{"application": {"pathManagementStrategy": "rcfiles"}}`)
+		writeFile(t, filepath.Join(env.HomeDir, ".bashrc"), "# bashrc\n"+block)
+		assert.Equal(t, StatusOK, checkPathManagement(env).Status)
+	})
+	t.Run("leftover", func(t *testing.T) {
+		env := newEnvironment(t)
+		writeFile(t, filepath.Join(env.Paths.Config, "settings.json"), `{"application": {"pathManagementStrategy": "manual"}}`)
+		bashrc := filepath.Join(env.HomeDir, ".bashrc")
+		writeFile(t, bashrc, "# bashrc\n"+block)
+		result := checkPathManagement(env)
+		assert.Equal(t, StatusWarning, result.Status)
+		assert.Contains(t, result.Message, bashrc)
+
+		result = resultFor(t, Run(env, true), "path-management")
+		assert.Equal(t, StatusFixed, result.Status)
+		contents, err := os.ReadFile(bashrc)
+		require.NoError(t, err)
+		assert.Equal(t, "# bashrc\n", string(contents))
+	})
+	t.Run("duplicated", func(t *testing.T) {
+		env := newEnvironment(t)
+		writeFile(t, filepath.Join(env.HomeDir, ".zshrc"), block+block)
+		result := checkPathManagement(env)
+		assert.Equal(t, StatusWarning, result.Status)
+		assert.False(t, result.Fixable)
+	})
+}
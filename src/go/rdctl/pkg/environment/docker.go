@@ -0,0 +1,41 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// CurrentDockerContext returns the name of the Docker CLI context in use.
+func CurrentDockerContext() (string, error) {
+	output, err := exec.Command("docker", "context", "show").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the current docker context: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// UseDockerContext makes the Docker CLI use the named context.
+func UseDockerContext(name string) error {
+	output, err := exec.Command("docker", "context", "use", name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to use docker context %q: %w: %s", name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
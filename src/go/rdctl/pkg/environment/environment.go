@@ -0,0 +1,143 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package environment describes a developer's whole Rancher Desktop setup in one file,
+// as used by `rdctl apply`: settings, extensions, autostart, the Docker context and
+// a baseline snapshot.
+package environment
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment is the contents of an environment file. Fields that are left out are not changed.
+type Environment struct {
+	// Settings is a full or partial settings document.
+	Settings map[string]interface{} `json:"settings,omitempty" yaml:"settings,omitempty"`
+	// Extensions lists the extensions that must be installed, as image references;
+	// an extension without a tag may be installed at any version.
+	Extensions []string `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	// Autostart is whether Rancher Desktop starts when the user logs in.
+	Autostart *bool `json:"autostart,omitempty" yaml:"autostart,omitempty"`
+	// DockerContext is the name of the Docker CLI context to use.
+	DockerContext string `json:"dockerContext,omitempty" yaml:"dockerContext,omitempty"`
+	// Snapshot is the baseline snapshot, which is created if there is no snapshot of that name.
+	Snapshot *Snapshot `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+}
+
+// Snapshot names the baseline snapshot.
+type Snapshot struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Parse decodes an environment file in YAML or JSON format, and checks its settings
+// against the settings schema.
+func Parse(filename string, contents []byte) (*Environment, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	// Round-trip through JSON so that the settings have the same types as those decoded from JSON.
+	jsonBuffer, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to JSON: %w", filename, err)
+	}
+	var env Environment
+	decoder := json.NewDecoder(bytes.NewReader(jsonBuffer))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&env); err != nil {
+		return nil, fmt.Errorf("%s is not a valid environment file: %w", filename, err)
+	}
+	if env.Snapshot != nil && env.Snapshot.Name == "" {
+		return nil, fmt.Errorf("%s: the snapshot must have a name", filename)
+	}
+	for _, extension := range env.Extensions {
+		if extension == "" {
+			return nil, fmt.Errorf("%s: extension references must not be empty", filename)
+		}
+	}
+	if env.Settings != nil {
+		schema, err := settings.PreferencesSchema()
+		if err != nil {
+			return nil, err
+		}
+		if validationErrors := schema.Validate(env.Settings); len(validationErrors) > 0 {
+			messages := make([]string, len(validationErrors))
+			for i, validationError := range validationErrors {
+				messages[i] = "settings." + validationError.Error()
+			}
+			return nil, fmt.Errorf("%s: invalid settings:\n  %s", filename, strings.Join(messages, "\n  "))
+		}
+		delete(env.Settings, "version")
+	}
+	return &env, nil
+}
+
+// Marshal encodes the environment as YAML.
+func (env *Environment) Marshal() ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(env); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// SplitExtension splits an extension image reference into the extension ID and its tag,
+// which is empty if the reference has none.
+func SplitExtension(reference string) (string, string) {
+	lastSlash := strings.LastIndex(reference, "/")
+	if colon := strings.LastIndex(reference, ":"); colon > lastSlash {
+		return reference[:colon], reference[colon+1:]
+	}
+	return reference, ""
+}
+
+// ExtensionChanges works out which extensions to install, given the installed extensions
+// as a map of IDs to versions; an installed extension with a different tag is installed again
+// at the wanted one. With prune, the installed extensions that aren't wanted are uninstalled.
+func ExtensionChanges(installed map[string]string, wanted []string, prune bool) (install []string, uninstall []string) {
+	wantedIDs := map[string]bool{}
+	for _, reference := range wanted {
+		id, tag := SplitExtension(reference)
+		wantedIDs[id] = true
+		version, ok := installed[id]
+		if !ok || (tag != "" && tag != version) {
+			install = append(install, reference)
+		}
+	}
+	if prune {
+		for id := range installed {
+			if !wantedIDs[id] {
+				uninstall = append(uninstall, id)
+			}
+		}
+		sort.Strings(uninstall)
+	}
+	return install, uninstall
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package environment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	contents := `
+settings:
+  version: 10
+  kubernetes:
+    enabled: false
+extensions:
+- docker/logs-explorer-extension:0.2.2
+autostart: true
+dockerContext: rancher-desktop
+snapshot:
+  name: baseline
+`
+	env, err := Parse("rancher-desktop.yaml", []byte(contents))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"kubernetes": map[string]interface{}{"enabled": false}}, env.Settings)
+	assert.Equal(t, []string{"docker/logs-explorer-extension:0.2.2"}, env.Extensions)
+	require.NotNil(t, env.Autostart)
+	assert.True(t, *env.Autostart)
+	assert.Equal(t, "rancher-desktop", env.DockerContext)
+	assert.Equal(t, &Snapshot{Name: "baseline"}, env.Snapshot)
+
+	exported, err := env.Marshal()
+	require.NoError(t, err)
+	roundTripped, err := Parse("exported.yaml", exported)
+	require.NoError(t, err)
+	assert.Equal(t, env, roundTripped)
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := map[string]string{
+		"unknownField: 1":                      "not a valid environment file",
+		"settings: {kubernetes: {enabled: 3}}": "settings.kubernetes.enabled: expected a boolean",
+		"snapshot: {description: no name}":     "the snapshot must have a name",
+		"extensions: ['']":                     "must not be empty",
+		"autostart: [":                         "failed to parse",
+	}
+	for contents, expected := range testCases {
+		_, err := Parse("env.yaml", []byte(contents))
+		assert.ErrorContains(t, err, expected, contents)
+	}
+}
+
+func TestSplitExtension(t *testing.T) {
+	testCases := map[string][2]string{
+		"docker/logs-explorer-extension:0.2.2": {"docker/logs-explorer-extension", "0.2.2"},
+		"docker/logs-explorer-extension":       {"docker/logs-explorer-extension", ""},
+		"registry:5000/ext":                    {"registry:5000/ext", ""},
+		"registry:5000/ext:1":                  {"registry:5000/ext", "1"},
+	}
+	for reference, expected := range testCases {
+		id, tag := SplitExtension(reference)
+		assert.Equal(t, expected, [2]string{id, tag}, reference)
+	}
+}
+
+func TestExtensionChanges(t *testing.T) {
+	installed := map[string]string{"a": "1", "b": "2", "c": "3"}
+	wanted := []string{"a", "b:3", "d:1"}
+
+	install, uninstall := ExtensionChanges(installed, wanted, false)
+	assert.Equal(t, []string{"b:3", "d:1"}, install)
+	assert.Empty(t, uninstall)
+
+	install, uninstall = ExtensionChanges(installed, wanted, true)
+	assert.Equal(t, []string{"b:3", "d:1"}, install)
+	assert.Equal(t, []string{"c"}, uninstall)
+}
@@ -82,6 +82,11 @@ func deleteUnixLikeData(paths p.Paths, pathList []string) error {
 		logrus.Errorf("Error trying to get home dir: %s", err)
 		return nil
 	}
+	return RemovePathManagement(ShellProfiles(homeDir))
+}
+
+// ShellProfiles returns the shell startup files that Rancher Desktop may add PATH management to.
+func ShellProfiles(homeDir string) []string {
 	rawPaths := []string{
 		".bashrc",
 		".bash_profile",
@@ -94,9 +99,7 @@ func deleteUnixLikeData(paths p.Paths, pathList []string) error {
 	for i, s := range rawPaths {
 		rawPaths[i] = path.Join(homeDir, s)
 	}
-	rawPaths = append(rawPaths, path.Join(homeDir, ".config", "fish", "config.fish"))
-
-	return removePathManagement(rawPaths)
+	return append(rawPaths, path.Join(homeDir, ".config", "fish", "config.fish"))
 }
 
 func deleteLimaVM() error {
@@ -146,7 +149,9 @@ func removeDockerCliPlugins(altAppHomePath string) error {
 	return nil
 }
 
-func removePathManagement(dotFiles []string) error {
+// RemovePathManagement removes the block of lines managed by Rancher Desktop from each of the files,
+// deleting any file that is left empty.
+func RemovePathManagement(dotFiles []string) error {
 	const startTarget = `### MANAGED BY RANCHER DESKTOP START \(DO NOT EDIT\)`
 	const endTarget = `### MANAGED BY RANCHER DESKTOP END \(DO NOT EDIT\)`
 
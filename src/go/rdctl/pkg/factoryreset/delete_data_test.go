@@ -32,7 +32,7 @@ import (
  * Copy all the dotfiles we care about into TMP/rd-dotfiles-copies and TMP/rd-dotfiles-working
  * Add fake management blocks to each file in TMP/rd-dotfiles-working
  * One of the files deliberately has no newline between its last line and the management block
- * Then call the removePathManagement() function on the working dot files
+ * Then call the RemovePathManagement() function on the working dot files
  * Then verify they're identical to the copied files
  * Clean up the temp dirs if everything matched
  */
@@ -191,7 +191,7 @@ func verifyMgmtRemoved(t *testing.T, dotFile string) {
 
 func TestRemoveManagedBlock(t *testing.T) {
 	modifiedFileList := append(filenames, path.Join(tempWorkingDotfilesDir, ".no-such-file"))
-	assert.NoError(t, removePathManagement(modifiedFileList))
+	assert.NoError(t, RemovePathManagement(modifiedFileList))
 	for _, dotFile := range filenames {
 		verifyMgmtRemoved(t, dotFile)
 	}
@@ -0,0 +1,661 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakeserver is an in-process stand-in for the Rancher Desktop command API server,
+// for testing rdctl without a running app. It keeps settings, locked and transient settings,
+// the backend state, extensions and snapshots in memory, accepts the same basic
+// authentication as the real server, and answers with the same status codes and messages.
+// Any endpoint can be replaced, or added, with [Server.Handle].
+package fakeserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+)
+
+const (
+	DefaultUser     = "user"
+	DefaultPassword = "password"
+	// AboutText is the response to `GET /v1/about`.
+	AboutText = "The API is currently at version 1, but is still considered internal and experimental, and is subject to change without any advance notice."
+	// ReconfiguringMessage is the response to a settings update that restarts the backend.
+	ReconfiguringMessage = "reconfiguring Rancher Desktop to apply changes (this may take a while)"
+)
+
+// restartSettings are the top-level settings whose changes make the backend restart.
+var restartSettings = []string{"containerEngine", "experimental", "kubernetes", "virtualMachine", "WSL"}
+
+// Request records a request the server received, after authentication.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   []byte
+}
+
+// Server is a fake command API server listening on a local port.
+type Server struct {
+	// User and Password are the credentials the server accepts.
+	User     string
+	Password string
+	// TransitionPolls is how many times `GET /v1/backend_state` reports STARTING or STOPPING
+	// after a state change is requested, before reporting the requested state.
+	TransitionPolls int
+
+	server            *httptest.Server
+	mutex             sync.Mutex
+	settings          map[string]interface{}
+	lockedSettings    map[string]interface{}
+	transientSettings map[string]interface{}
+	backendState      client.BackendState
+	// targetState is the VM state the backend is moving to, if it is starting or stopping.
+	targetState  string
+	pendingPolls int
+	snapshots    []snapshot.Snapshot
+	handlers     map[string]http.HandlerFunc
+	requests     []Request
+}
+
+// NewServer starts a fake server with the default settings and a running backend.
+// The server must be closed with [Server.Close].
+func NewServer() (*Server, error) {
+	defaultSettings, err := settings.DefaultSettings()
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		User:            DefaultUser,
+		Password:        DefaultPassword,
+		TransitionPolls: 1,
+		settings:        defaultSettings,
+		lockedSettings:  map[string]interface{}{},
+		transientSettings: map[string]interface{}{
+			"noModalDialogs": false,
+			"preferences": map[string]interface{}{
+				"navItem": map[string]interface{}{"current": "Application", "currentTabs": map[string]interface{}{}},
+			},
+		},
+		backendState: client.BackendState{VMState: "STARTED"},
+		handlers:     map[string]http.HandlerFunc{},
+	}
+	s.server = httptest.NewServer(s)
+	return s, nil
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL returns the base URL of the server, such as `http://127.0.0.1:12345`.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// ConnectionInfo returns the parameters rdctl needs to connect to the server.
+func (s *Server) ConnectionInfo() *config.ConnectionInfo {
+	serverURL, _ := url.Parse(s.server.URL)
+	return &config.ConnectionInfo{
+		User:     s.User,
+		Password: s.Password,
+		Host:     serverURL.Hostname(),
+		Port:     serverURL.Port(),
+	}
+}
+
+// WriteConfig writes an `rd-engine.json` file for the server to the given path,
+// as the app does when its server starts.
+func (s *Server) WriteConfig(path string) error {
+	connectionInfo := s.ConnectionInfo()
+	var port int
+	if _, err := fmt.Sscan(connectionInfo.Port, &port); err != nil {
+		return fmt.Errorf("failed to parse port %q: %w", connectionInfo.Port, err)
+	}
+	contents, err := json.Marshal(config.CLIConfig{User: s.User, Password: s.Password, Port: port})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0o600)
+}
+
+// Handle replaces the server's handler for the method and path, such as `PUT` and `/v1/settings`,
+// or adds a new endpoint. Requests are still authenticated and recorded before reaching it.
+func (s *Server) Handle(method, path string, handler http.HandlerFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[method+" "+path] = handler
+}
+
+// Requests returns the requests the server has received, oldest first.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Settings returns a copy of the current settings.
+func (s *Server) Settings() map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return settings.DeepCopy(s.settings)
+}
+
+// SetSettings replaces the current settings.
+func (s *Server) SetSettings(doc map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.settings = settings.DeepCopy(doc)
+}
+
+// SetLockedSettings replaces the locked settings, in the form returned by `GET /v1/settings/locked`.
+func (s *Server) SetLockedSettings(doc map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lockedSettings = settings.DeepCopy(doc)
+}
+
+// TransientSettings returns a copy of the current transient settings.
+func (s *Server) TransientSettings() map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return settings.DeepCopy(s.transientSettings)
+}
+
+// BackendState returns the current backend state, without advancing any transition.
+func (s *Server) BackendState() client.BackendState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.backendState
+}
+
+// SetBackendState sets the backend state immediately, cancelling any transition.
+func (s *Server) SetBackendState(state client.BackendState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.backendState = state
+	s.targetState = ""
+}
+
+// Extensions returns the installed extensions, mapping each ID to its tag.
+func (s *Server) Extensions() map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := map[string]string{}
+	for id, tag := range s.installedExtensions() {
+		result[id] = fmt.Sprintf("%v", tag)
+	}
+	return result
+}
+
+// Snapshots returns the snapshots, in the order they were created.
+func (s *Server) Snapshots() []snapshot.Snapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]snapshot.Snapshot{}, s.snapshots...)
+}
+
+// AddSnapshot adds a snapshot, as if it had been created earlier.
+func (s *Server) AddSnapshot(snap snapshot.Snapshot) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.snapshots = append(s.snapshots, snap)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, password, ok := r.BasicAuth(); !ok || user != s.User || password != s.Password {
+		writeText(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeText(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Like the real server, ignore a trailing slash.
+	path := r.URL.Path
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	s.mutex.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path, Query: r.URL.Query(), Body: body})
+	handler, ok := s.handlers[r.Method+" "+path]
+	s.mutex.Unlock()
+	if ok {
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		handler(w, r)
+		return
+	}
+	route, ok := s.routes()[r.Method+" "+path]
+	if !ok {
+		writeText(w, http.StatusNotFound, fmt.Sprintf("Unknown command: %s %s", r.Method, path))
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	route(w, r.URL.Query(), body)
+}
+
+type routeFunc func(w http.ResponseWriter, query url.Values, body []byte)
+
+func (s *Server) routes() map[string]routeFunc {
+	return map[string]routeFunc{
+		"GET /":                         s.listEndpoints,
+		"GET /v1":                       s.listEndpoints,
+		"GET /v1/about":                 s.about,
+		"GET /v1/settings":              s.getSettings,
+		"PUT /v1/settings":              s.updateSettings,
+		"GET /v1/settings/locked":       s.getLockedSettings,
+		"PUT /v1/propose_settings":      s.proposeSettings,
+		"GET /v1/transient_settings":    s.getTransientSettings,
+		"PUT /v1/transient_settings":    s.updateTransientSettings,
+		"GET /v1/backend_state":         s.getBackendState,
+		"PUT /v1/backend_state":         s.updateBackendState,
+		"PUT /v1/shutdown":              s.shutdown,
+		"PUT /v1/factory_reset":         s.factoryReset,
+		"GET /v1/extensions":            s.listExtensions,
+		"POST /v1/extensions/install":   s.installExtension,
+		"POST /v1/extensions/uninstall": s.uninstallExtension,
+		"GET /v1/snapshots":             s.listSnapshots,
+		"POST /v1/snapshots":            s.createSnapshot,
+		"DELETE /v1/snapshots":          s.deleteSnapshot,
+		"POST /v1/snapshot/restore":     s.restoreSnapshot,
+	}
+}
+
+func writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, text)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		writeText(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(contents)
+}
+
+// listEndpoints lists the built-in and added endpoints, with GET first for each path.
+func (s *Server) listEndpoints(w http.ResponseWriter, _ url.Values, _ []byte) {
+	endpoints := []string{}
+	for endpoint := range s.routes() {
+		endpoints = append(endpoints, endpoint)
+	}
+	for endpoint := range s.handlers {
+		if _, ok := s.routes()[endpoint]; !ok {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		methodI, pathI, _ := strings.Cut(endpoints[i], " ")
+		methodJ, pathJ, _ := strings.Cut(endpoints[j], " ")
+		if pathI != pathJ {
+			return pathI < pathJ
+		}
+		if (methodI == "GET") != (methodJ == "GET") {
+			return methodI == "GET"
+		}
+		return methodI < methodJ
+	})
+	writeJSON(w, http.StatusOK, endpoints)
+}
+
+func (s *Server) about(w http.ResponseWriter, _ url.Values, _ []byte) {
+	writeText(w, http.StatusOK, AboutText)
+}
+
+func (s *Server) getSettings(w http.ResponseWriter, _ url.Values, _ []byte) {
+	writeJSON(w, http.StatusOK, s.settings)
+}
+
+func (s *Server) getLockedSettings(w http.ResponseWriter, _ url.Values, _ []byte) {
+	writeJSON(w, http.StatusOK, s.lockedSettings)
+}
+
+// validateSettings checks a settings update the way the app does, returning the error messages
+// and the settings that would change.
+func (s *Server) validateSettings(changes map[string]interface{}) ([]string, map[string]interface{}) {
+	var errors []string
+	if version, ok := changes["version"]; !ok {
+		errors = append(errors, fmt.Sprintf("updating settings requires specifying version = %v, but no version was specified", s.settings["version"]))
+	} else if !settings.Equal(version, s.settings["version"]) {
+		errors = append(errors, fmt.Sprintf("updating settings requires specifying version = %v, but received version %v", s.settings["version"], version))
+	}
+	schema, err := settings.PreferencesSchema()
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+	for _, validationError := range schema.Validate(changes) {
+		errors = append(errors, validationError.Error())
+	}
+	delete(changes, "version")
+	changed := map[string]interface{}{}
+	current := settings.Flatten("", s.settings)
+	for name, value := range settings.Flatten("", changes) {
+		if settings.Equal(current[name], value) {
+			continue
+		}
+		if settings.IsLocked(s.lockedSettings, name) {
+			errors = append(errors, fmt.Sprintf("field %q is locked", name))
+		}
+		changed[name] = value
+	}
+	sort.Strings(errors)
+	return errors, changed
+}
+
+// needsRestart reports whether any of the changed settings makes the backend restart.
+func needsRestart(changed map[string]interface{}) bool {
+	for name := range changed {
+		for _, prefix := range restartSettings {
+			if strings.HasPrefix(name, prefix+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Server) updateSettings(w http.ResponseWriter, _ url.Values, body []byte) {
+	var changes map[string]interface{}
+	if err := json.Unmarshal(body, &changes); err != nil {
+		writeText(w, http.StatusBadRequest, fmt.Sprintf("error processing JSON request block\n%s\n%s", body, err))
+		return
+	}
+	errors, changed := s.validateSettings(changes)
+	if len(errors) > 0 {
+		writeText(w, http.StatusBadRequest, "errors in attempt to update settings:\n"+strings.Join(errors, "\n"))
+		return
+	}
+	if len(changed) == 0 {
+		writeText(w, http.StatusAccepted, "no changes necessary")
+		return
+	}
+	s.settings = settings.Merge(s.settings, changes)
+	if !needsRestart(changed) {
+		writeText(w, http.StatusAccepted, "settings updated; no restart required")
+		return
+	}
+	if s.backendState.VMState == "STARTED" {
+		s.startTransition("STARTED")
+	}
+	writeText(w, http.StatusAccepted, ReconfiguringMessage)
+}
+
+// proposeSettings reports each changed setting that would restart the backend.
+func (s *Server) proposeSettings(w http.ResponseWriter, _ url.Values, body []byte) {
+	var changes map[string]interface{}
+	if err := json.Unmarshal(body, &changes); err != nil {
+		writeText(w, http.StatusBadRequest, fmt.Sprintf("error processing JSON request block\n%s\n%s", body, err))
+		return
+	}
+	errors, changed := s.validateSettings(changes)
+	if len(errors) > 0 {
+		writeText(w, http.StatusBadRequest, "Errors in proposed settings:\n"+strings.Join(errors, "\n"))
+		return
+	}
+	current := settings.Flatten("", s.settings)
+	reasons := map[string]interface{}{}
+	for name, value := range changed {
+		if needsRestart(map[string]interface{}{name: value}) {
+			reasons[name] = map[string]interface{}{"current": current[name], "desired": value, "severity": "restart"}
+		}
+	}
+	writeJSON(w, http.StatusOK, reasons)
+}
+
+func (s *Server) getTransientSettings(w http.ResponseWriter, _ url.Values, _ []byte) {
+	writeJSON(w, http.StatusOK, s.transientSettings)
+}
+
+func (s *Server) updateTransientSettings(w http.ResponseWriter, _ url.Values, body []byte) {
+	var changes map[string]interface{}
+	if err := json.Unmarshal(body, &changes); err != nil {
+		writeText(w, http.StatusBadRequest, fmt.Sprintf("error processing JSON request block\n%s\n%s", body, err))
+		return
+	}
+	schema, err := settings.TransientSettingsSchema()
+	if err != nil {
+		writeText(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if validationErrors := schema.Validate(changes); len(validationErrors) > 0 {
+		messages := make([]string, len(validationErrors))
+		for i, validationError := range validationErrors {
+			messages[i] = validationError.Error()
+		}
+		writeText(w, http.StatusBadRequest, "errors in attempt to update Transient Settings:\n"+strings.Join(messages, "\n"))
+		return
+	}
+	s.transientSettings = settings.Merge(s.transientSettings, changes)
+	writeText(w, http.StatusAccepted, "")
+}
+
+// startTransition moves the backend towards the target state, through STARTING or STOPPING.
+func (s *Server) startTransition(target string) {
+	s.targetState = target
+	s.pendingPolls = s.TransitionPolls
+	if target == "STARTED" {
+		s.backendState.VMState = "STARTING"
+	} else {
+		s.backendState.VMState = "STOPPING"
+	}
+	if s.pendingPolls <= 0 {
+		s.backendState.VMState = target
+		s.targetState = ""
+	}
+}
+
+func (s *Server) getBackendState(w http.ResponseWriter, _ url.Values, _ []byte) {
+	state := s.backendState
+	if s.targetState != "" {
+		if s.pendingPolls--; s.pendingPolls <= 0 {
+			s.backendState.VMState = s.targetState
+			s.targetState = ""
+		}
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *Server) updateBackendState(w http.ResponseWriter, _ url.Values, body []byte) {
+	var state struct {
+		VMState *string `json:"vmState"`
+		Locked  *bool   `json:"locked"`
+	}
+	if err := json.Unmarshal(body, &state); err != nil {
+		writeText(w, http.StatusInternalServerError, fmt.Sprintf("internal error: %s", err))
+		return
+	}
+	if state.VMState != nil {
+		switch *state.VMState {
+		case "STARTED", "STOPPED":
+			if *state.VMState != s.backendState.VMState || s.targetState != "" {
+				s.startTransition(*state.VMState)
+			}
+		default:
+			writeText(w, http.StatusInternalServerError, fmt.Sprintf("internal error: Error: Invalid VM state %q", *state.VMState))
+			return
+		}
+	}
+	if state.Locked != nil {
+		s.backendState.Locked = *state.Locked
+	}
+	writeText(w, http.StatusAccepted, "received backend state")
+}
+
+func (s *Server) shutdown(w http.ResponseWriter, _ url.Values, _ []byte) {
+	s.backendState.VMState = "STOPPED"
+	s.targetState = ""
+	writeText(w, http.StatusAccepted, "Shutting down.")
+}
+
+func (s *Server) factoryReset(w http.ResponseWriter, _ url.Values, _ []byte) {
+	s.backendState.VMState = "STOPPED"
+	s.targetState = ""
+	writeText(w, http.StatusAccepted, "Doing a full factory reset....")
+}
+
+// installedExtensions returns the `application.extensions.installed` setting, which the
+// extension endpoints keep up to date, as the app does.
+func (s *Server) installedExtensions() map[string]interface{} {
+	installed, err := settings.Get(s.settings, "application.extensions.installed")
+	if installedMap, ok := installed.(map[string]interface{}); err == nil && ok {
+		return installedMap
+	}
+	installedMap := map[string]interface{}{}
+	_ = settings.Set(s.settings, "application.extensions.installed", installedMap)
+	return installedMap
+}
+
+// splitExtensionID splits an extension reference into its ID and tag, which defaults to `latest`.
+func splitExtensionID(ref string) (string, string) {
+	lastSlash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > lastSlash {
+		return ref[:colon], ref[colon+1:]
+	}
+	return ref, "latest"
+}
+
+func (s *Server) listExtensions(w http.ResponseWriter, _ url.Values, _ []byte) {
+	extensions := map[string]interface{}{}
+	for id, tag := range s.installedExtensions() {
+		extensions[id] = map[string]interface{}{"version": tag}
+	}
+	writeJSON(w, http.StatusOK, extensions)
+}
+
+// extensionID returns the `id` query parameter, writing an error response if it is missing.
+func extensionID(w http.ResponseWriter, query url.Values) (string, bool) {
+	id := query.Get("id")
+	if id == "" {
+		writeText(w, http.StatusBadRequest, "Extension ID is required in the id= parameter.")
+		return "", false
+	}
+	return id, true
+}
+
+func (s *Server) installExtension(w http.ResponseWriter, query url.Values, _ []byte) {
+	ref, ok := extensionID(w, query)
+	if !ok {
+		return
+	}
+	id, tag := splitExtensionID(ref)
+	installed := s.installedExtensions()
+	if installed[id] == tag {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	installed[id] = tag
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) uninstallExtension(w http.ResponseWriter, query url.Values, _ []byte) {
+	ref, ok := extensionID(w, query)
+	if !ok {
+		return
+	}
+	id, _ := splitExtensionID(ref)
+	installed := s.installedExtensions()
+	if _, ok := installed[id]; !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	delete(installed, id)
+	writeText(w, http.StatusCreated, fmt.Sprintf("Deleted %s", ref))
+}
+
+func (s *Server) listSnapshots(w http.ResponseWriter, _ url.Values, _ []byte) {
+	writeJSON(w, http.StatusOK, append([]snapshot.Snapshot{}, s.snapshots...))
+}
+
+// findSnapshot returns the index of the named snapshot, or -1 if there is none.
+func (s *Server) findSnapshot(name string) int {
+	for i, snap := range s.snapshots {
+		if snap.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) createSnapshot(w http.ResponseWriter, _ url.Values, body []byte) {
+	var snap snapshot.Snapshot
+	if err := json.Unmarshal(body, &snap); err != nil {
+		writeText(w, http.StatusBadRequest, "The snapshot is invalid")
+		return
+	}
+	if snap.Name == "" {
+		writeText(w, http.StatusBadRequest, "The name field is required")
+		return
+	}
+	if s.findSnapshot(snap.Name) >= 0 {
+		writeText(w, http.StatusBadRequest, fmt.Sprintf("name %q already exists", snap.Name))
+		return
+	}
+	snap.Created = time.Now()
+	s.snapshots = append(s.snapshots, snap)
+	writeText(w, http.StatusOK, "Snapshot successfully created")
+}
+
+// snapshotIndex returns the index of the snapshot named in the `name` query parameter,
+// writing an error response if there isn't one.
+func (s *Server) snapshotIndex(w http.ResponseWriter, query url.Values) (int, bool) {
+	name := query.Get("name")
+	if name == "" {
+		writeText(w, http.StatusBadRequest, "Snapshot name is required in query parameters")
+		return 0, false
+	}
+	index := s.findSnapshot(name)
+	if index < 0 {
+		writeText(w, http.StatusBadRequest, fmt.Sprintf("can't find snapshot %q", name))
+		return 0, false
+	}
+	return index, true
+}
+
+func (s *Server) deleteSnapshot(w http.ResponseWriter, query url.Values, _ []byte) {
+	index, ok := s.snapshotIndex(w, query)
+	if !ok {
+		return
+	}
+	s.snapshots = append(s.snapshots[:index], s.snapshots[index+1:]...)
+	writeText(w, http.StatusOK, "Snapshot successfully deleted")
+}
+
+func (s *Server) restoreSnapshot(w http.ResponseWriter, query url.Values, _ []byte) {
+	if _, ok := s.snapshotIndex(w, query); !ok {
+		return
+	}
+	writeText(w, http.StatusOK, "Snapshot successfully restored")
+}
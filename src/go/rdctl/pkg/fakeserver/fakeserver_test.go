@@ -0,0 +1,213 @@
+package fakeserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) (*Server, *client.RDClientImpl) {
+	server, err := NewServer()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	return server, client.NewRDClient(server.ConnectionInfo())
+}
+
+func request(t *testing.T, rdClient client.RDClient, method, command, payload string) (int, string) {
+	var response *http.Response
+	var err error
+	if payload == "" {
+		response, err = rdClient.DoRequest(method, client.VersionCommand("", command))
+	} else {
+		response, err = rdClient.DoRequestWithPayload(method, client.VersionCommand("", command), bytes.NewBufferString(payload))
+	}
+	require.NoError(t, err)
+	defer response.Body.Close()
+	var body bytes.Buffer
+	_, err = body.ReadFrom(response.Body)
+	require.NoError(t, err)
+	return response.StatusCode, body.String()
+}
+
+func TestAuthentication(t *testing.T) {
+	server, _ := newTestServer(t)
+	connectionInfo := server.ConnectionInfo()
+	connectionInfo.Password = "wrong"
+	status, body := request(t, client.NewRDClient(connectionInfo), "GET", "settings", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, "Unauthorized", body)
+	assert.Empty(t, server.Requests())
+}
+
+func TestUnknownCommand(t *testing.T) {
+	_, rdClient := newTestServer(t)
+	status, body := request(t, rdClient, "GET", "no_such_thing", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "Unknown command: GET /v1/no_such_thing", body)
+}
+
+func TestSettings(t *testing.T) {
+	t.Run("updates settings and restarts the backend", func(t *testing.T) {
+		server, rdClient := newTestServer(t)
+		status, body := request(t, rdClient, "PUT", "settings", `{"version": 10, "kubernetes": {"enabled": false}}`)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.Equal(t, ReconfiguringMessage, body)
+		assert.Equal(t, false, server.Settings()["kubernetes"].(map[string]interface{})["enabled"])
+		assert.Equal(t, "STARTING", server.BackendState().VMState)
+	})
+	t.Run("doesn't restart for application settings", func(t *testing.T) {
+		server, rdClient := newTestServer(t)
+		status, body := request(t, rdClient, "PUT", "settings", `{"version": 10, "application": {"debug": true}}`)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.Equal(t, "settings updated; no restart required", body)
+		assert.Equal(t, "STARTED", server.BackendState().VMState)
+	})
+	t.Run("reports unchanged settings", func(t *testing.T) {
+		_, rdClient := newTestServer(t)
+		status, body := request(t, rdClient, "PUT", "settings", `{"version": 10, "kubernetes": {"enabled": true}}`)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.Equal(t, "no changes necessary", body)
+	})
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		_, rdClient := newTestServer(t)
+		status, body := request(t, rdClient, "PUT", "settings", `{"kubernetes": {"enabled": "yes"}}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "errors in attempt to update settings:\n"+
+			"kubernetes.enabled: expected a boolean, got \"yes\"\n"+
+			"updating settings requires specifying version = 10, but no version was specified", body)
+	})
+	t.Run("rejects changes to locked settings", func(t *testing.T) {
+		server, rdClient := newTestServer(t)
+		server.SetLockedSettings(map[string]interface{}{"containerEngine": map[string]interface{}{"allowedImages": true}})
+		status, body := request(t, rdClient, "PUT", "settings", `{"version": 10, "containerEngine": {"allowedImages": {"enabled": true}}}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "errors in attempt to update settings:\nfield \"containerEngine.allowedImages.enabled\" is locked", body)
+		status, body = request(t, rdClient, "GET", "settings/locked", "")
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"containerEngine": {"allowedImages": true}}`, body)
+	})
+	t.Run("proposes settings", func(t *testing.T) {
+		_, rdClient := newTestServer(t)
+		status, body := request(t, rdClient, "PUT", "propose_settings", `{"version": 10, "kubernetes": {"enabled": false}, "application": {"debug": true}}`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, `{"kubernetes.enabled": {"current": true, "desired": false, "severity": "restart"}}`, body)
+	})
+	t.Run("updates transient settings", func(t *testing.T) {
+		server, rdClient := newTestServer(t)
+		status, _ := request(t, rdClient, "PUT", "transient_settings", `{"noModalDialogs": true}`)
+		assert.Equal(t, http.StatusAccepted, status)
+		assert.Equal(t, true, server.TransientSettings()["noModalDialogs"])
+		status, _ = request(t, rdClient, "PUT", "transient_settings", `{"noModalDialogs": 1}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+func TestBackendState(t *testing.T) {
+	server, rdClient := newTestServer(t)
+	server.TransitionPolls = 2
+	require.NoError(t, rdClient.UpdateBackendState(client.BackendState{VMState: "STOPPED", Locked: true}))
+	var states []string
+	for i := 0; i < 4; i++ {
+		state, err := rdClient.GetBackendState()
+		require.NoError(t, err)
+		assert.True(t, state.Locked)
+		states = append(states, state.VMState)
+	}
+	assert.Equal(t, []string{"STOPPING", "STOPPING", "STOPPED", "STOPPED"}, states)
+
+	status, body := request(t, rdClient, "PUT", "backend_state", `{"vmState": "PAUSED"}`)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, body, `Invalid VM state "PAUSED"`)
+}
+
+func TestExtensions(t *testing.T) {
+	server, rdClient := newTestServer(t)
+	status, _ := request(t, rdClient, "POST", "extensions/install?id=docker/logs-explorer-extension:0.2.2", "")
+	assert.Equal(t, http.StatusCreated, status)
+	status, _ = request(t, rdClient, "POST", "extensions/install?id=docker/logs-explorer-extension:0.2.2", "")
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, map[string]string{"docker/logs-explorer-extension": "0.2.2"}, server.Extensions())
+
+	status, body := request(t, rdClient, "GET", "extensions", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"docker/logs-explorer-extension": {"version": "0.2.2"}}`, body)
+
+	status, body = request(t, rdClient, "POST", "extensions/uninstall?id=docker/logs-explorer-extension", "")
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "Deleted docker/logs-explorer-extension", body)
+	assert.Empty(t, server.Extensions())
+
+	status, body = request(t, rdClient, "POST", "extensions/install", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "Extension ID is required in the id= parameter.", body)
+}
+
+func TestSnapshots(t *testing.T) {
+	server, rdClient := newTestServer(t)
+	server.AddSnapshot(snapshot.Snapshot{Name: "existing"})
+	status, body := request(t, rdClient, "POST", "snapshots", `{"name": "new", "description": "a snapshot"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Snapshot successfully created", body)
+	status, _ = request(t, rdClient, "POST", "snapshots", `{"name": "new"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, body = request(t, rdClient, "GET", "snapshots", "")
+	assert.Equal(t, http.StatusOK, status)
+	var snapshots []snapshot.Snapshot
+	require.NoError(t, json.Unmarshal([]byte(body), &snapshots))
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "a snapshot", snapshots[1].Description)
+
+	status, _ = request(t, rdClient, "POST", "snapshot/restore?name=existing", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = request(t, rdClient, "DELETE", "snapshots?name=existing", "")
+	assert.Equal(t, http.StatusOK, status)
+	status, body = request(t, rdClient, "DELETE", "snapshots?name=existing", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, `can't find snapshot "existing"`, body)
+	assert.Len(t, server.Snapshots(), 1)
+}
+
+func TestHandle(t *testing.T) {
+	server, rdClient := newTestServer(t)
+	server.Handle("GET", "/v1/settings", func(w http.ResponseWriter, r *http.Request) {
+		writeText(w, http.StatusServiceUnavailable, "busy")
+	})
+	status, body := request(t, rdClient, "GET", "settings", "")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "busy", body)
+
+	status, body = request(t, rdClient, "GET", "/", "")
+	assert.Equal(t, http.StatusOK, status)
+	var endpoints []string
+	require.NoError(t, json.Unmarshal([]byte(body), &endpoints))
+	assert.Equal(t, "GET /", endpoints[0])
+	assert.Subset(t, endpoints, []string{"GET /v1/settings", "PUT /v1/settings"})
+
+	requests := server.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/v1/settings", requests[0].Path)
+}
+
+func TestWriteConfig(t *testing.T) {
+	server, _ := newTestServer(t)
+	path := filepath.Join(t.TempDir(), "rd-engine.json")
+	require.NoError(t, server.WriteConfig(path))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var cliConfig config.CLIConfig
+	require.NoError(t, json.Unmarshal(contents, &cliConfig))
+	assert.Equal(t, DefaultUser, cliConfig.User)
+	assert.Equal(t, DefaultPassword, cliConfig.Password)
+	assert.Equal(t, server.ConnectionInfo().Port, fmt.Sprint(cliConfig.Port))
+}
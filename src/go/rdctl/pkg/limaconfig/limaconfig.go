@@ -0,0 +1,426 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package limaconfig reads and updates the Lima override.yaml file, whose settings Lima
+// applies on top of the configuration Rancher Desktop generates for the VM.
+// The file is edited as a tree of YAML nodes, so that comments in it are kept.
+package limaconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+	"gopkg.in/yaml.v3"
+)
+
+// OverridePath returns the path of override.yaml in the Lima home directory.
+func OverridePath(limaHome string) string {
+	return filepath.Join(limaHome, "_config", "override.yaml")
+}
+
+// Config is the contents of an override.yaml file.
+type Config struct {
+	doc yaml.Node
+}
+
+// Load reads an override.yaml file; a missing file is the same as an empty one.
+func Load(path string) (*Config, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	config, err := Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Parse decodes the contents of an override.yaml file.
+func Parse(contents []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(contents, &config.doc); err != nil {
+		return nil, err
+	}
+	if len(config.doc.Content) == 0 {
+		config.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if config.root().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the document must be a mapping of settings, not a %s", describeNode(config.root()))
+	}
+	return config, nil
+}
+
+func (config *Config) root() *yaml.Node {
+	return config.doc.Content[0]
+}
+
+// Bytes encodes the document as YAML.
+func (config *Config) Bytes() ([]byte, error) {
+	if len(config.root().Content) == 0 {
+		return []byte{}, nil
+	}
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&config.doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Save writes the document to path, creating its directory if needed.
+func (config *Config) Save(path string) error {
+	contents, err := config.Bytes()
+	if err != nil {
+		return err
+	}
+	return WriteFile(path, contents)
+}
+
+// WriteFile replaces the file at path with the contents, creating its directory if needed.
+// The contents are written to a temporary file first, so Lima never sees a partial file.
+func WriteFile(path string, contents []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".override.yaml-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(contents)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// Value returns the whole document as a decoded JSON value, as used for validation.
+func (config *Config) Value() (map[string]interface{}, error) {
+	value, err := decodeNode(config.root())
+	if err != nil {
+		return nil, err
+	}
+	return value.(map[string]interface{}), nil
+}
+
+// decodeNode converts a node into the types produced by json.Unmarshal.
+func decodeNode(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	jsonBuffer, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := json.Unmarshal(jsonBuffer, &result); err != nil {
+		return nil, err
+	}
+	if result == nil && node.Kind == yaml.MappingNode {
+		result = map[string]interface{}{}
+	}
+	return result, nil
+}
+
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	return "single value"
+}
+
+// pathElement is one step of a setting name: a key in a mapping, or an index in a list.
+type pathElement struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseName splits a setting name such as `mounts[0].location` or
+// `hostResolver.hosts["host.docker.internal"]` into its steps.
+func parseName(name string) ([]pathElement, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid setting name %q: %s", name, reason)
+	}
+	if name == "" {
+		return nil, invalid("must not be empty")
+	}
+	var elements []pathElement
+	rest := name
+	for rest != "" {
+		if strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if strings.HasPrefix(rest, `["`) {
+				end = strings.Index(rest, `"]`) + 1
+			}
+			if end <= 0 {
+				return nil, invalid("missing ]")
+			}
+			inner := rest[1:end]
+			if strings.HasPrefix(inner, `"`) {
+				key, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, invalid(fmt.Sprintf("bad quoted key %s", inner))
+				}
+				elements = append(elements, pathElement{key: key})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, invalid(fmt.Sprintf("bad list index %q", inner))
+				}
+				elements = append(elements, pathElement{index: index, isIndex: true})
+			}
+			rest = rest[end+1:]
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, invalid("empty name component")
+			}
+			elements = append(elements, pathElement{key: rest[:end]})
+			rest = rest[end:]
+		}
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, invalid("empty name component")
+			}
+		} else if rest != "" && !strings.HasPrefix(rest, "[") {
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest))
+		}
+	}
+	return elements, nil
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if it isn't there.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lookup finds the node for the elements. With create, missing mappings are added on the way.
+// It returns the parent of the node too, so that the node can be replaced.
+func (config *Config) lookup(name string, create bool) (node, parent *yaml.Node, err error) {
+	elements, err := parseName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	node = config.root()
+	for i, element := range elements {
+		parent = node
+		switch {
+		case element.isIndex && node.Kind == yaml.SequenceNode:
+			if element.index >= len(node.Content) {
+				return nil, nil, fmt.Errorf("%s: there are only %d items in the list", name, len(node.Content))
+			}
+			node = node.Content[element.index]
+			continue
+		case !element.isIndex && node.Kind == yaml.MappingNode:
+			next := mappingValue(node, element.key)
+			if next == nil {
+				if !create {
+					return nil, nil, fmt.Errorf("%s is not set", name)
+				}
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: element.key}, next)
+			}
+			node = next
+			continue
+		}
+		var partial string
+		if i > 0 {
+			partial = formatName(elements[:i])
+		}
+		if partial == "" {
+			partial = "the document"
+		}
+		if element.isIndex {
+			return nil, nil, fmt.Errorf("%s: %s is a %s, not a list", name, partial, describeNode(node))
+		}
+		return nil, nil, fmt.Errorf("%s: %s is a %s, not a mapping", name, partial, describeNode(node))
+	}
+	return node, parent, nil
+}
+
+func formatName(elements []pathElement) string {
+	var builder strings.Builder
+	for _, element := range elements {
+		switch {
+		case element.isIndex:
+			fmt.Fprintf(&builder, "[%d]", element.index)
+		case strings.ContainsAny(element.key, ".[]\"") || element.key == "":
+			fmt.Fprintf(&builder, "[%s]", strconv.Quote(element.key))
+		default:
+			if builder.Len() > 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString(element.key)
+		}
+	}
+	return builder.String()
+}
+
+// Get returns the value of a setting, as a decoded JSON value.
+func (config *Config) Get(name string) (interface{}, error) {
+	node, _, err := config.lookup(name, false)
+	if err != nil {
+		return nil, err
+	}
+	return decodeNode(node)
+}
+
+// Set replaces the value of a setting, adding it and any mappings containing it if needed.
+// Comments on the old value are kept.
+func (config *Config) Set(name string, value interface{}) error {
+	node, _, err := config.lookup(name, true)
+	if err != nil {
+		return err
+	}
+	var newNode yaml.Node
+	if err := newNode.Encode(value); err != nil {
+		return err
+	}
+	newNode.HeadComment, newNode.LineComment, newNode.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = newNode
+	return nil
+}
+
+// Append adds a value to the end of a list setting, creating the list if needed.
+func (config *Config) Append(name string, value interface{}) error {
+	node, _, err := config.lookup(name, true)
+	if err != nil {
+		return err
+	}
+	if node.Kind == yaml.MappingNode && len(node.Content) == 0 {
+		node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+	}
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s is a %s, not a list", name, describeNode(node))
+	}
+	var newNode yaml.Node
+	if err := newNode.Encode(value); err != nil {
+		return err
+	}
+	node.Content = append(node.Content, &newNode)
+	return nil
+}
+
+// Delete removes a setting.
+func (config *Config) Delete(name string) error {
+	node, parent, err := config.lookup(name, false)
+	if err != nil {
+		return err
+	}
+	for i, child := range parent.Content {
+		if child != node {
+			continue
+		}
+		if parent.Kind == yaml.MappingNode {
+			parent.Content = append(parent.Content[:i-1], parent.Content[i+1:]...)
+		} else {
+			parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+		}
+		break
+	}
+	return nil
+}
+
+// SetProvisionScript adds a system provisioning entry running the script, replacing the
+// entry whose script contains the marker, so that a tool can keep its own entry up to date.
+func (config *Config) SetProvisionScript(marker, script string) error {
+	entry := map[string]interface{}{"mode": "system", "script": script}
+	provisioning, err := config.Get("provision")
+	if err != nil {
+		// There are no provisioning entries yet.
+		return config.Append("provision", entry)
+	}
+	entries, ok := provisioning.([]interface{})
+	if !ok {
+		return fmt.Errorf("provision is not a list")
+	}
+	for i, existing := range entries {
+		existingMap, _ := existing.(map[string]interface{})
+		if existingScript, _ := existingMap["script"].(string); strings.Contains(existingScript, marker) {
+			return config.Set(fmt.Sprintf("provision[%d]", i), entry)
+		}
+	}
+	return config.Append("provision", entry)
+}
+
+// ParseValue converts a command-line string into the value of a setting. Settings that
+// are strings take the string as it is; others take it as YAML, so that numbers,
+// booleans, lists and mappings can be given.
+func ParseValue(name, raw string) (interface{}, error) {
+	if valueSchema := lookupSchema(name); valueSchema != nil && valueSchema.Type == "string" {
+		return raw, nil
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// lookupSchema returns the schema for a setting, or nil if the setting is unknown.
+func lookupSchema(name string) *settings.Schema {
+	elements, err := parseName(name)
+	if err != nil {
+		return nil
+	}
+	current, err := Schema()
+	if err != nil {
+		return nil
+	}
+	for _, element := range elements {
+		if element.isIndex {
+			current = current.Items
+		} else {
+			current = current.PropertySchema(element.key)
+		}
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
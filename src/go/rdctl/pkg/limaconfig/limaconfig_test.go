@@ -0,0 +1,195 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limaconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseName(t *testing.T) {
+	testCases := map[string][]pathElement{
+		"cpus":               {{key: "cpus"}},
+		"env.HTTP_PROXY":     {{key: "env"}, {key: "HTTP_PROXY"}},
+		"mounts[0].location": {{key: "mounts"}, {index: 0, isIndex: true}, {key: "location"}},
+		"portForwards[12]":   {{key: "portForwards"}, {index: 12, isIndex: true}},
+		`hosts["a.b"].x`:     {{key: "hosts"}, {key: "a.b"}, {key: "x"}},
+		`hosts["a]b"]`:       {{key: "hosts"}, {key: "a]b"}},
+		"networks[0][1]":     {{key: "networks"}, {index: 0, isIndex: true}, {index: 1, isIndex: true}},
+	}
+	for name, expected := range testCases {
+		elements, err := parseName(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, elements, name)
+		assert.Equal(t, name, formatName(elements), name)
+	}
+	for _, name := range []string{"", ".cpus", "cpus.", "env..A", "mounts[", "mounts[-1]", "mounts[x]", "mounts[0]x", `hosts["a]`} {
+		_, err := parseName(name)
+		assert.Error(t, err, name)
+	}
+}
+
+const overrideYAML = `# Overrides for the Rancher Desktop VM
+env:
+  HTTP_PROXY: http://proxy:3128 # the corporate proxy
+provision:
+  - mode: system
+    script: |
+      #!/bin/sh
+      echo hello
+`
+
+func TestGetAndSet(t *testing.T) {
+	config, err := Parse([]byte(overrideYAML))
+	require.NoError(t, err)
+
+	value, err := config.Get("env.HTTP_PROXY")
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy:3128", value)
+	value, err = config.Get("provision[0]")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"mode": "system", "script": "#!/bin/sh\necho hello\n"}, value)
+
+	_, err = config.Get("env.NO_PROXY")
+	assert.ErrorContains(t, err, "env.NO_PROXY is not set")
+	_, err = config.Get("provision[1]")
+	assert.ErrorContains(t, err, "only 1 items")
+	_, err = config.Get("env[0]")
+	assert.ErrorContains(t, err, "env is a mapping, not a list")
+	_, err = config.Get("env.HTTP_PROXY.x")
+	assert.ErrorContains(t, err, "env.HTTP_PROXY is a single value, not a mapping")
+
+	require.NoError(t, config.Set("env.HTTP_PROXY", "http://other:8080"))
+	require.NoError(t, config.Set(`hostResolver.hosts["host.example.com"]`, "1.2.3.4"))
+	require.NoError(t, config.Append("portForwards", map[string]interface{}{"guestPort": 80, "hostPort": 8080}))
+	require.NoError(t, config.Append("provision", map[string]interface{}{"script": "true"}))
+	require.NoError(t, config.Delete("provision[0]"))
+	assert.ErrorContains(t, config.Append("env", "x"), "env is a mapping, not a list")
+
+	contents, err := config.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, `# Overrides for the Rancher Desktop VM
+env:
+  HTTP_PROXY: http://other:8080 # the corporate proxy
+provision:
+  - script: "true"
+hostResolver:
+  hosts:
+    host.example.com: 1.2.3.4
+portForwards:
+  - guestPort: 80
+    hostPort: 8080
+`, string(contents))
+}
+
+func TestLoadAndSave(t *testing.T) {
+	path := OverridePath(t.TempDir())
+	config, err := Load(path)
+	require.NoError(t, err)
+	contents, err := config.Bytes()
+	require.NoError(t, err)
+	assert.Empty(t, contents)
+
+	require.NoError(t, config.Set("cpus", 4))
+	require.NoError(t, config.Save(path))
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "cpus: 4\n", string(contents))
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "override.yaml"), path)
+
+	require.NoError(t, config.Delete("cpus"))
+	require.NoError(t, config.Save(path))
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, contents)
+
+	require.NoError(t, os.WriteFile(path, []byte("- a list\n"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "must be a mapping")
+}
+
+func TestParseValue(t *testing.T) {
+	testCases := []struct {
+		name, raw string
+		expected  interface{}
+	}{
+		{"env.COUNT", "1", "1"},
+		{"memory", "4GiB", "4GiB"},
+		{"cpus", "4", 4},
+		{"mountInotify", "true", true},
+		{"mounts[0]", "{location: /data, writable: true}", map[string]interface{}{"location": "/data", "writable": true}},
+		{"dns", "[1.1.1.1, 8.8.8.8]", []interface{}{"1.1.1.1", "8.8.8.8"}},
+		{"unknown", "5", 5},
+	}
+	for _, testCase := range testCases {
+		value, err := ParseValue(testCase.name, testCase.raw)
+		require.NoError(t, err, testCase.name)
+		assert.Equal(t, testCase.expected, value, testCase.name)
+	}
+	_, err := ParseValue("mounts[0]", "{location")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	config, err := Parse([]byte(overrideYAML + `cpus: 2
+mounts:
+  - location: ~/data
+    writable: true
+portForwards:
+  - guestPort: 8080
+    hostPort: 18080
+`))
+	require.NoError(t, err)
+	problems, err := config.Validate()
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	config, err = Parse([]byte(`cpus: many
+vmType: wsl
+mounts:
+  - writable: true
+provision:
+  - mode: system
+portForwards:
+  - guestPort: 70000
+    hostPortRange: [1]
+env:
+  COUNT: 1
+unknownSetting: true
+`))
+	require.NoError(t, err)
+	problems, err = config.Validate()
+	require.NoError(t, err)
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.Error())
+	}
+	assert.Equal(t, []string{
+		`cpus: expected an integer, got "many"`,
+		"env.COUNT: expected a string, got 1",
+		"mounts[0].location: a mount needs a location",
+		"portForwards[0].guestPort: 70000 is not a valid port",
+		"portForwards[0].hostPortRange: a port range needs exactly two ports",
+		"provision[0].script: a provisioning entry needs a script",
+		"unknownSetting: unknown setting",
+		`vmType: invalid value "wsl"; must be one of [qemu, vz]`,
+	}, messages)
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package limaconfig
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/settings"
+)
+
+// schemaJSON describes the parts of the Lima configuration that can be overridden,
+// following the lima.yaml reference (examples/default.yaml in Lima) for the version
+// bundled with Rancher Desktop.
+const schemaJSON = `{
+  "type": "object",
+  "properties": {
+    "vmType": {"type": "string", "enum": ["qemu", "vz"]},
+    "os": {"type": "string", "enum": ["Linux"]},
+    "arch": {"type": "string", "enum": ["x86_64", "aarch64", "armv7l", "riscv64"]},
+    "images": {"type": "array", "items": {"type": "object", "properties": {
+      "location": {"type": "string"},
+      "arch": {"type": "string"},
+      "digest": {"type": "string"},
+      "kernel": {"type": "object", "properties": {
+        "location": {"type": "string"}, "arch": {"type": "string"}, "digest": {"type": "string"}, "cmdline": {"type": "string"}}},
+      "initrd": {"type": "object", "properties": {
+        "location": {"type": "string"}, "arch": {"type": "string"}, "digest": {"type": "string"}}}}}},
+    "cpuType": {"type": "object", "additionalProperties": {"type": "string"}},
+    "cpus": {"type": "integer", "minimum": 1},
+    "memory": {"type": "string"},
+    "disk": {"type": "string"},
+    "additionalDisks": {"type": "array"},
+    "mounts": {"type": "array", "items": {"type": "object", "properties": {
+      "location": {"type": "string"},
+      "mountPoint": {"type": "string"},
+      "writable": {"type": "boolean"},
+      "sshfs": {"type": "object", "properties": {
+        "cache": {"type": "boolean"}, "followSymlinks": {"type": "boolean"}, "sftpDriver": {"type": "string"}}},
+      "9p": {"type": "object", "properties": {
+        "securityModel": {"type": "string"}, "protocolVersion": {"type": "string"}, "msize": {"type": "string"}, "cache": {"type": "string"}}},
+      "virtiofs": {"type": "object", "properties": {"queueSize": {"type": "integer", "minimum": 0}}}}}},
+    "mountType": {"type": "string", "enum": ["reverse-sshfs", "9p", "virtiofs"]},
+    "mountInotify": {"type": "boolean"},
+    "ssh": {"type": "object", "properties": {
+      "localPort": {"type": "integer", "minimum": 0},
+      "loadDotSSHPubKeys": {"type": "boolean"},
+      "forwardAgent": {"type": "boolean"},
+      "forwardX11": {"type": "boolean"},
+      "forwardX11Trusted": {"type": "boolean"}}},
+    "firmware": {"type": "object", "properties": {"legacyBIOS": {"type": "boolean"}}},
+    "audio": {"type": "object", "properties": {"device": {"type": "string"}}},
+    "video": {"type": "object", "properties": {
+      "display": {"type": "string"},
+      "vnc": {"type": "object", "properties": {"display": {"type": "string"}}}}},
+    "provision": {"type": "array", "items": {"type": "object", "properties": {
+      "mode": {"type": "string", "enum": ["system", "user", "boot", "dependency"]},
+      "script": {"type": "string"},
+      "skipDefaultDependencyResolution": {"type": "boolean"}}}},
+    "upgradePackages": {"type": "boolean"},
+    "containerd": {"type": "object", "properties": {
+      "system": {"type": "boolean"}, "user": {"type": "boolean"}, "archives": {"type": "array"}}},
+    "guestInstallPrefix": {"type": "string"},
+    "probes": {"type": "array", "items": {"type": "object", "properties": {
+      "mode": {"type": "string", "enum": ["readiness"]},
+      "description": {"type": "string"},
+      "script": {"type": "string"},
+      "hint": {"type": "string"}}}},
+    "portForwards": {"type": "array", "items": {"type": "object", "properties": {
+      "guestIPMustBeZero": {"type": "boolean"},
+      "guestIP": {"type": "string"},
+      "guestPort": {"type": "integer", "minimum": 0},
+      "guestPortRange": {"type": "array", "items": {"type": "integer", "minimum": 0}},
+      "guestSocket": {"type": "string"},
+      "hostIP": {"type": "string"},
+      "hostPort": {"type": "integer", "minimum": 0},
+      "hostPortRange": {"type": "array", "items": {"type": "integer", "minimum": 0}},
+      "hostSocket": {"type": "string"},
+      "proto": {"type": "string", "enum": ["tcp"]},
+      "reverse": {"type": "boolean"},
+      "ignore": {"type": "boolean"}}}},
+    "copyToHost": {"type": "array", "items": {"type": "object", "properties": {
+      "guest": {"type": "string"}, "host": {"type": "string"}, "deleteOnStop": {"type": "boolean"}}}},
+    "message": {"type": "string"},
+    "networks": {"type": "array", "items": {"type": "object", "properties": {
+      "lima": {"type": "string"},
+      "socket": {"type": "string"},
+      "vnl": {"type": "string"},
+      "switchPort": {"type": "integer"},
+      "macAddress": {"type": "string"},
+      "interface": {"type": "string"},
+      "metric": {"type": "integer", "minimum": 0},
+      "vzNAT": {"type": "boolean"}}}},
+    "env": {"type": "object", "additionalProperties": {"type": "string"}},
+    "hostResolver": {"type": "object", "properties": {
+      "enabled": {"type": "boolean"},
+      "ipv6": {"type": "boolean"},
+      "hosts": {"type": "object", "additionalProperties": {"type": "string"}}}},
+    "useHostResolver": {"type": "boolean"},
+    "dns": {"type": "array", "items": {"type": "string"}},
+    "propagateProxyEnv": {"type": "boolean"},
+    "caCerts": {"type": "object", "properties": {
+      "removeDefaults": {"type": "boolean"},
+      "files": {"type": "array", "items": {"type": "string"}},
+      "certs": {"type": "array", "items": {"type": "string"}}}},
+    "rosetta": {"type": "object", "properties": {"enabled": {"type": "boolean"}, "binfmt": {"type": "boolean"}}},
+    "plain": {"type": "boolean"},
+    "timezone": {"type": "string"}
+  }
+}`
+
+// Schema returns the schema of the settings in override.yaml.
+func Schema() (*settings.Schema, error) {
+	return settings.ParseSchema(schemaJSON)
+}
+
+// Validate checks the document against the structure of the Lima configuration,
+// and for the mistakes Lima would only report when the VM starts.
+func (config *Config) Validate() ([]settings.ValidationError, error) {
+	schema, err := Schema()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := config.Value()
+	if err != nil {
+		return nil, err
+	}
+	problems := schema.ValidateValue(doc)
+	fail := func(path, format string, args ...interface{}) {
+		problems = append(problems, settings.ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	for i, mount := range listOfMaps(doc["mounts"]) {
+		if mount["location"] == nil || mount["location"] == "" {
+			fail(fmt.Sprintf("mounts[%d].location", i), "a mount needs a location")
+		}
+	}
+	for i, provision := range listOfMaps(doc["provision"]) {
+		if provision["script"] == nil || provision["script"] == "" {
+			fail(fmt.Sprintf("provision[%d].script", i), "a provisioning entry needs a script")
+		}
+	}
+	for i, portForward := range listOfMaps(doc["portForwards"]) {
+		for _, key := range []string{"guestPort", "hostPort"} {
+			if port, ok := portForward[key].(float64); ok && port > 65535 {
+				fail(fmt.Sprintf("portForwards[%d].%s", i, key), "%v is not a valid port", port)
+			}
+		}
+		for _, key := range []string{"guestPortRange", "hostPortRange"} {
+			if portRange, ok := portForward[key].([]interface{}); ok && len(portRange) != 2 {
+				fail(fmt.Sprintf("portForwards[%d].%s", i, key), "a port range needs exactly two ports")
+			}
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return problems, nil
+}
+
+// listOfMaps returns the mappings in a list; anything else is returned as an empty mapping,
+// as it has already been reported as the wrong type.
+func listOfMaps(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	result := make([]map[string]interface{}, len(list))
+	for i, item := range list {
+		result[i], _ = item.(map[string]interface{})
+	}
+	return result
+}
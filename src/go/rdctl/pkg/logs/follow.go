@@ -0,0 +1,198 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Follower watches the current log files of components for new lines, as `tail -F` does.
+// It notices when a file is truncated (as logrotate's copytruncate does) or replaced
+// (as happens when it is rotated, or when the app restarts), and reads the new file from the start.
+// Files are only opened while they are read, so that they can still be rotated or deleted on Windows.
+type Follower struct {
+	dir string
+	// names are the components to follow; if there are none, all components are followed,
+	// including those whose logs appear later.
+	names   []string
+	watches map[string]*watch
+}
+
+// watch is the state of one followed file.
+type watch struct {
+	component string
+	path      string
+	info      os.FileInfo
+	offset    int64
+	// partial is the start of a line that hasn't been finished yet.
+	partial  string
+	lastTime time.Time
+}
+
+// NewFollower starts following the components' current log files from the sizes they had when
+// they were found, so that it carries on from where [Merge] stops. When following all components,
+// pass all of them; logs for new components are then picked up as they appear.
+func NewFollower(dir string, components []Component, all bool) *Follower {
+	follower := &Follower{dir: dir, watches: map[string]*watch{}}
+	for _, component := range components {
+		if !all {
+			follower.names = append(follower.names, component.Name)
+		}
+		current := filepath.Join(dir, component.Name+logSuffix)
+		w := &watch{component: component.Name, path: current}
+		for _, file := range component.Files {
+			if file.Path != current {
+				continue
+			}
+			if info, err := statFile(current); err == nil {
+				w.info = info
+				w.offset = file.Size
+			}
+		}
+		follower.watches[component.Name] = w
+	}
+	return follower
+}
+
+// statFile returns the file info of the path, with the identity of the file loaded.
+func statFile(path string) (os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	// On Windows, the file ID is only looked up when it is first needed, by path;
+	// compare the info to itself to look it up now, while it's still this file.
+	os.SameFile(info, info)
+	return info, nil
+}
+
+// Poll returns the lines added to the followed files since the last call, in time order.
+func (f *Follower) Poll() ([]Line, error) {
+	if len(f.names) == 0 {
+		components, err := Components(f.dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range components {
+			if _, ok := f.watches[component.Name]; !ok {
+				f.watches[component.Name] = &watch{component: component.Name, path: filepath.Join(f.dir, component.Name+logSuffix)}
+			}
+		}
+	}
+	var batches [][]Line
+	for _, name := range f.componentNames() {
+		lines, err := f.watches[name].poll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s logs: %w", name, err)
+		}
+		if len(lines) > 0 {
+			batches = append(batches, lines)
+		}
+	}
+	return mergeBatches(batches), nil
+}
+
+// componentNames returns the followed components in a stable order.
+func (f *Follower) componentNames() []string {
+	if len(f.names) > 0 {
+		return f.names
+	}
+	components := make([]Component, 0, len(f.watches))
+	for name := range f.watches {
+		components = append(components, Component{Name: name})
+	}
+	sortComponents(components)
+	names := make([]string, len(components))
+	for i, component := range components {
+		names[i] = component.Name
+	}
+	return names
+}
+
+func (w *watch) poll() ([]Line, error) {
+	info, err := statFile(w.path)
+	if errors.Is(err, os.ErrNotExist) {
+		// The file may have been rotated away; wait for the new one.
+		w.info = nil
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if w.info == nil || !os.SameFile(w.info, info) || info.Size() < w.offset {
+		w.offset = 0
+		w.partial = ""
+	}
+	w.info = info
+	if info.Size() == w.offset {
+		return nil, nil
+	}
+	file, err := os.Open(w.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(w.offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	contents, err := io.ReadAll(io.LimitReader(file, info.Size()-w.offset))
+	if err != nil {
+		return nil, err
+	}
+	w.offset += int64(len(contents))
+	text := w.partial + string(contents)
+	lastNewline := strings.LastIndex(text, "\n")
+	w.partial = text[lastNewline+1:]
+	if lastNewline < 0 {
+		return nil, nil
+	}
+	var lines []Line
+	reader := lineReader{component: w.component, lastTime: w.lastTime}
+	for _, text := range strings.SplitAfter(text[:lastNewline+1], "\n") {
+		if text != "" {
+			lines = append(lines, reader.makeLine(text))
+		}
+	}
+	w.lastTime = reader.lastTime
+	return lines, nil
+}
+
+// mergeBatches merges lists of lines, each in time order, into one list in time order.
+func mergeBatches(batches [][]Line) []Line {
+	var result []Line
+	for {
+		earliest := -1
+		for i, batch := range batches {
+			if len(batch) > 0 && (earliest < 0 || batch[0].Time.Before(batches[earliest][0].Time)) {
+				earliest = i
+			}
+		}
+		if earliest < 0 {
+			return result
+		}
+		result = append(result, batches[earliest][0])
+		batches[earliest] = batches[earliest][1:]
+	}
+}
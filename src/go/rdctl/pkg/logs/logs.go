@@ -0,0 +1,338 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs finds, reads and merges the Rancher Desktop log files in `Paths.Logs`.
+// Each component (background, lima, k3s, wsl and so on) writes `<component>.log`;
+// log rotation may leave older files next to it, named `<component>.log.1`,
+// `<component>.log.2.gz` and so on, with larger numbers being older.
+package logs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const logSuffix = ".log"
+
+// File is one of the files of a component, with its size when it was found.
+type File struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Component is the set of log files written by one part of Rancher Desktop.
+type Component struct {
+	Name string `json:"name"`
+	// Files are the component's log files, oldest first; the current log is last.
+	Files []File `json:"files"`
+}
+
+// Line is one line from a log file.
+type Line struct {
+	Component string
+	// Time is when the line was logged. Lines without a timestamp of their own, such as the
+	// rest of a multi-line message, take the time of the line before them.
+	Time time.Time
+	Text string
+}
+
+// rotatedPattern matches the names of rotated log files, like `k3s.log.1` and `k3s.log.2.gz`.
+var rotatedPattern = regexp.MustCompile(`^(.+)\.log\.(\d+)(\.gz)?$`)
+
+// Components returns the components with log files in the directory, sorted by name.
+func Components(dir string) ([]Component, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory: %w", err)
+	}
+	type rotatedFile struct {
+		File
+		generation int
+	}
+	files := map[string][]rotatedFile{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		name := entry.Name()
+		var component string
+		generation := 0
+		if strings.HasSuffix(name, logSuffix) {
+			component = strings.TrimSuffix(name, logSuffix)
+		} else if match := rotatedPattern.FindStringSubmatch(name); match != nil {
+			component = match[1]
+			if generation, err = strconv.Atoi(match[2]); err != nil {
+				continue
+			}
+		} else {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read log directory: %w", err)
+		}
+		file := File{Path: filepath.Join(dir, name), Size: info.Size()}
+		files[component] = append(files[component], rotatedFile{file, generation})
+	}
+	components := make([]Component, 0, len(files))
+	for name, rotatedFiles := range files {
+		sort.Slice(rotatedFiles, func(i, j int) bool {
+			// The current log, generation 0, is the newest.
+			gi, gj := rotatedFiles[i].generation, rotatedFiles[j].generation
+			return gi != 0 && (gj == 0 || gi > gj)
+		})
+		component := Component{Name: name}
+		for _, file := range rotatedFiles {
+			component.Files = append(component.Files, file.File)
+		}
+		components = append(components, component)
+	}
+	sortComponents(components)
+	return components, nil
+}
+
+func sortComponents(components []Component) {
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+}
+
+// Select returns the named components, in the order given, or all of them if no names are given.
+func Select(components []Component, names []string) ([]Component, error) {
+	if len(names) == 0 {
+		return components, nil
+	}
+	byName := map[string]Component{}
+	for _, component := range components {
+		byName[component.Name] = component
+	}
+	var result []Component
+	for _, name := range names {
+		component, ok := byName[strings.TrimSuffix(name, logSuffix)]
+		if !ok {
+			available := make([]string, len(components))
+			for i, component := range components {
+				available[i] = component.Name
+			}
+			return nil, fmt.Errorf("no logs found for component %q; available components are: %s", name, strings.Join(available, ", "))
+		}
+		result = append(result, component)
+	}
+	return result, nil
+}
+
+var timestampPatterns = []*regexp.Regexp{
+	// Rancher Desktop's own logs, and most others, start with the time: `2023-08-01T12:34:56.789Z: ...`
+	regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`),
+	// logrus text format, as used by k3s and the guest agents: `time="2023-08-01T12:34:56Z" level=info ...`
+	regexp.MustCompile(`\btime="([^"]+)"`),
+	// logrus JSON format, as used by lima: `{"level":"info","msg":"...","time":"2023-08-01T12:34:56Z"}`
+	regexp.MustCompile(`"time":"([^"]+)"`),
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// ParseTime finds the timestamp of a log line, if it has one.
+// Timestamps without a time zone are taken to be in local time.
+func ParseTime(text string) (time.Time, bool) {
+	for _, pattern := range timestampPatterns {
+		match := pattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		value := strings.Replace(strings.Replace(match[1], " ", "T", 1), ",", ".", 1)
+		for _, layout := range timestampLayouts {
+			if timestamp, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				return timestamp, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseSince converts the argument of `--since`, either a duration before now (like `10m`)
+// or a time (like `2023-08-01T12:00:00Z` or `2023-08-01`), into a time.
+func ParseSince(since string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(since); err == nil {
+		return now.Add(-duration), nil
+	}
+	if timestamp, ok := ParseTime(since); ok {
+		return timestamp, nil
+	}
+	if timestamp, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return timestamp, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected a duration like 10m or a time like 2006-01-02T15:04:05Z", since)
+}
+
+// Filter selects log lines.
+type Filter struct {
+	// Since drops lines logged before it, unless it is zero.
+	Since time.Time
+	// Pattern drops lines that don't match it, unless it is nil.
+	Pattern *regexp.Regexp
+}
+
+// Match reports whether the line passes the filter.
+func (filter Filter) Match(line Line) bool {
+	if !filter.Since.IsZero() && line.Time.Before(filter.Since) {
+		return false
+	}
+	return filter.Pattern == nil || filter.Pattern.MatchString(line.Text)
+}
+
+// lineReader reads the lines of a component's files in order.
+type lineReader struct {
+	component string
+	files     []File
+	current   io.ReadCloser
+	reader    *bufio.Reader
+	lastTime  time.Time
+}
+
+// openNext opens the next file, returning false when there are no more.
+func (r *lineReader) openNext() (bool, error) {
+	if r.current != nil {
+		r.current.Close()
+		r.current = nil
+	}
+	if len(r.files) == 0 {
+		return false, nil
+	}
+	file := r.files[0]
+	r.files = r.files[1:]
+	opened, err := os.Open(file.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// The file was rotated away since it was found.
+			return r.openNext()
+		}
+		return false, err
+	}
+	r.current = opened
+	// Only read what was there when the file was found, so that following it can pick up from there.
+	var reader io.Reader = io.LimitReader(opened, file.Size)
+	if strings.HasSuffix(file.Path, ".gz") {
+		gzipReader, err := gzip.NewReader(opened)
+		if err != nil {
+			opened.Close()
+			return false, fmt.Errorf("failed to decompress %s: %w", file.Path, err)
+		}
+		reader = gzipReader
+	}
+	r.reader = bufio.NewReader(reader)
+	return true, nil
+}
+
+// next returns the next line, or io.EOF after the last one.
+func (r *lineReader) next() (Line, error) {
+	for {
+		if r.reader == nil {
+			if ok, err := r.openNext(); err != nil {
+				return Line{}, err
+			} else if !ok {
+				return Line{}, io.EOF
+			}
+		}
+		text, err := r.reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Line{}, err
+		}
+		if text == "" && err != nil {
+			r.reader = nil
+			continue
+		}
+		return r.makeLine(text), nil
+	}
+}
+
+func (r *lineReader) makeLine(text string) Line {
+	text = strings.TrimRight(text, "\r\n")
+	if timestamp, ok := ParseTime(text); ok {
+		r.lastTime = timestamp
+	}
+	return Line{Component: r.component, Time: r.lastTime, Text: text}
+}
+
+func (r *lineReader) close() {
+	if r.current != nil {
+		r.current.Close()
+	}
+}
+
+// Merge reads the files of the components, and calls emit for each line that passes the filter,
+// in time order. Lines logged at the same time are kept in the order of the components.
+func Merge(components []Component, filter Filter, emit func(Line) error) error {
+	readers := make([]*lineReader, 0, len(components))
+	heads := make([]*Line, 0, len(components))
+	defer func() {
+		for _, reader := range readers {
+			reader.close()
+		}
+	}()
+	advance := func(i int) error {
+		line, err := readers[i].next()
+		if errors.Is(err, io.EOF) {
+			heads[i] = nil
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read %s logs: %w", readers[i].component, err)
+		}
+		heads[i] = &line
+		return nil
+	}
+	for i, component := range components {
+		readers = append(readers, &lineReader{component: component.Name, files: component.Files})
+		heads = append(heads, nil)
+		if err := advance(i); err != nil {
+			return err
+		}
+	}
+	for {
+		earliest := -1
+		for i, head := range heads {
+			if head != nil && (earliest < 0 || head.Time.Before(heads[earliest].Time)) {
+				earliest = i
+			}
+		}
+		if earliest < 0 {
+			return nil
+		}
+		if line := *heads[earliest]; filter.Match(line) {
+			if err := emit(line); err != nil {
+				return err
+			}
+		}
+		if err := advance(earliest); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package logs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+}
+
+func writeGzipFile(t *testing.T, path, contents string) {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err := writer.Write([]byte(contents))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	writeFile(t, path, buffer.String())
+}
+
+func collect(t *testing.T, components []Component, filter Filter) []string {
+	t.Helper()
+	var result []string
+	require.NoError(t, Merge(components, filter, func(line Line) error {
+		result = append(result, line.Component+": "+line.Text)
+		return nil
+	}))
+	return result
+}
+
+func TestComponents(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "k3s.log"), "")
+	writeFile(t, filepath.Join(dir, "k3s.log.1"), "")
+	writeGzipFile(t, filepath.Join(dir, "k3s.log.10.gz"), "")
+	writeFile(t, filepath.Join(dir, "k3s.log.2"), "")
+	writeFile(t, filepath.Join(dir, "background.log"), "some text")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lima.log"), 0o755))
+
+	components, err := Components(dir)
+	require.NoError(t, err)
+	require.Len(t, components, 2)
+	assert.Equal(t, Component{Name: "background", Files: []File{{filepath.Join(dir, "background.log"), 9}}}, components[0])
+	assert.Equal(t, "k3s", components[1].Name)
+	var names []string
+	for _, file := range components[1].Files {
+		names = append(names, filepath.Base(file.Path))
+	}
+	assert.Equal(t, []string{"k3s.log.10.gz", "k3s.log.2", "k3s.log.1", "k3s.log"}, names)
+
+	selected, err := Select(components, []string{"k3s.log"})
+	require.NoError(t, err)
+	assert.Equal(t, []Component{components[1]}, selected)
+	_, err = Select(components, []string{"wsl"})
+	assert.EqualError(t, err, `no logs found for component "wsl"; available components are: background, k3s`)
+}
+
+func TestParseTime(t *testing.T) {
+	expected := time.Date(2023, 8, 1, 12, 34, 56, 789000000, time.UTC)
+	for _, text := range []string{
+		"2023-08-01T12:34:56.789Z: Rancher Desktop message",
+		"[2023-08-01 12:34:56.789Z] bracketed",
+		`time="2023-08-01T12:34:56.789Z" level=info msg="from k3s"`,
+		`{"level":"info","msg":"from lima","time":"2023-08-01T12:34:56.789Z"}`,
+		"2023-08-01T14:34:56.789+02:00 with an offset",
+	} {
+		timestamp, ok := ParseTime(text)
+		if assert.True(t, ok, text) {
+			assert.True(t, expected.Equal(timestamp), "%s: got %s", text, timestamp)
+		}
+	}
+	local, ok := ParseTime("2023-08-01 12:34:56 no zone")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2023, 8, 1, 12, 34, 56, 0, time.Local), local)
+
+	_, ok = ParseTime("    at Object.<anonymous> (stack trace)")
+	assert.False(t, ok)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	since, err := ParseSince("10m", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-10*time.Minute), since)
+	since, err = ParseSince("2023-07-31T10:00:00Z", now)
+	require.NoError(t, err)
+	assert.True(t, since.Equal(time.Date(2023, 7, 31, 10, 0, 0, 0, time.UTC)))
+	since, err = ParseSince("2023-07-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 7, 31, 0, 0, 0, 0, time.Local), since)
+	_, err = ParseSince("yesterday", now)
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	writeGzipFile(t, filepath.Join(dir, "k3s.log.2.gz"), `time="2023-08-01T10:00:00Z" msg="oldest"`+"\n")
+	writeFile(t, filepath.Join(dir, "k3s.log.1"), `time="2023-08-01T11:00:00Z" msg="older"`+"\n")
+	writeFile(t, filepath.Join(dir, "k3s.log"), `time="2023-08-01T12:30:00Z" msg="current"`+"\n")
+	writeFile(t, filepath.Join(dir, "background.log"), ""+
+		"2023-08-01T10:30:00.000Z: starting\n"+
+		"2023-08-01T12:00:00.000Z: Error: failed\n"+
+		"    at stack trace\n"+
+		"2023-08-01T12:30:00.000Z: same time as k3s\r\n"+
+		"unterminated")
+	components, err := Components(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`k3s: time="2023-08-01T10:00:00Z" msg="oldest"`,
+		"background: 2023-08-01T10:30:00.000Z: starting",
+		`k3s: time="2023-08-01T11:00:00Z" msg="older"`,
+		"background: 2023-08-01T12:00:00.000Z: Error: failed",
+		"background:     at stack trace",
+		"background: 2023-08-01T12:30:00.000Z: same time as k3s",
+		"background: unterminated",
+		`k3s: time="2023-08-01T12:30:00Z" msg="current"`,
+	}, collect(t, components, Filter{}))
+
+	filter := Filter{Since: time.Date(2023, 8, 1, 11, 30, 0, 0, time.UTC), Pattern: regexp.MustCompile(`(?i)error|stack|k3s`)}
+	assert.Equal(t, []string{
+		"background: 2023-08-01T12:00:00.000Z: Error: failed",
+		"background:     at stack trace",
+		"background: 2023-08-01T12:30:00.000Z: same time as k3s",
+	}, collect(t, components, filter))
+}
+
+func TestFollower(t *testing.T) {
+	poll := func(t *testing.T, follower *Follower) []string {
+		t.Helper()
+		lines, err := follower.Poll()
+		require.NoError(t, err)
+		var result []string
+		for _, line := range lines {
+			result = append(result, line.Component+": "+line.Text)
+		}
+		return result
+	}
+	appendFile := func(t *testing.T, path, contents string) {
+		t.Helper()
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = file.WriteString(contents)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+	}
+
+	t.Run("follows appended, truncated and replaced files", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "k3s.log")
+		writeFile(t, path, "already read\n")
+		components, err := Components(dir)
+		require.NoError(t, err)
+		follower := NewFollower(dir, components, false)
+		assert.Empty(t, poll(t, follower))
+
+		appendFile(t, path, "first\nsecond part")
+		assert.Equal(t, []string{"k3s: first"}, poll(t, follower))
+		appendFile(t, path, " ends here\n")
+		assert.Equal(t, []string{"k3s: second part ends here"}, poll(t, follower))
+
+		// logrotate's copytruncate
+		require.NoError(t, os.Truncate(path, 0))
+		assert.Empty(t, poll(t, follower))
+		appendFile(t, path, "after truncation\n")
+		assert.Equal(t, []string{"k3s: after truncation"}, poll(t, follower))
+
+		// Rotation by renaming, with a new file that is longer than the old one.
+		require.NoError(t, os.Rename(path, path+".1"))
+		assert.Empty(t, poll(t, follower))
+		writeFile(t, path, "a new file with a longer first line\n")
+		assert.Equal(t, []string{"k3s: a new file with a longer first line"}, poll(t, follower))
+	})
+	t.Run("picks up new components when following all of them", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "background.log"), "2023-08-01T12:00:00.000Z: old\n")
+		components, err := Components(dir)
+		require.NoError(t, err)
+		follower := NewFollower(dir, components, true)
+		writeFile(t, filepath.Join(dir, "wsl.log"), "2023-08-01T12:00:02.000Z: new component\n")
+		appendFile(t, filepath.Join(dir, "background.log"), "2023-08-01T12:00:01.000Z: new line\n")
+		assert.Equal(t, []string{
+			"background: 2023-08-01T12:00:01.000Z: new line",
+			"wsl: 2023-08-01T12:00:02.000Z: new component",
+		}, poll(t, follower))
+	})
+}
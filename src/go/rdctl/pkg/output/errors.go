@@ -0,0 +1,95 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+)
+
+// Error codes reported in the error envelope.
+const (
+	CodeError              = "error"
+	CodeConnectionRefused  = "connection_refused"
+	CodeUnauthorized       = "unauthorized"
+	CodeBadRequest         = "bad_request"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeServerError        = "server_error"
+	CodeUnexpectedResponse = "unexpected_response"
+	CodeNotFound           = "not_found"
+	CodePermissionDenied   = "permission_denied"
+	CodeSnapshotExists     = "snapshot_exists"
+	CodeSnapshotIncomplete = "snapshot_incomplete"
+)
+
+// ErrorEnvelope is the JSON form of an error. The `error` field holds the same message that
+// would otherwise be written to standard error, and `code` is one of the codes above.
+type ErrorEnvelope struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// ErrorCode returns the machine-readable code for an error.
+func ErrorCode(err error) string {
+	var statusError *client.StatusError
+	switch {
+	case errors.Is(err, client.ErrConnectionRefused):
+		return CodeConnectionRefused
+	case errors.As(err, &statusError):
+		switch statusError.StatusCode {
+		case 400:
+			return CodeBadRequest
+		case 401:
+			return CodeUnauthorized
+		case 413:
+			return CodePayloadTooLarge
+		case 500:
+			return CodeServerError
+		}
+		return CodeUnexpectedResponse
+	case errors.Is(err, snapshot.ErrNameExists):
+		return CodeSnapshotExists
+	case errors.Is(err, snapshot.ErrIncompleteSnapshot):
+		return CodeSnapshotIncomplete
+	case errors.Is(err, os.ErrNotExist):
+		return CodeNotFound
+	case errors.Is(err, os.ErrPermission):
+		return CodePermissionDenied
+	}
+	return CodeError
+}
+
+// NewErrorEnvelope wraps an error for structured output.
+func NewErrorEnvelope(err error) ErrorEnvelope {
+	return ErrorEnvelope{Error: err.Error(), Code: ErrorCode(err)}
+}
+
+// WriteError writes the error envelope as a single line of JSON.
+func WriteError(w io.Writer, err error) error {
+	jsonBuffer, marshalErr := json.Marshal(NewErrorEnvelope(err))
+	if marshalErr != nil {
+		return fmt.Errorf("error json-converting error messages: %w", marshalErr)
+	}
+	_, writeErr := fmt.Fprintln(w, string(jsonBuffer))
+	return writeErr
+}
@@ -0,0 +1,106 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// ANSI colours used for JSON values, similar to those used by jq.
+const (
+	colorReset   = "\x1b[0m"
+	colorKey     = "\x1b[34;1m"
+	colorString  = "\x1b[32m"
+	colorLiteral = "\x1b[39m"
+	colorNull    = "\x1b[90m"
+)
+
+// IsTerminal reports whether the file is connected to a terminal.
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// UseColor reports whether output to the file should be coloured: it must be a terminal,
+// and the user must not have opted out by setting NO_COLOR.
+func UseColor(file *os.File) bool {
+	_, noColor := os.LookupEnv("NO_COLOR")
+	return !noColor && IsTerminal(file)
+}
+
+// WritePrettyJSON writes the JSON document indented and, optionally, coloured.
+// Data that isn't valid JSON is written unchanged.
+func WritePrettyJSON(w io.Writer, data []byte, color bool) error {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data, "", "  "); err != nil {
+		_, err = w.Write(data)
+		return err
+	}
+	result := indented.Bytes()
+	if color {
+		result = colorizeJSON(result)
+	}
+	_, err := w.Write(append(result, '\n'))
+	return err
+}
+
+// colorizeJSON adds colours to valid, indented JSON.
+func colorizeJSON(data []byte) []byte {
+	var result bytes.Buffer
+	for i := 0; i < len(data); {
+		switch c := data[i]; {
+		case c == '"':
+			end := i + 1
+			for ; end < len(data) && data[end] != '"'; end++ {
+				if data[end] == '\\' {
+					end++
+				}
+			}
+			end++
+			color := colorString
+			if next := bytes.TrimLeft(data[end:], " \n"); len(next) > 0 && next[0] == ':' {
+				color = colorKey
+			}
+			writeColored(&result, color, data[i:end])
+			i = end
+		case c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z'):
+			end := i + 1
+			for end < len(data) && bytes.IndexByte([]byte(" ,\n]}"), data[end]) < 0 {
+				end++
+			}
+			color := colorLiteral
+			if string(data[i:end]) == "null" {
+				color = colorNull
+			}
+			writeColored(&result, color, data[i:end])
+			i = end
+		default:
+			result.WriteByte(c)
+			i++
+		}
+	}
+	return result.Bytes()
+}
+
+func writeColored(buffer *bytes.Buffer, color string, text []byte) {
+	buffer.WriteString(color)
+	buffer.Write(text)
+	buffer.WriteString(colorReset)
+}
@@ -0,0 +1,148 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package output renders command results in the format chosen with the global `--output` flag,
+// and reports errors in a consistent JSON envelope.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatTable      = "table"
+	goTemplatePrefix = "go-template="
+)
+
+// FlagUsage describes the values accepted by the `--output` flag.
+const FlagUsage = "output format, for the commands that support it: json|yaml|table|go-template=TEMPLATE"
+
+// Table is the tabular form of a command's result.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// Printer writes results in one output format.
+type Printer struct {
+	format   string
+	template *template.Template
+}
+
+// NewPrinter returns a printer for an `--output` flag value.
+func NewPrinter(spec string) (*Printer, error) {
+	switch spec {
+	case FormatJSON, FormatYAML, FormatTable:
+		return &Printer{format: spec}, nil
+	}
+	if templateText, found := strings.CutPrefix(spec, goTemplatePrefix); found {
+		tmpl, err := template.New("output").Option("missingkey=error").Parse(templateText)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		return &Printer{format: goTemplatePrefix, template: tmpl}, nil
+	}
+	return nil, fmt.Errorf("invalid output format %q; must be one of json, yaml, table, or go-template=TEMPLATE", spec)
+}
+
+// IsStructured reports whether the printer writes machine-readable output, which is also
+// how errors are reported.
+func (printer *Printer) IsStructured() bool {
+	return printer.format == FormatJSON || printer.format == FormatYAML
+}
+
+// Print writes the value in the printer's format. JSON, YAML and templates all work on the
+// JSON form of the value, so they see the same field names; the table format uses the table.
+func (printer *Printer) Print(w io.Writer, value interface{}, table Table) error {
+	switch printer.format {
+	case FormatJSON:
+		jsonBuffer, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsonBuffer))
+		return err
+	case FormatYAML:
+		generic, err := toGeneric(value)
+		if err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(generic); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatTable:
+		return WriteTable(w, table)
+	}
+	generic, err := toGeneric(value)
+	if err != nil {
+		return err
+	}
+	if err := printer.template.Execute(w, generic); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	// Like kubectl, don't add a newline; templates can end with {{"\n"}} if they need one.
+	return nil
+}
+
+// toGeneric converts the value into the maps and slices it would be decoded to from JSON.
+func toGeneric(value interface{}) (interface{}, error) {
+	jsonBuffer, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(jsonBuffer, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// WriteTable writes the table with aligned columns.
+func WriteTable(w io.Writer, table Table) error {
+	writer := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(table.Headers, "\t"))
+	for _, row := range table.Rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// FormatSize formats a number of bytes with binary units, as in "1.5 GiB".
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	prefixes := "KMGTPE"
+	i := 0
+	for ; value >= unit && i < len(prefixes)-1; i++ {
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f %ciB", value, prefixes[i])
+}
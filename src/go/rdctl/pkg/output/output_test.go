@@ -0,0 +1,151 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestPrinter(t *testing.T) {
+	value := []testItem{{"one", 1}, {"two", 2}}
+	table := Table{Headers: []string{"NAME", "COUNT"}, Rows: [][]string{{"one", "1"}, {"two", "2"}}}
+	testCases := []struct {
+		spec     string
+		expected string
+	}{
+		{FormatJSON, "[\n  {\n    \"name\": \"one\",\n    \"count\": 1\n  },\n  {\n    \"name\": \"two\",\n    \"count\": 2\n  }\n]\n"},
+		{FormatYAML, "- count: 1\n  name: one\n- count: 2\n  name: two\n"},
+		{FormatTable, "NAME    COUNT\none     1\ntwo     2\n"},
+		{`go-template={{range .}}{{.name}}={{.count}} {{end}}`, "one=1 two=2 "},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.spec, func(t *testing.T) {
+			printer, err := NewPrinter(testCase.spec)
+			require.NoError(t, err)
+			var buffer bytes.Buffer
+			require.NoError(t, printer.Print(&buffer, value, table))
+			assert.Equal(t, testCase.expected, buffer.String())
+		})
+	}
+}
+
+func TestNewPrinterErrors(t *testing.T) {
+	_, err := NewPrinter("xml")
+	assert.ErrorContains(t, err, "invalid output format")
+	_, err = NewPrinter("go-template={{.name")
+	assert.ErrorContains(t, err, "invalid go-template")
+}
+
+func TestTemplateMissingKey(t *testing.T) {
+	printer, err := NewPrinter("go-template={{.missing}}")
+	require.NoError(t, err)
+	err = printer.Print(&bytes.Buffer{}, testItem{"one", 1}, Table{})
+	assert.Error(t, err)
+}
+
+func TestIsStructured(t *testing.T) {
+	for spec, expected := range map[string]bool{
+		FormatJSON:              true,
+		FormatYAML:              true,
+		FormatTable:             false,
+		"go-template={{.name}}": false,
+	} {
+		printer, err := NewPrinter(spec)
+		require.NoError(t, err)
+		assert.Equal(t, expected, printer.IsStructured(), spec)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{errors.New("boom"), CodeError},
+		{fmt.Errorf("wrapped: %w", client.ErrConnectionRefused), CodeConnectionRefused},
+		{&client.StatusError{StatusCode: 400, Message: "bad"}, CodeBadRequest},
+		{&client.StatusError{StatusCode: 401, Message: "no"}, CodeUnauthorized},
+		{&client.StatusError{StatusCode: 413, Message: "big"}, CodePayloadTooLarge},
+		{&client.StatusError{StatusCode: 500, Message: "oops"}, CodeServerError},
+		{&client.StatusError{StatusCode: 418, Message: "teapot"}, CodeUnexpectedResponse},
+		{fmt.Errorf("failed: %w", snapshot.ErrNameExists), CodeSnapshotExists},
+		{fmt.Errorf("failed: %w", snapshot.ErrIncompleteSnapshot), CodeSnapshotIncomplete},
+		{fmt.Errorf("failed: %w", os.ErrNotExist), CodeNotFound},
+		{fmt.Errorf("failed: %w", os.ErrPermission), CodePermissionDenied},
+	}
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, ErrorCode(testCase.err), testCase.err.Error())
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, WriteError(&buffer, fmt.Errorf("failed to get settings: %w", client.ErrConnectionRefused)))
+	assert.Equal(t, `{"error":"failed to get settings: `+client.ErrConnectionRefused.Error()+`","code":"connection_refused"}`+"\n", buffer.String())
+}
+
+func TestWritePrettyJSON(t *testing.T) {
+	data := []byte(`{"a":[1,true,null],"b":"x\"y"}`)
+	t.Run("plain", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, WritePrettyJSON(&buffer, data, false))
+		assert.Equal(t, "{\n  \"a\": [\n    1,\n    true,\n    null\n  ],\n  \"b\": \"x\\\"y\"\n}\n", buffer.String())
+	})
+	t.Run("color", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, WritePrettyJSON(&buffer, []byte(`{"a":null,"b":"x\"y","c":-1.5}`), true))
+		expected := "{\n  " +
+			colorKey + `"a"` + colorReset + ": " + colorNull + "null" + colorReset + ",\n  " +
+			colorKey + `"b"` + colorReset + ": " + colorString + `"x\"y"` + colorReset + ",\n  " +
+			colorKey + `"c"` + colorReset + ": " + colorLiteral + "-1.5" + colorReset + "\n}\n"
+		assert.Equal(t, expected, buffer.String())
+	})
+	t.Run("not JSON", func(t *testing.T) {
+		var buffer bytes.Buffer
+		require.NoError(t, WritePrettyJSON(&buffer, []byte("plain text"), true))
+		assert.Equal(t, "plain text", buffer.String())
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	testCases := map[int64]string{
+		0:        "0 B",
+		1023:     "1023 B",
+		1024:     "1.0 KiB",
+		1536:     "1.5 KiB",
+		10 << 20: "10.0 MiB",
+		3 << 30:  "3.0 GiB",
+		5 << 40:  "5.0 TiB",
+	}
+	for size, expected := range testCases {
+		assert.Equal(t, expected, FormatSize(size), size)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin finds and runs rdctl plugins: executables named `rdctl-<name>` that
+// are run for `rdctl <name>` when there is no built-in command of that name.
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+)
+
+// Prefix is the start of the file name of every plugin.
+const Prefix = "rdctl-"
+
+// ErrNotFound is returned when there is no plugin with the requested name.
+var ErrNotFound = errors.New("plugin not found")
+
+// Plugin is an executable found in one of the plugin directories.
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Dirs returns the directories searched for plugins, in order: `~/.rd/libexec`,
+// and then the directories in PATH.
+func Dirs() []string {
+	var dirs []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".rd", "libexec"))
+	}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// List returns the plugins in the directories, sorted by name. When several plugins have
+// the same name, only the first one found is run; the others are returned as shadowed.
+func List(dirs []string) (plugins []Plugin, shadowed []Plugin) {
+	found := map[string]bool{}
+	seenDirs := map[string]bool{}
+	for _, dir := range dirs {
+		if seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			if found[name] {
+				shadowed = append(shadowed, Plugin{Name: name, Path: path})
+			} else {
+				found[name] = true
+				plugins = append(plugins, Plugin{Name: name, Path: path})
+			}
+		}
+	}
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	sort.SliceStable(shadowed, func(i, j int) bool { return shadowed[i].Name < shadowed[j].Name })
+	return plugins, shadowed
+}
+
+// Find returns the path of the plugin with the given name.
+func Find(name string, dirs []string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", ErrNotFound
+	}
+	plugins, _ := List(dirs)
+	for _, plugin := range plugins {
+		if plugin.Name == name {
+			return plugin.Path, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Environment returns the environment variables that tell a plugin how to connect to
+// the command API server, so that it can use the connection settings rdctl resolved
+// from its config file, context and flags.
+func Environment(connectionInfo *config.ConnectionInfo) []string {
+	if connectionInfo == nil {
+		return nil
+	}
+	values := []struct{ name, value string }{
+		{"RD_CONNECTION_HOST", connectionInfo.Host},
+		{"RD_CONNECTION_PORT", connectionInfo.Port},
+		{"RD_CONNECTION_USER", connectionInfo.User},
+		{"RD_CONNECTION_PASSWORD", connectionInfo.Password},
+		{"RD_CONNECTION_SOCKET", connectionInfo.SocketPath},
+		{"RD_CONNECTION_SCHEME", connectionInfo.Scheme},
+		{"RD_CONNECTION_CACERT", connectionInfo.CACert},
+		{"RD_CONNECTION_CLIENT_CERT", connectionInfo.ClientCert},
+		{"RD_CONNECTION_CLIENT_KEY", connectionInfo.ClientKey},
+		{"RD_CONNECTION_PINNED_CERT_SHA256", connectionInfo.PinnedCertSHA256},
+	}
+	var env []string
+	for _, value := range values {
+		if value.value != "" {
+			env = append(env, value.name+"="+value.value)
+		}
+	}
+	return env
+}
+
+// Run runs the plugin with the arguments, connected to rdctl's standard streams, and returns
+// its exit code. The plugin gets rdctl's environment, plus the connection settings, and
+// `RDCTL`, the path of the rdctl executable.
+func Run(path string, args []string, connectionInfo *config.ConnectionInfo) (int, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), Environment(connectionInfo)...)
+	if executable, err := os.Executable(); err == nil {
+		cmd.Env = append(cmd.Env, "RDCTL="+executable)
+	}
+	err := cmd.Run()
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) {
+		return exitError.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, fmt.Errorf("failed to run plugin %s: %w", path, err)
+	}
+	return 0, nil
+}
@@ -0,0 +1,82 @@
+//go:build !windows
+
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, mode os.FileMode) {
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexit $1\n"), mode))
+}
+
+func TestList(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+	writeFile(t, filepath.Join(first, "rdctl-b"), 0o755)
+	writeFile(t, filepath.Join(first, "rdctl-not-executable"), 0o644)
+	writeFile(t, filepath.Join(first, "not-a-plugin"), 0o755)
+	writeFile(t, filepath.Join(first, "rdctl-"), 0o755)
+	require.NoError(t, os.Mkdir(filepath.Join(first, "rdctl-directory"), 0o755))
+	writeFile(t, filepath.Join(second, "rdctl-a"), 0o755)
+	writeFile(t, filepath.Join(second, "rdctl-b"), 0o755)
+
+	plugins, shadowed := List([]string{first, second, first, filepath.Join(first, "missing")})
+	assert.Equal(t, []Plugin{
+		{Name: "a", Path: filepath.Join(second, "rdctl-a")},
+		{Name: "b", Path: filepath.Join(first, "rdctl-b")},
+	}, plugins)
+	assert.Equal(t, []Plugin{{Name: "b", Path: filepath.Join(second, "rdctl-b")}}, shadowed)
+
+	path, err := Find("b", []string{second, first})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(second, "rdctl-b"), path)
+	_, err = Find("not-executable", []string{first})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Find("../b", []string{second})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestEnvironment(t *testing.T) {
+	assert.Nil(t, Environment(nil))
+	env := Environment(&config.ConnectionInfo{Host: "localhost", Port: "6107", User: "user", Password: "secret"})
+	assert.Equal(t, []string{
+		"RD_CONNECTION_HOST=localhost",
+		"RD_CONNECTION_PORT=6107",
+		"RD_CONNECTION_USER=user",
+		"RD_CONNECTION_PASSWORD=secret",
+	}, env)
+}
+
+func TestRunExitCode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rdctl-exit")
+	writeFile(t, path, 0o755)
+	exitCode, err := Run(path, []string{"7"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 7, exitCode)
+
+	_, err = Run(filepath.Join(t.TempDir(), "rdctl-missing"), nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// executableExtensions returns the file extensions of executables, from PATHEXT.
+func executableExtensions() []string {
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".COM;.EXE;.BAT;.CMD"
+	}
+	return filepath.SplitList(strings.ToLower(pathExt))
+}
+
+// pluginName returns the name of the plugin in the named file, if it is one:
+// the file extension, which must be that of an executable, is not part of the name.
+func pluginName(fileName string) (string, bool) {
+	extension := strings.ToLower(filepath.Ext(fileName))
+	for _, executableExtension := range executableExtensions() {
+		if extension == executableExtension {
+			name, ok := strings.CutPrefix(strings.TrimSuffix(fileName, filepath.Ext(fileName)), Prefix)
+			return name, ok && name != ""
+		}
+	}
+	return "", false
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Marker identifies the registry script among the provisioning entries of the Lima
+// override.yaml, so that saving the configuration replaces it rather than adding another.
+const Marker = "# Managed by 'rdctl registry'; changes will be overwritten."
+
+const (
+	// K3sConfigPath is where k3s reads the registry configuration for containerd.
+	K3sConfigPath = "/etc/rancher/k3s/registries.yaml"
+	// DockerConfigPath is where dockerd reads its configuration.
+	DockerConfigPath = "/etc/docker/daemon.json"
+)
+
+type k3sMirror struct {
+	Endpoint []string `yaml:"endpoint"`
+}
+
+type k3sTLS struct {
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+type k3sRegistryConfig struct {
+	TLS k3sTLS `yaml:"tls"`
+}
+
+type k3sRegistries struct {
+	Mirrors map[string]k3sMirror         `yaml:"mirrors,omitempty"`
+	Configs map[string]k3sRegistryConfig `yaml:"configs,omitempty"`
+}
+
+type dockerDaemonConfig struct {
+	RegistryMirrors    []string `json:"registry-mirrors,omitempty"`
+	InsecureRegistries []string `json:"insecure-registries,omitempty"`
+}
+
+// K3sConfig returns the contents of registries.yaml for k3s, or an empty string if nothing
+// is configured. Insecure registries skip certificate verification and, unless they have
+// mirrors, fall back to plain HTTP.
+func (config *Config) K3sConfig() (string, error) {
+	registries := k3sRegistries{Mirrors: map[string]k3sMirror{}, Configs: map[string]k3sRegistryConfig{}}
+	for name, endpoints := range config.Mirrors {
+		registries.Mirrors[name] = k3sMirror{Endpoint: endpoints}
+	}
+	for _, host := range config.Insecure {
+		registries.Configs[host] = k3sRegistryConfig{TLS: k3sTLS{InsecureSkipVerify: true}}
+		if _, ok := registries.Mirrors[host]; !ok {
+			registries.Mirrors[host] = k3sMirror{Endpoint: []string{"https://" + host, "http://" + host}}
+		}
+	}
+	if len(registries.Mirrors) == 0 {
+		return "", nil
+	}
+	contents, err := marshalYAML(registries)
+	return string(contents), err
+}
+
+// DockerConfig returns the contents of daemon.json for dockerd, or an empty string if
+// nothing is configured. dockerd only uses mirrors for Docker Hub.
+func (config *Config) DockerConfig() (string, error) {
+	daemonConfig := dockerDaemonConfig{
+		RegistryMirrors:    config.Mirrors[DockerHub],
+		InsecureRegistries: config.Insecure,
+	}
+	if len(daemonConfig.RegistryMirrors) == 0 && len(daemonConfig.InsecureRegistries) == 0 {
+		return "", nil
+	}
+	contents, err := json.MarshalIndent(daemonConfig, "", "  ")
+	return string(contents) + "\n", err
+}
+
+// installConfigFunction is a shell function that writes its standard input to the file
+// named by its argument, or removes the file if the input is empty. A copy of what it
+// wrote is kept next to the file, so that a file written by something else, such as
+// a daemon.json made by hand, is left alone.
+const installConfigFunction = `install_config() {
+  new="$(cat)"
+  if [ -e "$1" ] && ! cmp -s "$1" "$1.rdctl"; then
+    echo "rdctl registry: not replacing $1, which was not written by rdctl" >&2
+    return 0
+  fi
+  if [ -z "$new" ]; then
+    rm -f "$1" "$1.rdctl"
+    return 0
+  fi
+  mkdir -p "$(dirname "$1")"
+  printf '%s\n' "$new" > "$1"
+  cp "$1" "$1.rdctl"
+}
+`
+
+// Script returns the shell script that writes the registry configuration of k3s and
+// dockerd in the VM, replacing the one written before. With nothing configured, it
+// removes the files it wrote.
+func Script(config *Config) (string, error) {
+	k3sConfig, err := config.K3sConfig()
+	if err != nil {
+		return "", err
+	}
+	dockerConfig, err := config.DockerConfig()
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	builder.WriteString("#!/bin/sh\n")
+	builder.WriteString(Marker + "\n")
+	builder.WriteString("set -o errexit\n")
+	builder.WriteString(installConfigFunction)
+	for _, file := range []struct{ path, contents string }{
+		{K3sConfigPath, k3sConfig},
+		{DockerConfigPath, dockerConfig},
+	} {
+		fmt.Fprintf(&builder, "install_config %s <<'EOF'\n%sEOF\n", file.path, file.contents)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,293 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry keeps the registry mirrors and insecure registries configured with
+// `rdctl registry`, and generates the provisioning script that writes them into the
+// configuration of k3s and dockerd in the VM when it starts.
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/limaconfig"
+	"gopkg.in/yaml.v3"
+)
+
+// DockerHub is the name the configuration uses for Docker Hub, whatever it is called on the command line.
+const DockerHub = "docker.io"
+
+// dockerHubAliases are the other names Docker Hub goes by.
+var dockerHubAliases = []string{"index.docker.io", "registry-1.docker.io"}
+
+// Config is the declarative registry configuration, as stored in registries.yaml in the
+// config directory.
+type Config struct {
+	// Mirrors maps a registry name, like docker.io or registry.example.com:5000, to the
+	// URLs of its mirrors, in the order they are tried.
+	Mirrors map[string][]string `yaml:"mirrors,omitempty" json:"mirrors"`
+	// Insecure lists the registries, as host[:port], that are used without verifying
+	// their certificates, or over plain HTTP.
+	Insecure []string `yaml:"insecure,omitempty" json:"insecure"`
+}
+
+// Path returns the path of the registry configuration.
+func Path(configDir string) string {
+	return filepath.Join(configDir, "registries.yaml")
+}
+
+// Load reads the registry configuration; a missing file is an empty configuration.
+func Load(path string) (*Config, error) {
+	config := &Config{}
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(contents, config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Save writes the registry configuration.
+func (config *Config) Save(path string) error {
+	contents, err := marshalYAML(config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return limaconfig.WriteFile(path, contents)
+}
+
+// Validate returns the problems in a configuration that may have been edited by hand.
+func (config *Config) Validate() []error {
+	var problems []error
+	for _, name := range sortedKeys(config.Mirrors) {
+		if normalized, err := NormalizeRegistry(name); err != nil {
+			problems = append(problems, fmt.Errorf("mirrors: %w", err))
+		} else if normalized != name {
+			problems = append(problems, fmt.Errorf("mirrors: registry %q should be written as %q", name, normalized))
+		}
+		for _, endpoint := range config.Mirrors[name] {
+			if _, err := NormalizeMirrorURL(endpoint); err != nil {
+				problems = append(problems, fmt.Errorf("mirrors: %s: %w", name, err))
+			}
+		}
+	}
+	for _, host := range config.Insecure {
+		if _, err := NormalizeHost(host); err != nil {
+			problems = append(problems, fmt.Errorf("insecure: %w", err))
+		}
+	}
+	return problems
+}
+
+// NormalizeHost checks a registry given as host[:port], and returns it in lower case.
+func NormalizeHost(host string) (string, error) {
+	name, port := strings.ToLower(host), ""
+	if strings.Contains(name, "/") || strings.Contains(name, "@") {
+		return "", fmt.Errorf("registry %q should be a host name with an optional port, such as registry.example.com:5000", host)
+	}
+	if splitName, splitPort, err := net.SplitHostPort(name); err == nil {
+		name, port = splitName, splitPort
+		if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+			return "", fmt.Errorf("registry %q has an invalid port %q", host, port)
+		}
+	}
+	if !validHostName(name) && net.ParseIP(name) == nil {
+		return "", fmt.Errorf("registry %q should be a host name with an optional port, such as registry.example.com:5000", host)
+	}
+	if port == "" {
+		return name, nil
+	}
+	return net.JoinHostPort(name, port), nil
+}
+
+func validHostName(name string) bool {
+	if name == "" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '.' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeRegistry checks a registry name, and returns it as it is used in the
+// configuration, with Docker Hub always called docker.io.
+func NormalizeRegistry(name string) (string, error) {
+	normalized, err := NormalizeHost(name)
+	if err != nil {
+		return "", err
+	}
+	for _, alias := range dockerHubAliases {
+		if normalized == alias {
+			return DockerHub, nil
+		}
+	}
+	return normalized, nil
+}
+
+// NormalizeMirrorURL checks the URL of a mirror, which dockerd and containerd only accept
+// as a scheme and a host, and returns it without a trailing slash.
+func NormalizeMirrorURL(raw string) (string, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid mirror URL %q: %w", raw, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("mirror URL %q must start with http:// or https://", raw)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("mirror URL %q has no host", raw)
+	}
+	if parsed.User != nil {
+		return "", fmt.Errorf("mirror URL %q must not contain credentials; use 'docker login' instead", raw)
+	}
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("mirror URL %q must not have a path, query or fragment", raw)
+	}
+	host, err := NormalizeHost(parsed.Host)
+	if err != nil {
+		return "", fmt.Errorf("mirror URL %q: %w", raw, err)
+	}
+	return parsed.Scheme + "://" + host, nil
+}
+
+// AddMirror adds a mirror for a registry, after the existing ones. It returns false if the
+// mirror had already been added.
+func (config *Config) AddMirror(registry, mirrorURL string) (bool, error) {
+	name, err := NormalizeRegistry(registry)
+	if err != nil {
+		return false, err
+	}
+	endpoint, err := NormalizeMirrorURL(mirrorURL)
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range config.Mirrors[name] {
+		if existing == endpoint {
+			return false, nil
+		}
+	}
+	if config.Mirrors == nil {
+		config.Mirrors = map[string][]string{}
+	}
+	config.Mirrors[name] = append(config.Mirrors[name], endpoint)
+	return true, nil
+}
+
+// RemoveMirror removes a mirror of a registry, or all its mirrors if the URL is empty.
+// It returns the mirrors that were removed.
+func (config *Config) RemoveMirror(registry, mirrorURL string) ([]string, error) {
+	name, err := NormalizeRegistry(registry)
+	if err != nil {
+		return nil, err
+	}
+	endpoints, ok := config.Mirrors[name]
+	if !ok {
+		return nil, fmt.Errorf("registry %s has no mirrors", name)
+	}
+	if mirrorURL == "" {
+		delete(config.Mirrors, name)
+		return endpoints, nil
+	}
+	endpoint, err := NormalizeMirrorURL(mirrorURL)
+	if err != nil {
+		return nil, err
+	}
+	for i, existing := range endpoints {
+		if existing != endpoint {
+			continue
+		}
+		endpoints = append(endpoints[:i:i], endpoints[i+1:]...)
+		if len(endpoints) == 0 {
+			delete(config.Mirrors, name)
+		} else {
+			config.Mirrors[name] = endpoints
+		}
+		return []string{endpoint}, nil
+	}
+	return nil, fmt.Errorf("%s is not a mirror of %s", endpoint, name)
+}
+
+// AddInsecure adds an insecure registry. It returns false if it had already been added.
+func (config *Config) AddInsecure(host string) (bool, error) {
+	normalized, err := NormalizeHost(host)
+	if err != nil {
+		return false, err
+	}
+	for _, existing := range config.Insecure {
+		if existing == normalized {
+			return false, nil
+		}
+	}
+	config.Insecure = append(config.Insecure, normalized)
+	sort.Strings(config.Insecure)
+	return true, nil
+}
+
+// RemoveInsecure removes an insecure registry.
+func (config *Config) RemoveInsecure(host string) error {
+	normalized, err := NormalizeHost(host)
+	if err != nil {
+		return err
+	}
+	for i, existing := range config.Insecure {
+		if existing == normalized {
+			config.Insecure = append(config.Insecure[:i:i], config.Insecure[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("registry %s has not been added as insecure", normalized)
+}
+
+// marshalYAML encodes the value with the indentation used by limaconfig.
+func marshalYAML(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
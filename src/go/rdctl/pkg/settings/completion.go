@@ -0,0 +1,97 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Names returns the dotted names of all the settings in the schema, including those of
+// the objects that contain other settings, in sorted order.
+func (schema *Schema) Names() []string {
+	var names []string
+	schema.collectNames("", &names)
+	sort.Strings(names)
+	return names
+}
+
+func (schema *Schema) collectNames(prefix string, names *[]string) {
+	for key, propertySchema := range schema.Properties {
+		name := joinPath(prefix, key)
+		*names = append(*names, name)
+		propertySchema.collectNames(name, names)
+	}
+}
+
+// Lookup returns the schema for the setting with the given dotted name, or nil if there is none.
+// Names can go into map-valued settings, such as `WSL.integrations.Ubuntu`.
+func (schema *Schema) Lookup(name string) *Schema {
+	current := schema
+	for _, part := range strings.Split(name, ".") {
+		current = current.PropertySchema(part)
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
+
+// IsLeaf reports whether the schema describes a single setting rather than a group of settings.
+// Map-valued settings, whose keys aren't known in advance, count as leaves.
+func (schema *Schema) IsLeaf() bool {
+	return schema.Type != "object" || len(schema.Properties) == 0
+}
+
+// Values returns the possible values of a setting, for settings that only allow a few.
+func (schema *Schema) Values() []string {
+	if schema.Type == "boolean" {
+		return []string{"true", "false"}
+	}
+	values := make([]string, len(schema.Enum))
+	for i, value := range schema.Enum {
+		values[i] = fmt.Sprintf("%v", value)
+	}
+	return values
+}
+
+// FlagName returns the name of the `rdctl set` flag for a setting. As when the flags are
+// generated, each run of capital letters following a lower-case letter is lower-cased and
+// preceded by a hyphen, so `virtualMachine.numberCPUs` becomes `virtual-machine.number-cpus`.
+func FlagName(name string) string {
+	var result strings.Builder
+	var previous rune
+	lowerCasing := false
+	for _, r := range name {
+		if !unicode.IsUpper(r) {
+			lowerCasing = false
+			result.WriteRune(r)
+		} else if unicode.IsLower(previous) || lowerCasing {
+			if !lowerCasing {
+				result.WriteRune('-')
+				lowerCasing = true
+			}
+			result.WriteRune(unicode.ToLower(r))
+		} else {
+			result.WriteRune(r)
+		}
+		previous = r
+	}
+	return result.String()
+}
@@ -0,0 +1,61 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaNames(t *testing.T) {
+	schema, err := ParseSchema(`{"type": "object", "properties": {
+		"b": {"type": "object", "properties": {"y": {"type": "string"}, "x": {"type": "boolean"}}},
+		"a": {"type": "object", "additionalProperties": true}
+	}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "b.x", "b.y"}, schema.Names())
+	assert.True(t, schema.Lookup("a").IsLeaf())
+	assert.False(t, schema.Lookup("b").IsLeaf())
+	assert.Equal(t, []string{"true", "false"}, schema.Lookup("b.x").Values())
+	assert.Empty(t, schema.Lookup("b.y").Values())
+	assert.Nil(t, schema.Lookup("b.z"))
+	assert.Nil(t, schema.Lookup("b.y.z"))
+	assert.NotNil(t, schema.Lookup("a.anything"))
+}
+
+func TestPreferencesSchemaValues(t *testing.T) {
+	schema, err := PreferencesSchema()
+	require.NoError(t, err)
+	engine := schema.Lookup("containerEngine.name")
+	require.NotNil(t, engine)
+	assert.ElementsMatch(t, []string{"containerd", "docker", "moby"}, engine.Values())
+}
+
+func TestFlagName(t *testing.T) {
+	testCases := map[string]string{
+		"kubernetes.version":                 "kubernetes.version",
+		"virtualMachine.numberCPUs":          "virtual-machine.number-cpus",
+		"virtualMachine.memoryInGB":          "virtual-machine.memory-in-gb",
+		"WSL.integrations":                   "WSL.integrations",
+		"application.pathManagementStrategy": "application.path-management-strategy",
+	}
+	for name, expected := range testCases {
+		assert.Equal(t, expected, FlagName(name), name)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	options "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+)
+
+// Source describes where the current value of a setting comes from.
+type Source string
+
+const (
+	// SourceDefault is used for values that match the built-in defaults.
+	SourceDefault Source = "default"
+	// SourceProfileDefault is used for values that match the defaults deployment profile.
+	SourceProfileDefault Source = "profile default"
+	// SourceLocked is used for values that are locked by the locked deployment profile.
+	SourceLocked Source = "locked"
+	// SourceUser is used for values that have been changed from the defaults.
+	SourceUser Source = "user"
+)
+
+// DefaultSettings returns the built-in default settings on the platform rdctl was built for.
+func DefaultSettings() (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(options.DefaultSettings), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse default settings: %w", err)
+	}
+	return doc, nil
+}
+
+// Explanation describes the current value of a single setting.
+type Explanation struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value"`
+	Source Source      `json:"source"`
+}
+
+// Explain works out where the value of each setting in current comes from.
+// The locked document is the tree returned by `GET /v1/settings/locked`, where a `true` leaf
+// locks that setting and everything under it. Settings are checked in order of precedence:
+// locked, then deployment profile defaults, then built-in defaults; anything else must have been
+// set by the user. Any of the documents other than current may be nil.
+// The result is sorted by setting name.
+func Explain(current, defaults, profileDefaults, locked map[string]interface{}) []Explanation {
+	flatDefaults := Flatten("", defaults)
+	flatProfileDefaults := Flatten("", profileDefaults)
+	flatCurrent := Flatten("", current)
+	delete(flatCurrent, "version")
+
+	var result []Explanation
+	for _, name := range sortedNames(flatCurrent) {
+		value := flatCurrent[name]
+		source := SourceUser
+		if defaultValue, ok := flatProfileDefaults[name]; IsLocked(locked, name) {
+			source = SourceLocked
+		} else if ok && Equal(value, defaultValue) {
+			source = SourceProfileDefault
+		} else if defaultValue, ok := flatDefaults[name]; ok && Equal(value, defaultValue) {
+			source = SourceDefault
+		}
+		result = append(result, Explanation{Name: name, Value: value, Source: source})
+	}
+	return result
+}
+
+// IsLocked reports whether the setting, or any object containing it, is marked as locked
+// in a document shaped like the one returned by `GET /v1/settings/locked`.
+func IsLocked(locked map[string]interface{}, name string) bool {
+	var current interface{} = locked
+	for _, part := range strings.Split(name, ".") {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if current = currentMap[part]; current == true {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedNames(flat map[string]interface{}) []string {
+	names := make([]string, 0, len(flat))
+	for name := range flat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
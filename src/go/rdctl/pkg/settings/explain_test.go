@@ -0,0 +1,81 @@
+package settings
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	current := map[string]interface{}{
+		"version": float64(10),
+		"containerEngine": map[string]interface{}{
+			"name": "containerd",
+			"allowedImages": map[string]interface{}{
+				"enabled":  true,
+				"patterns": []interface{}{"docker.io"},
+			},
+		},
+		"kubernetes": map[string]interface{}{
+			"enabled": false,
+			"version": "1.27.3",
+			"port":    float64(6443),
+		},
+	}
+	defaults := map[string]interface{}{
+		"containerEngine": map[string]interface{}{
+			"name": "moby",
+			"allowedImages": map[string]interface{}{
+				"enabled":  false,
+				"patterns": []interface{}{},
+			},
+		},
+		"kubernetes": map[string]interface{}{
+			"enabled": true,
+			"version": "",
+			"port":    float64(6443),
+		},
+	}
+	profileDefaults := map[string]interface{}{
+		"kubernetes": map[string]interface{}{"enabled": false, "port": float64(9443)},
+	}
+	locked := map[string]interface{}{
+		"containerEngine": map[string]interface{}{
+			"allowedImages": true,
+		},
+		"kubernetes": map[string]interface{}{"enabled": false},
+	}
+
+	assert.Equal(t, []Explanation{
+		{"containerEngine.allowedImages.enabled", true, SourceLocked},
+		{"containerEngine.allowedImages.patterns", []interface{}{"docker.io"}, SourceLocked},
+		{"containerEngine.name", "containerd", SourceUser},
+		{"kubernetes.enabled", false, SourceProfileDefault},
+		{"kubernetes.port", float64(6443), SourceDefault},
+		{"kubernetes.version", "1.27.3", SourceUser},
+	}, Explain(current, defaults, profileDefaults, locked))
+
+	t.Run("works without a deployment profile", func(t *testing.T) {
+		explanations := Explain(current, defaults, nil, nil)
+		require.Len(t, explanations, 6)
+		assert.Equal(t, SourceUser, explanations[0].Source)
+		assert.Equal(t, SourceUser, explanations[3].Source)
+	})
+}
+
+func TestDefaultSettings(t *testing.T) {
+	defaults, err := DefaultSettings()
+	require.NoError(t, err)
+	assert.Contains(t, defaults, "containerEngine")
+
+	// The defaults are those of the platform rdctl is built for, not the one it was generated on.
+	strategy, err := Get(defaults, "application.pathManagementStrategy")
+	require.NoError(t, err)
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, "manual", strategy)
+	} else {
+		assert.Equal(t, "rcfiles", strategy)
+	}
+}
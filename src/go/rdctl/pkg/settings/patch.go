@@ -0,0 +1,280 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ApplyMergePatch applies an RFC 7386 JSON merge patch to a copy of the document.
+func ApplyMergePatch(doc map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("failed to parse merge patch: %w", err)
+	}
+	patchMap, ok := patchValue.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("merge patch must be a JSON object")
+	}
+	return Merge(doc, patchMap), nil
+}
+
+// Merge applies an already-decoded merge patch to a copy of the document.
+func Merge(doc, patch map[string]interface{}) map[string]interface{} {
+	return mergePatch(DeepCopy(doc), DeepCopy(patch))
+}
+
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	for key, patchValue := range patch {
+		if patchValue == nil {
+			delete(target, key)
+			continue
+		}
+		patchMap, ok := patchValue.(map[string]interface{})
+		if !ok {
+			target[key] = patchValue
+			continue
+		}
+		targetMap, ok := target[key].(map[string]interface{})
+		if !ok {
+			targetMap = map[string]interface{}{}
+		}
+		target[key] = mergePatch(targetMap, patchMap)
+	}
+	return target
+}
+
+type jsonPatchOperation struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From string `json:"from"`
+	// Value is kept undecoded, so that a missing value can be told apart from null.
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON patch to a copy of the document.
+func ApplyJSONPatch(doc map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON patch: %w", err)
+	}
+	var result interface{} = DeepCopy(doc)
+	var err error
+	for i, operation := range operations {
+		result, err = applyOperation(result, operation)
+		if err != nil {
+			return nil, fmt.Errorf("JSON patch operation %d (%s %s): %w", i, operation.Op, operation.Path, err)
+		}
+	}
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("JSON patch replaced the settings document with a non-object")
+	}
+	return resultMap, nil
+}
+
+func applyOperation(doc interface{}, operation jsonPatchOperation) (interface{}, error) {
+	var value interface{}
+	switch operation.Op {
+	case "add", "replace", "test":
+		if len(operation.Value) == 0 {
+			return nil, fmt.Errorf("missing value")
+		}
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+	switch operation.Op {
+	case "add":
+		return addValue(doc, operation.Path, value, false)
+	case "replace":
+		return addValue(doc, operation.Path, value, true)
+	case "remove":
+		doc, _, err := removeValue(doc, operation.Path)
+		return doc, err
+	case "move":
+		if operation.Path == operation.From || strings.HasPrefix(operation.Path, operation.From+"/") {
+			return nil, fmt.Errorf("can't move %s into itself", operation.From)
+		}
+		doc, value, err := removeValue(doc, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, operation.Path, value, false)
+	case "copy":
+		value, err := lookupPointer(doc, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, operation.Path, deepCopyValue(value), false)
+	case "test":
+		current, err := lookupPointer(doc, operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(current, value) {
+			return nil, fmt.Errorf("test failed: value is %v", current)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unsupported operation %q", operation.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func lookupPointer(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, token := range tokens {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s not found", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %s: %w", pointer, err)
+			}
+			current = container[index]
+		default:
+			return nil, fmt.Errorf("path %s not found", pointer)
+		}
+	}
+	return current, nil
+}
+
+// addValue implements both `add` and `replace`; the latter requires that the target already exists.
+func addValue(doc interface{}, pointer string, value interface{}, mustExist bool) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := lookupPointer(doc, parentPointer(pointer))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := container[last]; mustExist && !ok {
+			return nil, fmt.Errorf("path %s not found", pointer)
+		}
+		container[last] = value
+		return doc, nil
+	case []interface{}:
+		var newArray []interface{}
+		if mustExist {
+			index, err := arrayIndex(last, len(container), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %s: %w", pointer, err)
+			}
+			container[index] = value
+			return doc, nil
+		}
+		index, err := arrayIndex(last, len(container), true)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %w", pointer, err)
+		}
+		newArray = append(newArray, container[:index]...)
+		newArray = append(newArray, value)
+		newArray = append(newArray, container[index:]...)
+		return addValue(doc, parentPointer(pointer), newArray, true)
+	}
+	return nil, fmt.Errorf("path %s: parent is not an object or array", pointer)
+}
+
+func removeValue(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("can't remove the whole document")
+	}
+	parent, err := lookupPointer(doc, parentPointer(pointer))
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		value, ok := container[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %s not found", pointer)
+		}
+		delete(container, last)
+		return doc, value, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(container), false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("path %s: %w", pointer, err)
+		}
+		value := container[index]
+		newArray := append(append([]interface{}{}, container[:index]...), container[index+1:]...)
+		doc, err = addValue(doc, parentPointer(pointer), newArray, true)
+		return doc, value, err
+	}
+	return nil, nil, fmt.Errorf("path %s: parent is not an object or array", pointer)
+}
+
+func parentPointer(pointer string) string {
+	return pointer[:strings.LastIndex(pointer, "/")]
+}
+
+// arrayIndex parses an array reference token; `-` (the end of the array) is only valid when adding.
+func arrayIndex(token string, length int, forAdd bool) (int, error) {
+	if token == "-" && forAdd {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	limit := length - 1
+	if forAdd {
+		limit = length
+	}
+	if index > limit {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
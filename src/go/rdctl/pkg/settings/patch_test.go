@@ -0,0 +1,75 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := loadTestSettings(t)
+	result, err := ApplyMergePatch(doc, []byte(`{"kubernetes": {"version": "1.28.1", "options": null}, "images": {"showAll": true}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"version": "1.28.1", "port": float64(6443)}, result["kubernetes"])
+	assert.Equal(t, map[string]interface{}{"showAll": true}, result["images"])
+	assert.Equal(t, "1.27.3", doc["kubernetes"].(map[string]interface{})["version"], "original document must not change")
+
+	_, err = ApplyMergePatch(doc, []byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	t.Run("applies all operations", func(t *testing.T) {
+		doc := loadTestSettings(t)
+		result, err := ApplyJSONPatch(doc, []byte(`[
+			{"op": "test", "path": "/kubernetes/version", "value": "1.27.3"},
+			{"op": "replace", "path": "/kubernetes/version", "value": "1.28.1"},
+			{"op": "add", "path": "/containerEngine/allowedImages/patterns/-", "value": "quay.io"},
+			{"op": "add", "path": "/containerEngine/allowedImages/patterns/0", "value": "ghcr.io"},
+			{"op": "copy", "from": "/WSL/integrations/Ubuntu", "path": "/WSL/integrations/Debian"},
+			{"op": "move", "from": "/WSL/integrations/Ubuntu", "path": "/WSL/integrations/Ubuntu~122.04"},
+			{"op": "remove", "path": "/containerEngine/allowedImages/patterns/1"}
+		]`))
+		require.NoError(t, err)
+		flat := Flatten("", result)
+		assert.Equal(t, "1.28.1", flat["kubernetes.version"])
+		assert.Equal(t, []interface{}{"ghcr.io", "quay.io"}, flat["containerEngine.allowedImages.patterns"])
+		assert.Equal(t, true, flat["WSL.integrations.Debian"])
+		assert.Equal(t, true, flat["WSL.integrations.Ubuntu/22.04"])
+		assert.NotContains(t, flat, "WSL.integrations.Ubuntu")
+		assert.Equal(t, []interface{}{"docker.io"}, Flatten("", doc)["containerEngine.allowedImages.patterns"], "original document must not change")
+	})
+	t.Run("takes null as a value", func(t *testing.T) {
+		doc := loadTestSettings(t)
+		result, err := ApplyJSONPatch(doc, []byte(`[
+			{"op": "add", "path": "/kubernetes/flavor", "value": null},
+			{"op": "test", "path": "/kubernetes/flavor", "value": null},
+			{"op": "replace", "path": "/kubernetes/version", "value": null}
+		]`))
+		require.NoError(t, err)
+		kubernetes := result["kubernetes"].(map[string]interface{})
+		assert.Contains(t, kubernetes, "flavor")
+		assert.Nil(t, kubernetes["flavor"])
+		assert.Nil(t, kubernetes["version"])
+
+		_, err = ApplyJSONPatch(doc, []byte(`[{"op": "test", "path": "/kubernetes/version", "value": null}]`))
+		assert.ErrorContains(t, err, "test failed")
+	})
+	t.Run("reports failing operations", func(t *testing.T) {
+		doc := loadTestSettings(t)
+		testCases := map[string]string{
+			`[{"op": "test", "path": "/kubernetes/version", "value": "1.0.0"}]`:       "test failed",
+			`[{"op": "replace", "path": "/kubernetes/flavor", "value": "k3s"}]`:       "not found",
+			`[{"op": "remove", "path": "/containerEngine/allowedImages/patterns/5"}]`: "out of range",
+			`[{"op": "add", "path": "kubernetes", "value": {}}]`:                      "must start with '/'",
+			`[{"op": "add", "path": "/kubernetes/port"}]`:                             "missing value",
+			`[{"op": "frobnicate", "path": "/kubernetes"}]`:                           "unsupported operation",
+			`[{"op": "move", "from": "/kubernetes", "path": "/kubernetes/options"}]`:  "into itself",
+		}
+		for patch, message := range testCases {
+			_, err := ApplyJSONPatch(doc, []byte(patch))
+			assert.ErrorContains(t, err, message, patch)
+		}
+	})
+}
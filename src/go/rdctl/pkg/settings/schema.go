@@ -0,0 +1,231 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	options "github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/options/generated"
+)
+
+// Schema is the subset of an OpenAPI schema object used by the preferences in command-api.yaml.
+type Schema struct {
+	Type       string             `json:"type"`
+	Properties map[string]*Schema `json:"properties"`
+	// AdditionalProperties is either a boolean or a schema for the values of a map-valued setting.
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	Items                *Schema         `json:"items"`
+	Enum                 []interface{}   `json:"enum"`
+	Minimum              *float64        `json:"minimum"`
+	// Aliases are other names of the command-line flag of the setting, such as --container-engine.
+	Aliases []string `json:"x-rd-aliases"`
+}
+
+// UnknownSetting is the message of a ValidationError for a name that isn't in the schema.
+const UnknownSetting = "unknown setting"
+
+// ValidationError describes a value in a settings document that doesn't match the schema.
+type ValidationError struct {
+	// Path is the dotted name of the offending value, with list indexes in brackets.
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ParseSchema decodes a JSON schema.
+func ParseSchema(schemaJSON string) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// PreferencesSchema returns the schema for the settings document, as generated from command-api.yaml.
+func PreferencesSchema() (*Schema, error) {
+	return ParseSchema(options.PreferencesSchema)
+}
+
+// TransientSettingsSchema returns the schema for the transient settings document, as generated from command-api.yaml.
+func TransientSettingsSchema() (*Schema, error) {
+	return ParseSchema(options.TransientSettingsSchema)
+}
+
+// PropertySchema returns the schema for the value of a key in an object, or nil if the key isn't allowed.
+func (schema *Schema) PropertySchema(key string) *Schema {
+	if propertySchema := schema.Properties[key]; propertySchema != nil {
+		return propertySchema
+	}
+	return schema.additionalPropertiesSchema()
+}
+
+// additionalPropertiesSchema returns the schema for values of a map-valued object, or nil if it has none.
+// A value of `true` allows any value.
+func (schema *Schema) additionalPropertiesSchema() *Schema {
+	if len(schema.AdditionalProperties) == 0 {
+		return nil
+	}
+	var allowed bool
+	if err := json.Unmarshal(schema.AdditionalProperties, &allowed); err == nil {
+		if allowed {
+			return &Schema{}
+		}
+		return nil
+	}
+	var valueSchema Schema
+	if err := json.Unmarshal(schema.AdditionalProperties, &valueSchema); err != nil {
+		return nil
+	}
+	return &valueSchema
+}
+
+// Validate checks a settings document against the schema, returning all the problems found
+// in order of their paths. The top-level `version` field is not part of the preferences
+// schema, and only needs to be an integer.
+func (schema *Schema) Validate(doc map[string]interface{}) []ValidationError {
+	var errors []ValidationError
+	if version, ok := doc["version"]; ok {
+		if !isInteger(version) {
+			errors = append(errors, ValidationError{"version", fmt.Sprintf("expected an integer, got %s", describe(version))})
+		}
+		doc = shallowCopyWithout(doc, "version")
+	}
+	errors = append(errors, schema.validate("", doc)...)
+	sortErrors(errors)
+	return errors
+}
+
+// ValidateValue checks a decoded JSON value against the schema, returning all the problems
+// found in order of their paths.
+func (schema *Schema) ValidateValue(value interface{}) []ValidationError {
+	errors := schema.validate("", value)
+	sortErrors(errors)
+	return errors
+}
+
+func sortErrors(errors []ValidationError) {
+	sort.SliceStable(errors, func(i, j int) bool { return errors[i].Path < errors[j].Path })
+}
+
+func (schema *Schema) validate(path string, value interface{}) []ValidationError {
+	fail := func(format string, args ...interface{}) []ValidationError {
+		return []ValidationError{{path, fmt.Sprintf(format, args...)}}
+	}
+	switch schema.Type {
+	case "":
+		// No type restriction, as with `additionalProperties: true`
+		return nil
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("expected an object, got %s", describe(value))
+		}
+		var errors []ValidationError
+		for key, item := range object {
+			itemSchema := schema.PropertySchema(key)
+			if itemSchema == nil {
+				errors = append(errors, ValidationError{joinPath(path, key), UnknownSetting})
+				continue
+			}
+			errors = append(errors, itemSchema.validate(joinPath(path, key), item)...)
+		}
+		return errors
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fail("expected a list, got %s", describe(value))
+		}
+		var errors []ValidationError
+		if schema.Items != nil {
+			for i, item := range array {
+				errors = append(errors, schema.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+		return errors
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fail("expected a string, got %s", describe(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("expected a boolean, got %s", describe(value))
+		}
+	case "integer":
+		if !isInteger(value) {
+			return fail("expected an integer, got %s", describe(value))
+		}
+		if schema.Minimum != nil && value.(float64) < *schema.Minimum {
+			return fail("%v is less than the minimum of %v", value, *schema.Minimum)
+		}
+	default:
+		return fail("internal error: unsupported schema type %q", schema.Type)
+	}
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if Equal(allowed, value) {
+				return nil
+			}
+		}
+		allowedValues := make([]string, len(schema.Enum))
+		for i, allowed := range schema.Enum {
+			allowedValues[i] = fmt.Sprintf("%v", allowed)
+		}
+		return fail("invalid value %s; must be one of [%s]", describe(value), strings.Join(allowedValues, ", "))
+	}
+	return nil
+}
+
+func isInteger(value interface{}) bool {
+	number, ok := value.(float64)
+	return ok && number == math.Trunc(number)
+}
+
+func describe(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+func shallowCopyWithout(doc map[string]interface{}, key string) map[string]interface{} {
+	result := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		if k != key {
+			result[k] = v
+		}
+	}
+	return result
+}
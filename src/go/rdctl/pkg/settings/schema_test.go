@@ -0,0 +1,91 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	schema, err := PreferencesSchema()
+	require.NoError(t, err)
+
+	t.Run("accepts valid settings", func(t *testing.T) {
+		assert.Empty(t, schema.Validate(loadTestSettings(t)))
+	})
+	t.Run("reports the path of each problem", func(t *testing.T) {
+		doc, err := ParseDocument("settings.yaml", []byte(`
+version: ten
+kubernetes:
+  port: "6443"
+  enabled: yes
+  flavor: k3s
+containerEngine:
+  name: podman
+  allowedImages:
+    patterns: [docker.io, 5]
+virtualMachine:
+  memoryInGB: 0
+WSL:
+  integrations:
+    Ubuntu: true
+application: []
+`))
+		require.NoError(t, err)
+		messages := []string{}
+		for _, validationError := range schema.Validate(doc) {
+			messages = append(messages, validationError.Error())
+		}
+		assert.Equal(t, []string{
+			"application: expected an object, got a list",
+			"containerEngine.allowedImages.patterns[1]: expected a string, got 5",
+			"containerEngine.name: invalid value \"podman\"; must be one of [containerd, docker, moby]",
+			"kubernetes.enabled: expected a boolean, got \"yes\"",
+			"kubernetes.flavor: unknown setting",
+			"kubernetes.port: expected an integer, got \"6443\"",
+			"version: expected an integer, got \"ten\"",
+			"virtualMachine.memoryInGB: 0 is less than the minimum of 1",
+		}, messages)
+	})
+}
+
+func TestParseDocument(t *testing.T) {
+	t.Run("parses JSON", func(t *testing.T) {
+		doc, err := ParseDocument("settings.json", []byte(`{"kubernetes": {"port": 6443}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"kubernetes": map[string]interface{}{"port": float64(6443)}}, doc)
+	})
+	t.Run("parses YAML with the same types as JSON", func(t *testing.T) {
+		doc, err := ParseDocument("settings.yaml", []byte("kubernetes:\n  port: 6443\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"kubernetes": map[string]interface{}{"port": float64(6443)}}, doc)
+	})
+	t.Run("rejects documents that aren't objects", func(t *testing.T) {
+		_, err := ParseDocument("settings.yaml", []byte("- kubernetes\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateTransientSettings(t *testing.T) {
+	schema, err := TransientSettingsSchema()
+	require.NoError(t, err)
+
+	doc := map[string]interface{}{
+		"noModalDialogs": true,
+		"preferences": map[string]interface{}{
+			"navItem": map[string]interface{}{
+				"current":     "Kubernetes",
+				"currentTabs": map[string]interface{}{"Kubernetes": "general"},
+			},
+		},
+	}
+	assert.Empty(t, schema.Validate(doc))
+
+	doc["noModalDialogs"] = "yes"
+	require.NoError(t, Set(doc, "preferences.navItem.current", "Volumes"))
+	assert.Equal(t, []ValidationError{
+		{"noModalDialogs", `expected a boolean, got "yes"`},
+		{"preferences.navItem.current", `invalid value "Volumes"; must be one of [Application, WSL, Virtual Machine, Container Engine, Kubernetes]`},
+	}, schema.Validate(doc))
+}
@@ -0,0 +1,286 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package settings works with settings documents as returned by `GET /v1/settings`:
+// looking up and assigning values by dotted path, applying patches, and computing
+// the partial document that takes the server from one set of settings to another.
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SplitPath breaks a dotted setting name like `kubernetes.options.traefik` into its parts.
+func SplitPath(path string) ([]string, error) {
+	if path == "" {
+		return nil, fmt.Errorf("setting name must not be empty")
+	}
+	parts := strings.Split(path, ".")
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("invalid setting name %q: empty name component", path)
+		}
+	}
+	return parts, nil
+}
+
+// Get returns the value at the dotted path in the document.
+func Get(doc map[string]interface{}, path string) (interface{}, error) {
+	parts, err := SplitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var current interface{} = doc
+	for i, part := range parts {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("setting %q: %q is not an object", path, strings.Join(parts[:i], "."))
+		}
+		current, ok = currentMap[part]
+		if !ok {
+			return nil, fmt.Errorf("setting %q not found", path)
+		}
+	}
+	return current, nil
+}
+
+// Set assigns the value at the dotted path in the document, creating any missing intermediate objects.
+func Set(doc map[string]interface{}, path string, value interface{}) error {
+	parts, err := SplitPath(path)
+	if err != nil {
+		return err
+	}
+	current := doc
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := current[part]
+		if !ok || next == nil {
+			next = map[string]interface{}{}
+			current[part] = next
+		}
+		nextMap, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("setting %q: %q is not an object", path, strings.Join(parts[:i+1], "."))
+		}
+		current = nextMap
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// ParseAssignment splits a `name=value` command-line argument.
+func ParseAssignment(assignment string) (string, string, error) {
+	name, value, found := strings.Cut(assignment, "=")
+	if !found {
+		return "", "", fmt.Errorf("invalid assignment %q: expected NAME=VALUE", assignment)
+	}
+	return name, value, nil
+}
+
+// ParseValue converts a command-line string into a value of the same type as the current value
+// of the setting. Lists can be given as a JSON array or as comma-separated strings.
+// When there is no current value (e.g. a new key in a map-valued setting), the string is
+// treated as JSON if it parses as JSON, and as a plain string otherwise.
+func ParseValue(name, raw string, current interface{}) (interface{}, error) {
+	switch current.(type) {
+	case bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for setting %s: %q is not a boolean", name, raw)
+		}
+		return value, nil
+	case float64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for setting %s: %q is not an integer", name, raw)
+		}
+		return value, nil
+	case string:
+		return raw, nil
+	case []interface{}:
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			var value []interface{}
+			if err := json.Unmarshal([]byte(raw), &value); err != nil {
+				return nil, fmt.Errorf("invalid value for setting %s: %q is not a JSON array: %w", name, raw, err)
+			}
+			return value, nil
+		}
+		value := []interface{}{}
+		if raw != "" {
+			for _, item := range strings.Split(raw, ",") {
+				value = append(value, item)
+			}
+		}
+		return value, nil
+	case map[string]interface{}:
+		var value map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			return nil, fmt.Errorf("invalid value for setting %s: %q is not a JSON object: %w", name, raw, err)
+		}
+		return value, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return raw, nil
+	}
+	return value, nil
+}
+
+// Flatten converts a nested settings document into a map of dotted names to leaf values.
+// Empty objects are dropped, as they don't specify any settings.
+func Flatten(prefix string, doc map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range doc {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		if inner, ok := value.(map[string]interface{}); ok {
+			for innerName, innerValue := range Flatten(name, inner) {
+				result[innerName] = innerValue
+			}
+		} else {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// Diff returns the partial document holding only the values in desired that differ from current.
+// Lists are compared, and sent, as a whole. Settings can't be removed through the API,
+// so a value that is in current but not in desired is an error.
+func Diff(current, desired map[string]interface{}) (map[string]interface{}, error) {
+	return diff("", current, desired)
+}
+
+func diff(prefix string, current, desired map[string]interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for key := range current {
+		if _, ok := desired[key]; !ok {
+			return nil, fmt.Errorf("can't remove setting %s: settings can only be changed", joinPath(prefix, key))
+		}
+	}
+	for key, desiredValue := range desired {
+		currentValue, ok := current[key]
+		if !ok {
+			result[key] = desiredValue
+			continue
+		}
+		currentMap, currentIsMap := currentValue.(map[string]interface{})
+		desiredMap, desiredIsMap := desiredValue.(map[string]interface{})
+		if currentIsMap && desiredIsMap {
+			inner, err := diff(joinPath(prefix, key), currentMap, desiredMap)
+			if err != nil {
+				return nil, err
+			}
+			if len(inner) > 0 {
+				result[key] = inner
+			}
+		} else if !Equal(currentValue, desiredValue) {
+			result[key] = desiredValue
+		}
+	}
+	return result, nil
+}
+
+// Equal reports whether two decoded JSON values are the same, treating all numbers alike.
+func Equal(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// normalize converts the integer types produced by ParseValue into float64, as produced by json.Unmarshal.
+func normalize(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case int:
+		return float64(typedValue)
+	case int64:
+		return float64(typedValue)
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			result[i] = normalize(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			result[key] = normalize(item)
+		}
+		return result
+	}
+	return value
+}
+
+// DeepCopy returns a copy of a decoded JSON document that shares no maps or slices with the original.
+func DeepCopy(doc map[string]interface{}) map[string]interface{} {
+	return deepCopyValue(doc).(map[string]interface{})
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			result[i] = deepCopyValue(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			result[key] = deepCopyValue(item)
+		}
+		return result
+	}
+	return value
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// ParseDocument decodes a settings document in either JSON or YAML format.
+// JSON is used for files named `*.json` and for documents that start with `{`.
+func ParseDocument(filename string, contents []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	if strings.EqualFold(filepath.Ext(filename), ".json") || strings.HasPrefix(strings.TrimSpace(string(contents)), "{") {
+		if err := json.Unmarshal(contents, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s as JSON: %w", filename, err)
+		}
+		return doc, nil
+	}
+	var yamlDoc interface{}
+	if err := yaml.Unmarshal(contents, &yamlDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s as YAML: %w", filename, err)
+	}
+	// Round-trip through JSON so that the document has the same types as one decoded from JSON.
+	jsonBuffer, err := json.Marshal(yamlDoc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to JSON: %w", filename, err)
+	}
+	if err := json.Unmarshal(jsonBuffer, &doc); err != nil {
+		return nil, fmt.Errorf("%s does not contain a settings object: %w", filename, err)
+	}
+	return doc, nil
+}
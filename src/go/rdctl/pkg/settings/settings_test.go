@@ -0,0 +1,140 @@
+package settings
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSettings = `{
+  "version": 10,
+  "containerEngine": {
+    "name": "moby",
+    "allowedImages": { "enabled": false, "patterns": ["docker.io"] }
+  },
+  "kubernetes": { "version": "1.27.3", "port": 6443, "options": { "traefik": true } },
+  "WSL": { "integrations": { "Ubuntu": true } }
+}`
+
+func loadTestSettings(t *testing.T) map[string]interface{} {
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(testSettings), &doc))
+	return doc
+}
+
+func TestGet(t *testing.T) {
+	doc := loadTestSettings(t)
+	t.Run("finds leaf values", func(t *testing.T) {
+		value, err := Get(doc, "kubernetes.version")
+		assert.NoError(t, err)
+		assert.Equal(t, "1.27.3", value)
+	})
+	t.Run("finds objects", func(t *testing.T) {
+		value, err := Get(doc, "kubernetes.options")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"traefik": true}, value)
+	})
+	t.Run("complains about missing settings", func(t *testing.T) {
+		_, err := Get(doc, "kubernetes.flavor")
+		assert.ErrorContains(t, err, `setting "kubernetes.flavor" not found`)
+	})
+	t.Run("complains about descending into scalars", func(t *testing.T) {
+		_, err := Get(doc, "kubernetes.version.major")
+		assert.ErrorContains(t, err, `"kubernetes.version" is not an object`)
+	})
+	t.Run("rejects empty components", func(t *testing.T) {
+		_, err := Get(doc, "kubernetes..version")
+		assert.Error(t, err)
+	})
+}
+
+func TestSet(t *testing.T) {
+	doc := map[string]interface{}{}
+	assert.NoError(t, Set(doc, "kubernetes.options.traefik", false))
+	assert.Equal(t, map[string]interface{}{
+		"kubernetes": map[string]interface{}{"options": map[string]interface{}{"traefik": false}},
+	}, doc)
+	assert.Error(t, Set(doc, "kubernetes.options.traefik.enabled", true))
+}
+
+func TestParseValue(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		current  interface{}
+		expected interface{}
+		isError  bool
+	}{
+		{"true", false, true, false},
+		{"yes", false, nil, true},
+		{"8", float64(4), int64(8), false},
+		{"8GB", float64(4), nil, true},
+		{"1.27.3", "1.26.1", "1.27.3", false},
+		{"true", "", "true", false},
+		{"a,b", []interface{}{}, []interface{}{"a", "b"}, false},
+		{"", []interface{}{"a"}, []interface{}{}, false},
+		{`["a", "b,c"]`, []interface{}{}, []interface{}{"a", "b,c"}, false},
+		{`{"Ubuntu": false}`, map[string]interface{}{}, map[string]interface{}{"Ubuntu": false}, false},
+		{"Ubuntu", map[string]interface{}{}, nil, true},
+		{"true", nil, true, false},
+		{"plain", nil, "plain", false},
+	}
+	for _, testCase := range testCases {
+		value, err := ParseValue("test", testCase.raw, testCase.current)
+		if testCase.isError {
+			assert.Error(t, err, "parsing %q", testCase.raw)
+		} else if assert.NoError(t, err, "parsing %q", testCase.raw) {
+			assert.Equal(t, testCase.expected, value, "parsing %q", testCase.raw)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := loadTestSettings(t)
+	t.Run("returns only changed values", func(t *testing.T) {
+		desired := DeepCopy(current)
+		require.NoError(t, Set(desired, "kubernetes.port", int64(6444)))
+		require.NoError(t, Set(desired, "WSL.integrations.Debian", false))
+		require.NoError(t, Set(desired, "containerEngine.allowedImages.enabled", false))
+		changes, err := Diff(current, desired)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"kubernetes": map[string]interface{}{"port": int64(6444)},
+			"WSL":        map[string]interface{}{"integrations": map[string]interface{}{"Debian": false}},
+		}, changes)
+	})
+	t.Run("sends whole lists", func(t *testing.T) {
+		desired := DeepCopy(current)
+		require.NoError(t, Set(desired, "containerEngine.allowedImages.patterns", []interface{}{"docker.io", "quay.io"}))
+		changes, err := Diff(current, desired)
+		assert.NoError(t, err)
+		assert.Equal(t, []interface{}{"docker.io", "quay.io"}, Flatten("", changes)["containerEngine.allowedImages.patterns"])
+	})
+	t.Run("treats integer types alike", func(t *testing.T) {
+		desired := DeepCopy(current)
+		require.NoError(t, Set(desired, "kubernetes.port", int64(6443)))
+		changes, err := Diff(current, desired)
+		assert.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+	t.Run("refuses to remove settings", func(t *testing.T) {
+		desired := DeepCopy(current)
+		delete(desired["kubernetes"].(map[string]interface{}), "port")
+		_, err := Diff(current, desired)
+		assert.ErrorContains(t, err, "can't remove setting kubernetes.port")
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{
+		"version":                                float64(10),
+		"containerEngine.name":                   "moby",
+		"containerEngine.allowedImages.enabled":  false,
+		"containerEngine.allowedImages.patterns": []interface{}{"docker.io"},
+		"kubernetes.version":                     "1.27.3",
+		"kubernetes.port":                        float64(6443),
+		"kubernetes.options.traefik":             true,
+		"WSL.integrations.Ubuntu":                true,
+	}, Flatten("", loadTestSettings(t)))
+}
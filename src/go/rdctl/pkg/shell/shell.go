@@ -0,0 +1,211 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shell builds the command lines used to run commands in the Rancher Desktop VM,
+// through `limactl shell` on macOS and Linux, and through `wsl --exec` on Windows.
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// LimaInstance is the name lima knows the Rancher Desktop VM by.
+	LimaInstance = "0"
+	// WSLDistro is the WSL distribution the Rancher Desktop VM runs in.
+	WSLDistro = "rancher-desktop"
+	// WSLExec runs a command in the namespace of the WSL distribution's init process.
+	WSLExec = "/usr/local/bin/wsl-exec"
+)
+
+// defaultShell runs the user's shell in the VM, for when options have to be applied
+// but no command was given.
+var defaultShell = []string{"/bin/sh", "-c", `exec "${SHELL:-/bin/sh}"`}
+
+// Options change how a command is run in the VM.
+type Options struct {
+	// Workdir is the directory in the VM to run the command in; when empty,
+	// the VM's default directory is used.
+	Workdir string
+	// Env holds KEY=VALUE assignments to add to the environment of the command.
+	Env []string
+	// User is the user to run the command as; when empty, the default user is used.
+	User string
+}
+
+// ExitCode returns the exit code a shell would report for the command: its own exit code,
+// or 128 plus the signal number when it was killed by a signal.
+func ExitCode(exitError *exec.ExitError) int {
+	if status, ok := exitError.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return exitError.ExitCode()
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnv converts `--env` arguments into KEY=VALUE assignments. A plain KEY takes
+// its value from the host environment, and is skipped when it isn't set there.
+func ParseEnv(args []string, lookupEnv func(string) (string, bool)) ([]string, error) {
+	var result []string
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable %q: expected KEY=VALUE or KEY", arg)
+		}
+		if !found {
+			if value, found = lookupEnv(name); !found {
+				continue
+			}
+		}
+		result = append(result, name+"="+value)
+	}
+	return result, nil
+}
+
+// guestCommand wraps the command so it runs with the environment and as the user in the options.
+// Lima logs in as a user that can use sudo; the WSL distribution runs commands as root, and has su.
+func guestCommand(opts Options, args []string, useSudo bool) []string {
+	if len(args) == 0 && (opts.User != "" || len(opts.Env) > 0) {
+		args = defaultShell
+	}
+	var command []string
+	if opts.User != "" && useSudo {
+		// sudo resets the environment, so the variables are set after it.
+		command = append(command, "sudo", "--user", opts.User, "--set-home", "--")
+	}
+	if len(opts.Env) > 0 {
+		command = append(command, "env")
+		command = append(command, opts.Env...)
+	}
+	if opts.User != "" && !useSudo {
+		// su keeps the environment, apart from HOME, SHELL, USER and LOGNAME.
+		command = append(command, "su", opts.User, "-c", `exec "$0" "$@"`)
+	}
+	return append(command, args...)
+}
+
+// LimaArgs returns the arguments to limactl that run the command in the VM.
+func LimaArgs(opts Options, args []string) []string {
+	limaArgs := []string{"shell"}
+	if opts.Workdir != "" {
+		limaArgs = append(limaArgs, "--workdir", opts.Workdir)
+	}
+	limaArgs = append(limaArgs, LimaInstance)
+	return append(limaArgs, guestCommand(opts, args, true)...)
+}
+
+// WSLArgs returns the arguments to wsl.exe that run the command in the VM.
+func WSLArgs(opts Options, args []string) []string {
+	wslArgs := []string{"--distribution", WSLDistro, "--exec", WSLExec}
+	command := guestCommand(opts, args, false)
+	if opts.Workdir != "" {
+		if len(command) == 0 {
+			command = defaultShell
+		}
+		// wsl --cd would be lost when wsl-exec enters the namespace of init.
+		wslArgs = append(wslArgs, "/bin/sh", "-c", `cd "$1" || exit; shift; exec "$@"`, "rdctl-shell", opts.Workdir)
+	}
+	return append(wslArgs, command...)
+}
+
+// Mount is a host directory shared with the VM.
+type Mount struct {
+	// Location is the directory on the host.
+	Location string `yaml:"location"`
+	// MountPoint is where the directory is mounted in the VM; when empty, it is the same as Location.
+	MountPoint string `yaml:"mountPoint"`
+}
+
+// LimaMounts returns the directories shared with the VM, as configured in lima.yaml
+// and override.yaml in limaHome. A `~` at the start of a location is the host home directory.
+func LimaMounts(limaHome, homeDir string) ([]Mount, error) {
+	var mounts []Mount
+	for _, configPath := range []string{
+		filepath.Join(limaHome, "_config", "override.yaml"),
+		filepath.Join(limaHome, LimaInstance, "lima.yaml"),
+	} {
+		contents, err := os.ReadFile(configPath)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+		}
+		var limaConfig struct {
+			Mounts []Mount `yaml:"mounts"`
+		}
+		if err := yaml.Unmarshal(contents, &limaConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+		}
+		for _, mount := range limaConfig.Mounts {
+			mount.Location = expandHome(mount.Location, homeDir)
+			if mount.MountPoint == "" {
+				mount.MountPoint = mount.Location
+			}
+			mounts = append(mounts, mount)
+		}
+	}
+	return mounts, nil
+}
+
+func expandHome(location, homeDir string) string {
+	if location == "~" {
+		return homeDir
+	}
+	if strings.HasPrefix(location, "~/") {
+		return filepath.Join(homeDir, location[2:])
+	}
+	return location
+}
+
+// TranslatePath returns the path in the VM of a host path that is in one of the mounts.
+// Mounts from override.yaml come first, and take precedence, as they do in lima.
+func TranslatePath(hostPath string, mounts []Mount) (string, bool) {
+	for _, mount := range mounts {
+		relPath, err := filepath.Rel(mount.Location, hostPath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(filepath.Join(mount.MountPoint, relPath)), true
+	}
+	return "", false
+}
+
+var windowsDrivePattern = regexp.MustCompile(`^([A-Za-z]):(?:[\\/]|$)`)
+
+// WSLPath returns the path in WSL of a path on a Windows drive, which is mounted
+// under /mnt; paths that aren't on a drive, like UNC paths, aren't shared.
+func WSLPath(hostPath string) (string, bool) {
+	match := windowsDrivePattern.FindStringSubmatch(hostPath)
+	if match == nil {
+		return "", false
+	}
+	rest := strings.Trim(strings.ReplaceAll(hostPath[2:], "\\", "/"), "/")
+	wslPath := "/mnt/" + strings.ToLower(match[1])
+	if rest != "" {
+		wslPath += "/" + rest
+	}
+	return wslPath, true
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shell
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	testCases := map[string]int{
+		"exit 3":        3,
+		"kill -TERM $$": 128 + 15,
+		"kill -KILL $$": 128 + 9,
+	}
+	for script, expected := range testCases {
+		err := exec.Command("/bin/sh", "-c", script).Run()
+		var exitError *exec.ExitError
+		require.True(t, errors.As(err, &exitError), script)
+		assert.Equal(t, expected, ExitCode(exitError), script)
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	lookupEnv := func(name string) (string, bool) {
+		if name == "FROM_HOST" {
+			return "host value", true
+		}
+		return "", false
+	}
+	env, err := ParseEnv([]string{"A=1", "B=", "C=x=y", "FROM_HOST", "UNSET"}, lookupEnv)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A=1", "B=", "C=x=y", "FROM_HOST=host value"}, env)
+
+	for _, arg := range []string{"", "=1", "1A=2", "A-B=3"} {
+		_, err := ParseEnv([]string{arg}, lookupEnv)
+		assert.Error(t, err, arg)
+	}
+}
+
+func TestLimaArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     Options
+		args     []string
+		expected []string
+	}{
+		{"plain", Options{}, []string{"ls", "-l"}, []string{"shell", "0", "ls", "-l"}},
+		{"interactive", Options{}, nil, []string{"shell", "0"}},
+		{
+			"workdir",
+			Options{Workdir: "/Users/me/src"},
+			[]string{"pwd"},
+			[]string{"shell", "--workdir", "/Users/me/src", "0", "pwd"},
+		},
+		{
+			"env and user",
+			Options{Env: []string{"A=1"}, User: "root"},
+			[]string{"id"},
+			[]string{"shell", "0", "sudo", "--user", "root", "--set-home", "--", "env", "A=1", "id"},
+		},
+		{
+			"interactive as user",
+			Options{User: "root"},
+			nil,
+			append([]string{"shell", "0", "sudo", "--user", "root", "--set-home", "--"}, defaultShell...),
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, LimaArgs(testCase.opts, testCase.args))
+		})
+	}
+}
+
+func TestWSLArgs(t *testing.T) {
+	prefix := []string{"--distribution", WSLDistro, "--exec", WSLExec}
+	cd := []string{"/bin/sh", "-c", `cd "$1" || exit; shift; exec "$@"`, "rdctl-shell"}
+	testCases := []struct {
+		name     string
+		opts     Options
+		args     []string
+		expected []string
+	}{
+		{"plain", Options{}, []string{"ls"}, []string{"ls"}},
+		{"interactive", Options{}, nil, nil},
+		{"workdir", Options{Workdir: "/mnt/c/src"}, []string{"pwd"}, append(append(cd, "/mnt/c/src"), "pwd")},
+		{"interactive in workdir", Options{Workdir: "/mnt/c/src"}, nil, append(append(cd, "/mnt/c/src"), defaultShell...)},
+		{
+			"env and user",
+			Options{Env: []string{"A=1"}, User: "nobody"},
+			[]string{"id"},
+			[]string{"env", "A=1", "su", "nobody", "-c", `exec "$0" "$@"`, "id"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, append(append([]string{}, prefix...), testCase.expected...), WSLArgs(testCase.opts, testCase.args))
+		})
+	}
+}
+
+func TestLimaMounts(t *testing.T) {
+	limaHome := t.TempDir()
+	write := func(name, contents string) {
+		path := filepath.Join(limaHome, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	}
+	mounts, err := LimaMounts(limaHome, "/home/me")
+	require.NoError(t, err)
+	assert.Empty(t, mounts)
+
+	write("0/lima.yaml", "mounts:\n- location: \"~\"\n  writable: true\n- location: /tmp/rancher-desktop\n")
+	write("_config/override.yaml", "mounts:\n- location: ~/data\n  mountPoint: /data\n")
+	mounts, err = LimaMounts(limaHome, "/home/me")
+	require.NoError(t, err)
+	assert.Equal(t, []Mount{
+		{Location: filepath.Join("/home/me", "data"), MountPoint: "/data"},
+		{Location: "/home/me", MountPoint: "/home/me"},
+		{Location: "/tmp/rancher-desktop", MountPoint: "/tmp/rancher-desktop"},
+	}, mounts)
+
+	write("0/lima.yaml", "mounts: [")
+	_, err = LimaMounts(limaHome, "/home/me")
+	assert.Error(t, err)
+}
+
+func TestTranslatePath(t *testing.T) {
+	mounts := []Mount{
+		{Location: filepath.FromSlash("/home/me/data"), MountPoint: "/data"},
+		{Location: filepath.FromSlash("/home/me"), MountPoint: "/home/me"},
+	}
+	testCases := map[string]string{
+		"/home/me":            "/home/me",
+		"/home/me/src/rd":     "/home/me/src/rd",
+		"/home/me/data":       "/data",
+		"/home/me/data/x/y":   "/data/x/y",
+		"/home/me2":           "",
+		"/home/mine/../other": "",
+		"/":                   "",
+		"/tmp":                "",
+	}
+	for hostPath, expected := range testCases {
+		vmPath, ok := TranslatePath(filepath.FromSlash(hostPath), mounts)
+		assert.Equal(t, expected != "", ok, hostPath)
+		assert.Equal(t, expected, vmPath, hostPath)
+	}
+}
+
+func TestWSLPath(t *testing.T) {
+	testCases := map[string]string{
+		`C:\`:                   "/mnt/c",
+		`C:`:                    "/mnt/c",
+		`c:\Users\me\src\`:      "/mnt/c/Users/me/src",
+		`D:/data/x`:             "/mnt/d/data/x",
+		`\\server\share\dir`:    "",
+		`C:relative`:            "",
+		`/already/a/linux/path`: "",
+	}
+	for hostPath, expected := range testCases {
+		wslPath, ok := WSLPath(hostPath)
+		assert.Equal(t, expected != "", ok, hostPath)
+		assert.Equal(t, expected, wslPath, hostPath)
+	}
+}
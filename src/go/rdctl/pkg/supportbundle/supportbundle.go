@@ -0,0 +1,283 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supportbundle collects the information needed for a bug report into a
+// gzipped tar file, with passwords, tokens and other credentials redacted.
+// VM disks and SSH keys are never included: only the files listed here are collected.
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+)
+
+// Sources are where the contents of the bundle come from.
+type Sources struct {
+	Paths paths.Paths
+	// Client is used to query the running app; when it is nil, the API output is left out.
+	Client client.RDClient
+	// ClientError explains why there is no client, if there isn't one.
+	ClientError error
+}
+
+// Skipped is an item that couldn't be added to the bundle.
+type Skipped struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Manifest describes the bundle; it is included in it as `manifest.json`.
+type Manifest struct {
+	Created       time.Time `json:"created"`
+	ClientVersion string    `json:"clientVersion"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	Files         []string  `json:"files"`
+	Skipped       []Skipped `json:"skipped,omitempty"`
+}
+
+// apiEndpoints are the API responses included in the bundle, with the files they are stored in.
+var apiEndpoints = []struct {
+	command string
+	name    string
+}{
+	{"about", "api/about.txt"},
+	{"backend_state", "api/backend_state.json"},
+	{"diagnostic_checks", "api/diagnostic_checks.json"},
+}
+
+// DefaultFileName is the name of a bundle created at the given time, when no name is given.
+func DefaultFileName(now time.Time) string {
+	return fmt.Sprintf("rancher-desktop-support-%s.tar.gz", now.Format("20060102-150405"))
+}
+
+type bundleWriter struct {
+	tarWriter *tar.Writer
+	root      string
+	modTime   time.Time
+	manifest  Manifest
+}
+
+// Create writes a bundle to w. Items that can't be collected, such as the API output
+// when the app isn't running, are listed as skipped in the manifest rather than failing.
+func Create(w io.Writer, sources Sources, now time.Time) (Manifest, error) {
+	gzipWriter := gzip.NewWriter(w)
+	bundle := &bundleWriter{
+		tarWriter: tar.NewWriter(gzipWriter),
+		root:      strings.TrimSuffix(DefaultFileName(now), ".tar.gz"),
+		modTime:   now,
+		manifest: Manifest{
+			Created:       now,
+			ClientVersion: client.Version,
+			OS:            runtime.GOOS,
+			Arch:          runtime.GOARCH,
+			Files:         []string{},
+		},
+	}
+	if err := bundle.collect(sources); err != nil {
+		return bundle.manifest, err
+	}
+	manifest, err := json.MarshalIndent(bundle.manifest, "", "  ")
+	if err != nil {
+		return bundle.manifest, err
+	}
+	if err := bundle.write("manifest.json", append(manifest, '\n')); err != nil {
+		return bundle.manifest, err
+	}
+	if err := bundle.tarWriter.Close(); err != nil {
+		return bundle.manifest, err
+	}
+	return bundle.manifest, gzipWriter.Close()
+}
+
+func (b *bundleWriter) collect(sources Sources) error {
+	appPaths := sources.Paths
+	pathsJSON, err := json.MarshalIndent(appPaths, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := b.add("paths.json", append(pathsJSON, '\n')); err != nil {
+		return err
+	}
+	if err := b.addFile("settings.json", filepath.Join(appPaths.Config, "settings.json"), RedactJSON); err != nil {
+		return err
+	}
+	if appPaths.Lima != "" {
+		if err := b.addFile("lima/override.yaml", filepath.Join(appPaths.Lima, "_config", "override.yaml"), RedactYAML); err != nil {
+			return err
+		}
+		if err := b.addFile("lima/lima.yaml", filepath.Join(appPaths.Lima, "0", "lima.yaml"), RedactYAML); err != nil {
+			return err
+		}
+	}
+	if err := b.addLogs(appPaths.Logs); err != nil {
+		return err
+	}
+	if err := b.addSnapshotMetadata(appPaths.Snapshots); err != nil {
+		return err
+	}
+	for _, endpoint := range apiEndpoints {
+		if err := b.addAPIOutput(sources, endpoint.command, endpoint.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bundleWriter) skip(name string, reason error) {
+	b.manifest.Skipped = append(b.manifest.Skipped, Skipped{Name: name, Reason: reason.Error()})
+}
+
+// add writes a file to the bundle and lists it in the manifest.
+func (b *bundleWriter) add(name string, contents []byte) error {
+	b.manifest.Files = append(b.manifest.Files, name)
+	return b.write(name, contents)
+}
+
+func (b *bundleWriter) write(name string, contents []byte) error {
+	header := &tar.Header{
+		Name:    path.Join(b.root, name),
+		Mode:    0o600,
+		Size:    int64(len(contents)),
+		ModTime: b.modTime,
+	}
+	if err := b.tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to the bundle: %w", name, err)
+	}
+	if _, err := b.tarWriter.Write(contents); err != nil {
+		return fmt.Errorf("failed to write %s to the bundle: %w", name, err)
+	}
+	return nil
+}
+
+// addFile adds a redacted copy of a file, recording it as skipped if it can't be read.
+func (b *bundleWriter) addFile(name, filePath string, redact func([]byte) []byte) error {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		b.skip(name, err)
+		return nil
+	}
+	return b.add(name, redact(contents))
+}
+
+// addLogs adds the log files, decompressing rotated ones.
+func (b *bundleWriter) addLogs(logsDir string) error {
+	var names []string
+	err := filepath.WalkDir(logsDir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			names = append(names, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		b.skip("logs", err)
+		return nil
+	}
+	sort.Strings(names)
+	for _, filePath := range names {
+		relativePath, err := filepath.Rel(logsDir, filePath)
+		if err != nil {
+			return err
+		}
+		name := path.Join("logs", filepath.ToSlash(relativePath))
+		contents, err := readLogFile(filePath)
+		if err != nil {
+			b.skip(name, err)
+			continue
+		}
+		if err := b.add(strings.TrimSuffix(name, ".gz"), []byte(RedactText(string(contents)))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLogFile(filePath string) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if !strings.HasSuffix(filePath, ".gz") {
+		return io.ReadAll(file)
+	}
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s: %w", filePath, err)
+	}
+	return io.ReadAll(gzipReader)
+}
+
+// addSnapshotMetadata adds the metadata of each snapshot, but nothing else from the snapshot directories,
+// which hold disk images and SSH keys.
+func (b *bundleWriter) addSnapshotMetadata(snapshotsDir string) error {
+	entries, err := os.ReadDir(snapshotsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		b.skip("snapshots", err)
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := path.Join("snapshots", entry.Name(), "metadata.json")
+		if err := b.addFile(name, filepath.Join(snapshotsDir, entry.Name(), "metadata.json"), RedactJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAPIOutput adds the response to a GET request to the API of the running app.
+func (b *bundleWriter) addAPIOutput(sources Sources, command, name string) error {
+	if sources.Client == nil {
+		reason := sources.ClientError
+		if reason == nil {
+			reason = errors.New("no connection to Rancher Desktop")
+		}
+		b.skip(name, reason)
+		return nil
+	}
+	response, err := client.ProcessRequestForUtility(sources.Client.DoRequest("GET", client.VersionCommand("", command)))
+	if err != nil {
+		b.skip(name, err)
+		return nil
+	}
+	if strings.HasSuffix(name, ".json") {
+		return b.add(name, RedactJSON(response))
+	}
+	return b.add(name, []byte(RedactText(string(response))))
+}
@@ -0,0 +1,120 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/client"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/fakeserver"
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/paths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, filePath, contents string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	require.NoError(t, os.WriteFile(filePath, []byte(contents), 0o600))
+}
+
+// readBundle returns the contents of the files in a bundle, by their names within its top-level directory.
+func readBundle(t *testing.T, bundle []byte) map[string]string {
+	t.Helper()
+	gzipReader, err := gzip.NewReader(bytes.NewReader(bundle))
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	files := map[string]string{}
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		contents, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		_, name, _ := strings.Cut(header.Name, "/")
+		files[name] = string(contents)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	appPaths := paths.Paths{
+		Config:    filepath.Join(root, "config"),
+		Logs:      filepath.Join(root, "logs"),
+		Lima:      filepath.Join(root, "lima"),
+		Snapshots: filepath.Join(root, "snapshots"),
+	}
+	writeFile(t, filepath.Join(appPaths.Config, "settings.json"), `{"experimental": {"virtualMachine": {"proxy": {"password": "hunter2"}}}}`)
+	writeFile(t, filepath.Join(appPaths.Lima, "0", "lima.yaml"), "cpus: 2\n")
+	writeFile(t, filepath.Join(appPaths.Lima, "0", "diffdisk"), "disk")
+	writeFile(t, filepath.Join(appPaths.Lima, "_config", "user"), "private key")
+	writeFile(t, filepath.Join(appPaths.Logs, "background.log"), "2023-08-01T12:00:00.000Z: --password hunter2\n")
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	_, err := gzipWriter.Write([]byte("rotated\n"))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+	writeFile(t, filepath.Join(appPaths.Logs, "k3s.log.1.gz"), compressed.String())
+	writeFile(t, filepath.Join(appPaths.Snapshots, "id1", "metadata.json"), `{"name": "first"}`)
+	writeFile(t, filepath.Join(appPaths.Snapshots, "id1", "diffdisk"), "disk")
+	writeFile(t, filepath.Join(appPaths.Snapshots, "id1", "user"), "private key")
+
+	server, err := fakeserver.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	var bundle bytes.Buffer
+	manifest, err := Create(&bundle, Sources{Paths: appPaths, Client: client.NewRDClient(server.ConnectionInfo())}, now)
+	require.NoError(t, err)
+	assert.Equal(t, "rancher-desktop-support-20230801-120000.tar.gz", DefaultFileName(now))
+
+	files := readBundle(t, bundle.Bytes())
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"manifest.json",
+		"paths.json",
+		"settings.json",
+		"lima/lima.yaml",
+		"logs/background.log",
+		"logs/k3s.log.1",
+		"snapshots/id1/metadata.json",
+		"api/about.txt",
+		"api/backend_state.json",
+	}, names)
+	assert.ElementsMatch(t, names, append(manifest.Files, "manifest.json"))
+	assert.NotContains(t, strings.Join(mapValues(files), "\n"), "hunter2")
+	assert.Equal(t, "2023-08-01T12:00:00.000Z: --password REDACTED\n", files["logs/background.log"])
+	assert.Equal(t, "rotated\n", files["logs/k3s.log.1"])
+	assert.Equal(t, fakeserver.AboutText, files["api/about.txt"])
+	assert.Contains(t, files["manifest.json"], `"name": "lima/override.yaml"`)
+	assert.Contains(t, files["manifest.json"], `"name": "api/diagnostic_checks.json"`)
+
+	t.Run("without a running app", func(t *testing.T) {
+		var bundle bytes.Buffer
+		manifest, err := Create(&bundle, Sources{Paths: appPaths, ClientError: errors.New("no config file")}, now)
+		require.NoError(t, err)
+		assert.NotContains(t, manifest.Files, "api/about.txt")
+		assert.Contains(t, manifest.Skipped, Skipped{Name: "api/about.txt", Reason: "no config file"})
+	})
+}
+
+func mapValues(files map[string]string) []string {
+	var values []string
+	for _, value := range files {
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,342 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vmcopy copies files between the host and the Rancher Desktop VM as tar archives,
+// which are streamed through the same command path as `rdctl shell`.
+package vmcopy
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// VMPrefix marks a path as being in the VM rather than on the host.
+const VMPrefix = "vm:"
+
+// Location is a source or destination of a copy.
+type Location struct {
+	Path string
+	// VM is set when the path is in the VM.
+	VM bool
+}
+
+// ParseLocation parses a command-line path, which is in the VM when it starts with `vm:`.
+func ParseLocation(arg string) (Location, error) {
+	if !strings.HasPrefix(arg, VMPrefix) {
+		if arg == "" {
+			return Location{}, fmt.Errorf("path must not be empty")
+		}
+		return Location{Path: arg}, nil
+	}
+	vmPath := strings.TrimPrefix(arg, VMPrefix)
+	if vmPath == "" {
+		return Location{}, fmt.Errorf("path in the VM must not be empty: %q", arg)
+	}
+	return Location{Path: vmPath, VM: true}, nil
+}
+
+// Progress is called while the contents of a file are copied, with the number of bytes
+// copied so far and the size of the file.
+type Progress func(name string, copied, total int64)
+
+// Target is where a copy is written: the entry at the top of the archive is extracted
+// into Dir with the name Name.
+type Target struct {
+	Dir  string
+	Name string
+}
+
+// ResolveTarget works out where to copy srcName to when the destination is dst,
+// following cp -r: into dst when it is an existing directory, and as dst otherwise.
+// A destination ending with a slash must be an existing directory.
+func ResolveTarget(dst, srcName string, isDir, exists bool, split func(string) (string, string)) (Target, error) {
+	if isDir {
+		return Target{Dir: dst, Name: srcName}, nil
+	}
+	if strings.HasSuffix(dst, "/") || strings.HasSuffix(dst, string(filepath.Separator)) {
+		if exists {
+			return Target{}, fmt.Errorf("%s is not a directory", dst)
+		}
+		return Target{}, fmt.Errorf("directory %s does not exist", dst)
+	}
+	dir, name := split(dst)
+	if dir == "" {
+		dir = "."
+	}
+	return Target{Dir: dir, Name: name}, nil
+}
+
+// HostTarget resolves the target of a copy to the host.
+func HostTarget(dst, srcName string) (Target, error) {
+	info, err := os.Stat(dst)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Target{}, err
+	}
+	return ResolveTarget(dst, srcName, err == nil && info.IsDir(), err == nil, filepath.Split)
+}
+
+// VMTarget resolves the target of a copy to the VM, given the type of the destination
+// as reported by the VM: "directory", "file", or empty when it doesn't exist.
+func VMTarget(dst, srcName, dstType string) (Target, error) {
+	return ResolveTarget(dst, srcName, dstType == "directory", dstType != "", path.Split)
+}
+
+// SplitVMPath returns the directory and name of a path in the VM, as used with `tar -C`.
+func SplitVMPath(vmPath string) (string, string) {
+	vmPath = path.Clean(vmPath)
+	if vmPath == "/" {
+		return "/", "."
+	}
+	dir, name := path.Split(vmPath)
+	if dir == "" {
+		dir = "."
+	}
+	return dir, name
+}
+
+// WriteArchive writes the file or directory at srcPath to a tar archive, under the name topName.
+// Symbolic links are copied as links. Owners aren't kept, as they differ between the host and the VM.
+func WriteArchive(w io.Writer, srcPath, topName string, progress Progress) error {
+	tarWriter := tar.NewWriter(w)
+	err := filepath.WalkDir(srcPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcPath, filePath)
+		if err != nil {
+			return err
+		}
+		name := path.Join(topName, filepath.ToSlash(relPath))
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("can't copy %s: %w", filePath, err)
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return copyContents(tarWriter, file, filePath, info.Size(), progress)
+	})
+	if err != nil {
+		return err
+	}
+	return tarWriter.Close()
+}
+
+// copyChunkSize is how much is copied between calls to the progress function.
+const copyChunkSize = 1 << 20
+
+func copyContents(w io.Writer, r io.Reader, name string, size int64, progress Progress) error {
+	if progress == nil {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	var copied int64
+	for {
+		n, err := io.CopyN(w, r, copyChunkSize)
+		copied += n
+		progress(name, copied, size)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// archivePath checks that the name of an entry in an archive is inside it, and replaces
+// its first component with topName. All entries must have the same first component.
+func archivePath(name, topName string, top *string) (string, error) {
+	cleanName := path.Clean(name)
+	if path.IsAbs(cleanName) || cleanName == ".." || strings.HasPrefix(cleanName, "../") {
+		return "", fmt.Errorf("invalid path %q in archive", name)
+	}
+	first, rest, _ := strings.Cut(cleanName, "/")
+	if *top == "" {
+		*top = first
+	} else if first != *top {
+		return "", fmt.Errorf("unexpected path %q in archive: everything should be in %s", name, *top)
+	}
+	return filepath.FromSlash(path.Join(topName, rest)), nil
+}
+
+// ExtractArchive extracts a tar archive written by WriteArchive, or by tar in the VM,
+// renaming the entry at the top of the archive to target.Name in target.Dir.
+// The modes and modification times of files and directories are kept.
+func ExtractArchive(r io.Reader, target Target, progress Progress) error {
+	tarReader := tar.NewReader(r)
+	type extractedDir struct {
+		path   string
+		header *tar.Header
+	}
+	var top string
+	var dirs []extractedDir
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		relPath, err := archivePath(header.Name, target.Name, &top)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(target.Dir, relPath)
+		if err := checkParents(target.Dir, relPath); err != nil {
+			return err
+		}
+		mode := header.FileInfo().Mode().Perm()
+		switch header.Typeflag {
+		case tar.TypeDir:
+			// A symbolic link already there is replaced, rather than followed.
+			if info, err := os.Lstat(destPath); err == nil && !info.IsDir() {
+				if err := os.Remove(destPath); err != nil {
+					return err
+				}
+			}
+			if err := os.MkdirAll(destPath, 0o700); err != nil {
+				return err
+			}
+			// Directory modes are set at the end, in case they don't allow writing.
+			dirs = append(dirs, extractedDir{destPath, header})
+			continue
+		case tar.TypeReg, tar.TypeRegA:
+			if err := extractFile(tarReader, destPath, header, progress); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := replace(destPath, func() error { return os.Symlink(header.Linkname, destPath) }); err != nil {
+				return err
+			}
+			continue
+		case tar.TypeLink:
+			linkPath, err := archivePath(header.Linkname, target.Name, &top)
+			if err != nil {
+				return err
+			}
+			if err := checkParents(target.Dir, linkPath); err != nil {
+				return err
+			}
+			if err := replace(destPath, func() error { return os.Link(filepath.Join(target.Dir, linkPath), destPath) }); err != nil {
+				return err
+			}
+			continue
+		default:
+			// Devices and the like can't be copied to the host.
+			continue
+		}
+		if err := os.Chmod(destPath, mode); err != nil {
+			return err
+		}
+		if err := os.Chtimes(destPath, header.ModTime, header.ModTime); err != nil {
+			return err
+		}
+	}
+	if top == "" {
+		return fmt.Errorf("nothing was copied")
+	}
+	// Directories come before their contents in an archive, so going backwards sets
+	// the modes of read-only parents after those of their children.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].header.FileInfo().Mode().Perm()); err != nil {
+			return err
+		}
+		if err := os.Chtimes(dirs[i].path, dirs[i].header.ModTime, dirs[i].header.ModTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkParents makes sure that none of the directories between dir and the entry at
+// relPath is a symbolic link, so that an archive can't create a link to somewhere else
+// on the host and then write through it.
+func checkParents(dir, relPath string) error {
+	parent := filepath.Dir(relPath)
+	if parent == "." {
+		return nil
+	}
+	current := dir
+	for _, component := range strings.Split(parent, string(filepath.Separator)) {
+		current = filepath.Join(current, component)
+		info, err := os.Lstat(current)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to extract %s through the symbolic link %s", relPath, current)
+		}
+	}
+	return nil
+}
+
+func extractFile(r io.Reader, destPath string, header *tar.Header, progress Progress) error {
+	// Anything but a regular file is replaced, so that a symbolic link isn't written through.
+	if info, err := os.Lstat(destPath); err == nil && !info.Mode().IsRegular() {
+		if err := os.RemoveAll(destPath); err != nil {
+			return err
+		}
+	}
+	file, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	err = copyContents(file, r, destPath, header.Size, progress)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// replace creates a link with create, removing any file already at destPath first.
+func replace(destPath string, create func() error) error {
+	if err := os.Remove(destPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return create()
+}
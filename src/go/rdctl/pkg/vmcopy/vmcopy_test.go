@@ -0,0 +1,223 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmcopy
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocation(t *testing.T) {
+	location, err := ParseLocation("vm:/tmp/x")
+	require.NoError(t, err)
+	assert.Equal(t, Location{Path: "/tmp/x", VM: true}, location)
+
+	location, err = ParseLocation(`C:\Users\me`)
+	require.NoError(t, err)
+	assert.Equal(t, Location{Path: `C:\Users\me`}, location)
+
+	for _, arg := range []string{"", "vm:"} {
+		_, err := ParseLocation(arg)
+		assert.Error(t, err, arg)
+	}
+}
+
+func TestVMTarget(t *testing.T) {
+	testCases := []struct {
+		dst, dstType string
+		expected     Target
+	}{
+		{"/tmp", "directory", Target{"/tmp", "src"}},
+		{"/tmp/", "directory", Target{"/tmp/", "src"}},
+		{"/tmp/new", "", Target{"/tmp/", "new"}},
+		{"/tmp/existing", "file", Target{"/tmp/", "existing"}},
+		{"relative", "", Target{".", "relative"}},
+	}
+	for _, testCase := range testCases {
+		target, err := VMTarget(testCase.dst, "src", testCase.dstType)
+		require.NoError(t, err, testCase.dst)
+		assert.Equal(t, testCase.expected, target, testCase.dst)
+	}
+	_, err := VMTarget("/tmp/missing/", "src", "")
+	assert.ErrorContains(t, err, "does not exist")
+	_, err = VMTarget("/tmp/file/", "src", "file")
+	assert.ErrorContains(t, err, "not a directory")
+}
+
+func TestSplitVMPath(t *testing.T) {
+	testCases := map[string][2]string{
+		"/var/log/":   {"/var/", "log"},
+		"/etc/hosts":  {"/etc/", "hosts"},
+		"/":           {"/", "."},
+		"file":        {".", "file"},
+		"dir/../file": {".", "file"},
+	}
+	for vmPath, expected := range testCases {
+		dir, name := SplitVMPath(vmPath)
+		assert.Equal(t, expected, [2]string{dir, name}, vmPath)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "script.sh"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "data"), bytes.Repeat([]byte("x"), 3*copyChunkSize+1), 0o640))
+	modTime := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(src, "script.sh"), modTime, modTime))
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Chmod(filepath.Join(src, "sub"), 0o500))
+		t.Cleanup(func() { _ = os.Chmod(filepath.Join(src, "sub"), 0o755) })
+		require.NoError(t, os.Symlink("script.sh", filepath.Join(src, "link")))
+	}
+
+	var archive bytes.Buffer
+	progress := map[string][]int64{}
+	record := func(name string, copied, total int64) {
+		progress[filepath.Base(name)] = append(progress[filepath.Base(name)], copied)
+		assert.LessOrEqual(t, copied, total)
+	}
+	require.NoError(t, WriteArchive(&archive, src, "renamed", record))
+	assert.Equal(t, []int64{copyChunkSize, 2 * copyChunkSize, 3 * copyChunkSize, 3*copyChunkSize + 1}, progress["data"])
+
+	dst := t.TempDir()
+	progress = map[string][]int64{}
+	require.NoError(t, ExtractArchive(&archive, Target{Dir: dst, Name: "copy"}, record))
+	assert.Len(t, progress["data"], 4)
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(dst, "copy", "sub"), 0o755) })
+
+	contents, err := os.ReadFile(filepath.Join(dst, "copy", "sub", "data"))
+	require.NoError(t, err)
+	assert.Len(t, contents, 3*copyChunkSize+1)
+	info, err := os.Stat(filepath.Join(dst, "copy", "script.sh"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(modTime))
+	if runtime.GOOS == "windows" {
+		return
+	}
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(dst, "copy", "sub", "data"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(dst, "copy", "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o500), info.Mode().Perm())
+	link, err := os.Readlink(filepath.Join(dst, "copy", "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "script.sh", link)
+}
+
+func TestExtractSingleFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "hosts")
+	require.NoError(t, os.WriteFile(src, []byte("127.0.0.1 localhost\n"), 0o644))
+	var archive bytes.Buffer
+	require.NoError(t, WriteArchive(&archive, src, "hosts", nil))
+
+	dst := t.TempDir()
+	require.NoError(t, ExtractArchive(&archive, Target{Dir: dst, Name: "hosts.copy"}, nil))
+	contents, err := os.ReadFile(filepath.Join(dst, "hosts.copy"))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1 localhost\n", string(contents))
+}
+
+func TestExtractRejectsUnsafePaths(t *testing.T) {
+	for _, names := range [][]string{
+		{"../escape"},
+		{"/etc/passwd"},
+		{"top/file", "other/file"},
+		{"top/../../escape"},
+	} {
+		var archive bytes.Buffer
+		tarWriter := tar.NewWriter(&archive)
+		for _, name := range names {
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Typeflag: tar.TypeReg}))
+		}
+		require.NoError(t, tarWriter.Close())
+		dst := t.TempDir()
+		assert.Error(t, ExtractArchive(&archive, Target{Dir: dst, Name: "copy"}, nil), names)
+	}
+
+	if runtime.GOOS != "windows" {
+		// A symbolic link in the archive must not be written through.
+		outside := t.TempDir()
+		for _, headers := range [][]tar.Header{
+			{
+				{Name: "top", Mode: 0o755, Typeflag: tar.TypeDir},
+				{Name: "top/l", Linkname: outside, Typeflag: tar.TypeSymlink},
+				{Name: "top/l/authorized_keys", Mode: 0o644, Typeflag: tar.TypeReg},
+			},
+			{
+				{Name: "top", Mode: 0o755, Typeflag: tar.TypeDir},
+				{Name: "top/l", Linkname: outside, Typeflag: tar.TypeSymlink},
+				{Name: "top/l/sub", Mode: 0o755, Typeflag: tar.TypeDir},
+			},
+		} {
+			var archive bytes.Buffer
+			tarWriter := tar.NewWriter(&archive)
+			for _, header := range headers {
+				header := header
+				require.NoError(t, tarWriter.WriteHeader(&header))
+			}
+			require.NoError(t, tarWriter.Close())
+			assert.ErrorContains(t, ExtractArchive(&archive, Target{Dir: t.TempDir(), Name: "copy"}, nil), "symbolic link")
+			entries, err := os.ReadDir(outside)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		}
+
+		// A symbolic link followed by a file or directory of the same name is replaced.
+		outsideFile := filepath.Join(outside, "file")
+		require.NoError(t, os.WriteFile(outsideFile, []byte("unchanged"), 0o644))
+		require.NoError(t, os.Chmod(outside, 0o750))
+		var archive bytes.Buffer
+		tarWriter := tar.NewWriter(&archive)
+		for _, header := range []tar.Header{
+			{Name: "top", Mode: 0o755, Typeflag: tar.TypeDir},
+			{Name: "top/f", Linkname: outsideFile, Typeflag: tar.TypeSymlink},
+			{Name: "top/f", Mode: 0o600, Typeflag: tar.TypeReg},
+			{Name: "top/d", Linkname: outside, Typeflag: tar.TypeSymlink},
+			{Name: "top/d", Mode: 0o700, Typeflag: tar.TypeDir},
+		} {
+			header := header
+			require.NoError(t, tarWriter.WriteHeader(&header))
+		}
+		require.NoError(t, tarWriter.Close())
+		dst := t.TempDir()
+		require.NoError(t, ExtractArchive(&archive, Target{Dir: dst, Name: "copy"}, nil))
+		contents, err := os.ReadFile(outsideFile)
+		require.NoError(t, err)
+		assert.Equal(t, "unchanged", string(contents))
+		info, err := os.Stat(outside)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o750), info.Mode().Perm(), "the directory outside should not be touched")
+		info, err = os.Lstat(filepath.Join(dst, "copy", "d"))
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+	}
+
+	var empty bytes.Buffer
+	require.NoError(t, tar.NewWriter(&empty).Close())
+	assert.ErrorContains(t, ExtractArchive(&empty, Target{Dir: t.TempDir(), Name: "copy"}, nil), "nothing was copied")
+}
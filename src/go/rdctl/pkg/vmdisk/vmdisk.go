@@ -0,0 +1,211 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vmdisk compacts the disk images of the Lima VM for `rdctl vm compact`, giving
+// the space freed in the guest back to the host.
+package vmdisk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Disk image formats, as named by qemu-img.
+const (
+	FormatRaw   = "raw"
+	FormatQcow2 = "qcow2"
+)
+
+// qcow2Magic starts every qcow2 image.
+var qcow2Magic = []byte{'Q', 'F', 'I', 0xfb}
+
+// blockSize is the granularity at which runs of zeros are left out of a sparse copy.
+const blockSize = 4096
+
+// chunkSize is how much of the image is read at once.
+const chunkSize = 1 << 20
+
+// TrimScript is a shell script, run as root in the guest, that discards the unused blocks
+// of the file systems on block devices, so that they read as zeros in the disk image.
+const TrimScript = `awk '$1 ~ "^/dev/" { print $2 }' /proc/mounts | sort -u | while read -r mountpoint; do
+  fstrim -v "$mountpoint" || true
+done
+`
+
+// Format returns the format of a disk image: qcow2 for QEMU, or raw for Virtualization.framework.
+func Format(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	magic := make([]byte, len(qcow2Magic))
+	if _, err := io.ReadFull(file, magic); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if bytes.Equal(magic, qcow2Magic) {
+		return FormatQcow2, nil
+	}
+	return FormatRaw, nil
+}
+
+// tempPath returns the path the compacted image is written to before it replaces the original.
+func tempPath(path string) string {
+	return filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".compact")
+}
+
+// replace moves the compacted image over the original, keeping the mode of the original.
+func replace(path, compacted string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(compacted, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(compacted, path)
+}
+
+// RewriteSparse copies a raw image into a new sparse file, leaving out the blocks that
+// are all zeros, and replaces the image with it.
+func RewriteSparse(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	compacted := tempPath(path)
+	dst, err := os.OpenFile(compacted, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	err = sparseCopy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = replace(path, compacted)
+	}
+	if err != nil {
+		_ = os.Remove(compacted)
+		return fmt.Errorf("failed to rewrite %s: %w", path, err)
+	}
+	return nil
+}
+
+// sparseCopy writes the non-zero blocks of src at the same offsets in dst, and makes dst
+// as long as src, so the zero blocks are left as holes.
+func sparseCopy(dst *os.File, src io.Reader) error {
+	buf := make([]byte, chunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(src, buf)
+		if n > 0 {
+			if err := writeNonZero(dst, buf[:n], offset); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	if err := dst.Truncate(offset); err != nil {
+		return err
+	}
+	return dst.Sync()
+}
+
+// writeNonZero writes the runs of non-zero blocks of a chunk read at the offset.
+func writeNonZero(dst io.WriterAt, chunk []byte, offset int64) error {
+	zeros := make([]byte, blockSize)
+	runStart := 0
+	for start := 0; start < len(chunk); start += blockSize {
+		end := min(start+blockSize, len(chunk))
+		if !bytes.Equal(chunk[start:end], zeros[:end-start]) {
+			continue
+		}
+		if start > runStart {
+			if _, err := dst.WriteAt(chunk[runStart:start], offset+int64(runStart)); err != nil {
+				return err
+			}
+		}
+		runStart = end
+	}
+	if runStart < len(chunk) {
+		if _, err := dst.WriteAt(chunk[runStart:], offset+int64(runStart)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// qemuImgInfo is the part of the output of `qemu-img info --output=json` that is needed.
+type qemuImgInfo struct {
+	BackingFilename       string `json:"backing-filename"`
+	BackingFilenameFormat string `json:"backing-filename-format"`
+}
+
+// ConvertQcow2 rewrites a qcow2 image with qemu-img, which leaves out the clusters that
+// are all zeros, and replaces the image with it. The image keeps its backing file.
+func ConvertQcow2(qemuImg, path string) error {
+	infoOutput, err := exec.Command(qemuImg, "info", "--output=json", path).Output()
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", path, commandError(err))
+	}
+	var info qemuImgInfo
+	if err := json.Unmarshal(infoOutput, &info); err != nil {
+		return fmt.Errorf("failed to parse the output of qemu-img info: %w", err)
+	}
+	compacted := tempPath(path)
+	args := []string{"convert", "-O", FormatQcow2}
+	if info.BackingFilename != "" {
+		args = append(args, "-B", info.BackingFilename)
+		if info.BackingFilenameFormat != "" {
+			args = append(args, "-o", "backing_fmt="+info.BackingFilenameFormat)
+		}
+	}
+	args = append(args, path, compacted)
+	convert := exec.Command(qemuImg, args...)
+	// Relative backing files are relative to the directory of the image.
+	convert.Dir = filepath.Dir(path)
+	if _, err := convert.Output(); err != nil {
+		_ = os.Remove(compacted)
+		return fmt.Errorf("failed to convert %s: %w", path, commandError(err))
+	}
+	if err := replace(path, compacted); err != nil {
+		_ = os.Remove(compacted)
+		return err
+	}
+	return nil
+}
+
+// commandError adds the error output of a failed command to its error.
+func commandError(err error) error {
+	var exitError *exec.ExitError
+	if errors.As(err, &exitError) && len(bytes.TrimSpace(exitError.Stderr)) > 0 {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitError.Stderr))
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+/*
+Copyright © 2023 SUSE LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmdisk
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/rancher-sandbox/rancher-desktop/src/go/rdctl/pkg/diskusage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string][]byte{
+		FormatRaw:   make([]byte, 8192),
+		FormatQcow2: append([]byte{'Q', 'F', 'I', 0xfb}, make([]byte, 100)...),
+		"short":     {1},
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, contents, 0o644))
+		format, err := Format(path)
+		require.NoError(t, err)
+		expected := name
+		if name == "short" {
+			expected = FormatRaw
+		}
+		assert.Equal(t, expected, format, name)
+	}
+	_, err := Format(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestRewriteSparse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "diffdisk")
+	contents := make([]byte, 3*chunkSize+123)
+	copy(contents, "start")
+	copy(contents[2*chunkSize-3:], "across chunks")
+	copy(contents[blockSize*5+1:], "inside a block")
+	contents[len(contents)-1] = 'x'
+	require.NoError(t, os.WriteFile(path, contents, 0o640))
+	before, err := diskusage.Measure(path)
+	require.NoError(t, err)
+
+	require.NoError(t, RewriteSparse(path))
+	actual, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, contents, actual)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.NoFileExists(t, tempPath(path))
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+		after, err := diskusage.Measure(path)
+		require.NoError(t, err)
+		assert.Less(t, after.Allocated, before.Allocated)
+	}
+}
+
+func TestConvertQcow2(t *testing.T) {
+	qemuImg, err := exec.LookPath("qemu-img")
+	if err != nil {
+		t.Skip("qemu-img is not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command(qemuImg, args...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	run("create", "-f", FormatRaw, "basedisk", "16M")
+	run("create", "-f", FormatQcow2, "-b", "basedisk", "-F", FormatRaw, "diffdisk")
+	path := filepath.Join(dir, "diffdisk")
+
+	require.NoError(t, ConvertQcow2(qemuImg, path))
+	format, err := Format(path)
+	require.NoError(t, err)
+	assert.Equal(t, FormatQcow2, format)
+	output, err := exec.Command(qemuImg, "info", "--output=json", path).Output()
+	require.NoError(t, err)
+	var info qemuImgInfo
+	require.NoError(t, json.Unmarshal(output, &info))
+	assert.Equal(t, qemuImgInfo{BackingFilename: "basedisk", BackingFilenameFormat: FormatRaw}, info)
+}
+
+func TestTrimScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the script needs a POSIX shell")
+	}
+	output, err := exec.Command("/bin/sh", "-n", "-c", TrimScript).CombinedOutput()
+	assert.NoError(t, err, string(output))
+}